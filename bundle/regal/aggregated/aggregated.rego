@@ -30,6 +30,40 @@ rule_tree := object.union_n([m | m := _aggregates[_][_].rule_tree])
 #   a set containing all package paths from the linted files
 all_package_paths := {path | path := _aggregates[_][_].package_path}
 
+# METADATA
+# description: |
+#   the data files (data.json, data.yaml and data.yml) found alongside the linted files, keyed by
+#   file name. each data file is an object with the following attributes:
+#     path:       the path where the document of the file is mounted under data
+#     tree:       a tree of all keys in the document, in the same format as rule_tree
+#     keys:       each key in the document, with its full path, location, and whether it's a leaf
+#     duplicates: keys declared more than once in the same object, in the same format as keys
+#     error:      the message and location of the error encountered parsing the file, if any
+data_files := object.get(input, "data_files", {})
+
+# METADATA
+# description: |
+#   a tree representing all documents in data files, in the same format as rule_tree, where any
+#   value that isn't an object is represented by an empty object
+data_tree := object.union_n([file.tree | some file in data_files])
+
+# METADATA
+# description: |
+#   the bundles found among the linted files, which are directories containing a .manifest file, and
+#   project roots from the configuration. each bundle is an object with the following attributes:
+#     path:     the root directory of the bundle
+#     manifest: the roots and wasm resolvers declared in the .manifest file, if the bundle has one
+#     files:    the linted files in the bundle, keyed by file name. a file for which the manifest
+#               declares a Rego version has that version, the location of the declaration, and
+#               whether the file can be parsed using that version
+bundles := object.get(input, "bundles", [])
+
+# METADATA
+# description: |
+#   the names of the schemas found at the schema paths configured for the unresolved-metadata-schema
+#   rule, like "k8s.pod" for a schema referenced as schema.k8s.pod. empty if no paths are configured
+schema_files := object.get(input, "schema_files", [])
+
 # METADATA
 # description: |
 #   like util.to_location_object, but with file passed in as we don't
@@ -55,6 +89,113 @@ location_object(loc, file) := {"location": {
 	ref_text := substring(from_col, 0, _ref_end(from_col))
 }
 
+# METADATA
+# description: |
+#   the data collected by aggregators.references for each file, keyed by file name. any aggregate
+#   rule may collect this data under the "references" attribute, and the result is shared by all
+references[file] := util.any_set_item(refs) if {
+	some file, aggregates in input.aggregates_internal
+	refs := {entry.references | entry := aggregates[_][_]}
+	refs != set()
+}
+
+# METADATA
+# description: |
+#   a graph of references between all rules and functions in the workspace, mapping the full name
+#   of each rule or function to the set of rules and functions it refers to. a ref refers to any
+#   rule that it is either a prefix of, like a ref to a package, or that is a prefix of the ref,
+#   like a ref indexing into the value of a rule
+reference_graph[name] := object.get(_references_from, name, set()) if some name in _rule_names
+
+# METADATA
+# description: |
+#   the names of all rules and functions that are entrypoints, either by means of an `entrypoint`
+#   annotation on the rule or its package, or by being referenced from any of the provided refs
+reference_entrypoints(refs) := _annotated_entrypoints | {name |
+	some ref in refs
+	some name in resolve_ref(_with_data_prefix(ref))
+}
+
+# METADATA
+# description: |
+#   the names of all rules and functions reachable from any entrypoint, including those provided as
+#   refs, and unless ignore_tests is true, from any rule in a test module. undefined if there are no
+#   entrypoints, as everything would then be considered unreachable
+reachable_rules(refs, ignore_tests) := graph.reachable(reference_graph, entrypoints | tests) if {
+	entrypoints := reference_entrypoints(refs)
+	entrypoints != set()
+
+	tests := {name |
+		not ignore_tests
+
+		some module in references
+		module.test
+
+		some name, _ in module.rules
+	}
+}
+
+# METADATA
+# description: |
+#   the names of all rules and functions a ref (like "data.foo.bar") refers to, which are those
+#   that the ref is either a prefix of, like a ref to a package, or that are a prefix of the ref
+resolve_ref(ref) := indexed | object.get(_names_by_prefix, ref, set()) if {
+	parts := split(ref, ".")
+	indexed := {prefix |
+		some i in numbers.range(1, count(parts))
+		prefix := concat(".", array.slice(parts, 0, i))
+
+		prefix in _rule_names
+	}
+}
+
+_rule_names contains name if {
+	some module in references
+	some name, _ in module.rules
+}
+
+_references_from[name] contains target if {
+	some module in references
+	some name, rule in module.rules
+	some ref in rule.refs
+	some target in resolve_ref(ref)
+}
+
+_annotated_entrypoints contains name if {
+	some module in references
+	some name, rule in module.rules
+	rule.entrypoint
+}
+
+_annotated_entrypoints contains name if {
+	some module in references
+	module.entrypoint
+
+	some other in references
+	other.package == module.package
+
+	some name, _ in other.rules
+}
+
+# looked up once for each ref in the workspace, and grouped from pairs of prefix and name rather
+# than by splitting each name again for each prefix
+_names_by_prefix[prefix] := {name | some [other, name] in _prefixed_names; other == prefix} if {
+	some prefix in _prefixes
+}
+
+_prefixes contains prefix if some [prefix, _] in _prefixed_names
+
+_prefixed_names contains [prefix, name] if {
+	some name in _rule_names
+	parts := split(name, ".")
+
+	some i in numbers.range(1, count(parts) - 1)
+	prefix := concat(".", array.slice(parts, 0, i))
+}
+
+_with_data_prefix(ref) := ref if startswith(ref, "data.")
+_with_data_prefix(ref) := $"data.{ref}" if not startswith(ref, "data.")
+
 default _ref_end(_) := -1
 
 _ref_end(text) := i if {
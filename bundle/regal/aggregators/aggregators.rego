@@ -61,3 +61,144 @@ _rule_paths contains path if {
 	some name, _ in ast.rule_head_locations
 	some path in util.all_paths(split(trim_prefix(name, pkg_pref), "."))
 }
+
+# METADATA
+# description: |
+#   aggregates the rules and functions declared in the given input module, along with the
+#   fully expanded refs (like "data.foo.bar") found in each of them, for building a graph of
+#   references across all modules in a workspace. rules are keyed by their full name, and the
+#   names of rules and refs are both normalized to dot-separated paths, with everything from
+#   the first dynamic (or numeric) part of the path excluded
+references := {
+	"package": _package_prefix,
+	"location": input.package.location,
+	"entrypoint": _package_entrypoint,
+	"test": _test_module,
+	"rules": {name: info |
+		some name, indices in rule_indices
+
+		info := {
+			"location": _rule_locations[name][0],
+			"function": name in _functions,
+			"entrypoint": _rule_is_entrypoint(indices),
+			"refs": {ref | some i in indices; some ref in _expanded_refs[i]},
+		}
+	},
+}
+
+default _package_entrypoint := false
+
+_package_entrypoint if input.package.annotations[_].entrypoint == true
+
+default _test_module := false
+
+_test_module if endswith(ast.package_name, "_test")
+
+_test_module if endswith(input.regal.file.name, "_test.rego")
+
+# most names have no brackets to normalize, and are returned as is
+_normalized_name(name) := name if {
+	not contains(name, "[")
+} else := regex.replace(replace(replace(name, `["`, "."), `"]`, ""), `\[.*`, "")
+
+# METADATA
+# description: |
+#   the indices of the rules in the input module, keyed by the full name of the rule, normalized
+#   like the names in references. rules defined more than once have several indices
+rule_indices[_normalized_name($"{ast.package_name_full}.{name}")] contains i if {
+	some i, name in ast.rule_names_ordered
+}
+
+_rule_locations[name] := [loc |
+	some i in sort(indices)
+	loc := input.rules[i].head.ref[0].location
+] if {
+	some name, indices in rule_indices
+}
+
+_functions contains name if {
+	some name, indices in rule_indices
+	input.rules[util.any_set_item(indices)].head.args
+}
+
+default _rule_is_entrypoint(_) := false
+
+_rule_is_entrypoint(indices) if {
+	some i in indices
+	input.rules[i].annotations[_].entrypoint == true
+}
+
+_expanded_refs[i] contains expanded_name(ref) if {
+	some i, ref
+	ast.found.refs[i][ref]
+
+	not ref.value[0].value in _declared_vars[i]
+}
+
+# rules and imports may also be referenced by name only, like `allow if admin`
+_expanded_refs[i] contains _expanded_var(name) if {
+	some i, names in _referenced_names
+	some name in names
+}
+
+# the names of vars in each rule that aren't declared in it, each expanded once only, as the same
+# name is commonly found many times in a rule
+_referenced_names[i] := names - object.get(_declared_vars, i, set()) if {
+	some i, rule in input.rules
+
+	names := {term.value |
+		walk(rule, [_, term])
+
+		term.type == "var"
+		not term.location in _var_locations_excluded[i]
+	}
+}
+
+# vars declared in the rule, like function args and vars declared with `some` — any other name may
+# refer to a rule in the package, and as those may be declared in other modules, all are included
+_declared_vars[i] contains var.value if {
+	some i, contexts in ast.found.vars
+	some vars in contexts
+	some var in vars
+}
+
+# the first var of a ref is handled as part of the ref, and the name of the rule itself isn't a reference
+_var_locations_excluded[i] contains input.rules[i].head.ref[0].location if some i
+
+_var_locations_excluded[i] contains ref.value[0].location if {
+	some i, ref
+	ast.found.refs[i][ref]
+}
+
+# METADATA
+# description: |
+#   the full name of what a ref or var term in the input module refers to in data, like "data.foo.bar",
+#   normalized like the names in references. undefined for terms not referring to data, like input refs
+# scope: document
+expanded_name(term) := _normalized_name(_expanded_ref(term.value)) if term.type == "ref"
+
+expanded_name(term) := _expanded_var(term.value) if term.type == "var"
+
+_expanded_ref(ref) := ast.ref_static_to_string(ref) if {
+	ref[0].value == "data"
+} else := concat("", [prefix, substring(ast.ref_static_to_string(ref), count(ref[0].value), -1)]) if {
+	prefix := _expanded_var(ref[0].value)
+}
+
+# like OPA, resolve names to imports before rules in the package, should both exist. the result is
+# already normalized, as import paths are split into parts, and the package name is normalized once
+_expanded_var(name) := concat(".", resolved) if {
+	resolved := ast.resolved_imports[name]
+	resolved[0] == "data"
+} else := $"{_package_prefix}.{name}" if not _non_package_name(name)
+
+_package_prefix := _normalized_name(ast.package_name_full)
+
+_non_package_name(name) if ast.resolved_imports[name]
+
+_non_package_name(name) if name in {"input", "data"}
+
+_non_package_name(name) if name in ast.builtin_namespaces
+
+# generated vars, like those replacing wildcards
+_non_package_name(name) if startswith(name, "$")
@@ -1,6 +1,8 @@
 package regal.aggregators_test
 
 import data.regal.aggregators
+import data.regal.capabilities
+import data.regal.config
 
 test_aggregate_collects_imports_with_location if {
 	r := aggregators.imports with input as regal.parse_module("p.rego", `
@@ -26,3 +28,60 @@ test_aggregate_ignores_imports_of_regal_in_custom_rule if {
 
 	r == [[["a", "b", "c"], "6:2:6:8"]]
 }
+
+test_aggregate_collects_references if {
+	r := aggregators.references with input as regal.parse_module("p.rego", `
+# METADATA
+# entrypoint: true
+package p
+
+import data.lib.x as y
+
+allow if {
+	helper
+	y.z[1]
+	f(1)
+}
+
+helper := data.q.r.s
+
+f(x) := x + 1
+
+f(x) := x - 1 if x > 10
+
+a.b.c := 1`)
+		with config.capabilities as capabilities.provided
+
+	r == {
+		"package": "data.p",
+		"location": "4:1:4:8",
+		"entrypoint": true,
+		"test": false,
+		"rules": {
+			"data.p.allow": {
+				"location": "8:1:8:6",
+				"function": false,
+				"entrypoint": false,
+				"refs": {"data.p.helper", "data.lib.x.z", "data.p.f"},
+			},
+			"data.p.helper": {
+				"location": "14:1:14:7",
+				"function": false,
+				"entrypoint": false,
+				"refs": {"data.q.r.s"},
+			},
+			"data.p.f": {
+				"location": "16:1:16:2",
+				"function": true,
+				"entrypoint": false,
+				"refs": set(),
+			},
+			"data.p.a.b.c": {
+				"location": "20:1:20:2",
+				"function": false,
+				"entrypoint": false,
+				"refs": set(),
+			},
+		},
+	}
+}
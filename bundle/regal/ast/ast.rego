@@ -129,6 +129,18 @@ public_rules_and_functions := [rule |
 	}
 ]
 
+# METADATA
+# description: |
+#   all metadata annotations in the input AST, i.e. those of the package and of any rule or function.
+#   annotations with document scope are attached to each rule of the document, but included only once
+# scope: document
+annotations contains annotation if some annotation in input.package.annotations
+
+annotations contains annotation if {
+	some rule in _rules
+	some annotation in rule.annotations
+}
+
 # METADATA
 # description: a list of the argument names for the given rule (if function)
 function_arg_names(rule) := [arg.value | some arg in rule.head.args]
@@ -434,3 +446,41 @@ rule_head_locations[name] contains {"row": loc.row, "col": loc.col} if {
 	name := $"{package_name_full}.{rule_names_ordered[i]}"
 	loc := util.to_location_object(rule.head.location)
 }
+
+# METADATA
+# description: |
+#   object describing which of the rules and comprehensions in the input module OPA is able to index,
+#   keyed by "row:col" locations. See the regal.rule_index built-in function for details
+index_outcomes := regal.rule_index(
+	concat("\n", input.regal.file.lines),
+	{"rego_version": input.regal.file.rego_version},
+)
+
+# METADATA
+# description: |
+#   object containing the types inferred by OPA's type checker for vars, refs and function calls in the
+#   top-level scope of rule bodies, keyed by "row:col" locations. See the regal.inferred_types built-in
+#   function for details
+inferred_types := regal.inferred_types(
+	concat("\n", input.regal.file.lines),
+	{"rego_version": input.regal.file.rego_version},
+)
+
+# METADATA
+# description: |
+#   the kinds of values the provided term may have according to the type checker, e.g. {"string"} or
+#   {"null", "string"}, or undefined if the type of the term isn't known or could be anything
+inferred_kinds(term) := {kind | some kind in inferred.kinds} if {
+	inferred := inferred_type(term)
+	inferred.kinds != []
+}
+
+# METADATA
+# description: |
+#   the type inferred for the provided term, or undefined if not known. Scalar terms
+#   aren't included in the inferred types, but their type is known from the AST
+inferred_type(term) := {"type": term.type, "kinds": [term.type]} if {
+	term.type in scalar_types
+} else := inferred_types[$"{loc.row}:{loc.col}"] if {
+	loc := util.to_location_object(term.location)
+}
@@ -19,6 +19,29 @@ test_function_decls_multiple_same_name if {
 	is_object(custom)
 }
 
+test_annotations if {
+	module := regal.parse_module("p.rego", `# METADATA
+# title: Package
+package p
+
+# METADATA
+# scope: document
+# title: Allow
+allow if input.admin
+
+allow if input.owner
+
+# METADATA
+# title: Deny
+deny if input.blocked
+`)
+	annotations := ast.annotations with input as module
+
+	# the document scoped annotation is attached to both allow rules, but included once
+	count(annotations) == 3
+	{a.title | some a in annotations} == {"Package", "Allow", "Deny"}
+}
+
 test_comment_blocks if {
 	policy := `package p
 
@@ -0,0 +1,87 @@
+# METADATA
+# description: |
+#   complexity metrics for each rule and function in the input module, used both for
+#   reporting rules exceeding configured thresholds, and for providing the raw metrics
+#   to users tracking the complexity of their policies over time
+package regal.complexity
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.ast
+import data.regal.util
+
+# METADATA
+# description: |
+#   the complexity metrics of each rule and function in the input module, keyed by the full name
+#   of the rule (like "data.policy.allow"), where rules defined more than once are counted as one:
+#     location:    the location of the first definition
+#     function:    whether the rule is a function
+#     definitions: the number of definitions, not counting else branches
+#     expressions: the number of expressions in the bodies, including nested ones
+#     nesting:     the deepest nesting of comprehensions and `every` in any body
+#     fan_out:     the number of distinct rules and documents referenced
+#     else:        the number of else branches
+metrics[name] := {
+	"location": object.union(util.to_location_no_text(rule.location), {"file": input.regal.file.name}),
+	"function": rule.function,
+	"definitions": count(indices),
+	"expressions": sum([count(object.get(ast.found.expressions, i, set())) | some i in indices]),
+	"nesting": max([_nesting(i) | some i in indices]),
+	"fan_out": count(rule.refs | {ref | some i in indices; some ref in _input_refs[i]}),
+	"else": sum([count(_else_branches(input.rules[i])) | some i in indices]),
+} if {
+	some name, indices in aggregators.rule_indices
+
+	rule := aggregators.references.rules[name]
+}
+
+# METADATA
+# description: |
+#   the fan-in of each rule and function in all linted files, i.e. the number of other rules and
+#   functions referring to it, keyed by the full name of the rule. only available in the context
+#   of aggregate rules
+# schemas:
+#   - input: schema.regal.aggregate
+fan_in[name] := count({other |
+	some other, targets in aggregated.reference_graph
+
+	other != name
+	name in targets
+}) if {
+	some name, _ in aggregated.reference_graph
+}
+
+_input_refs[i] contains ast.ref_static_to_string(ref.value) if {
+	some i, ref
+	ast.found.refs[i][ref]
+
+	ref.value[0].value == "input"
+}
+
+_else_branches(rule) := {path |
+	walk(rule, [path, _])
+
+	regal.last(path) == "else"
+}
+
+default _nesting(_) := 0
+
+_nesting(i) := max([count({outer |
+	some outer in _nested[i]
+	util.contains_location(outer, inner)
+}) |
+	some inner in _nested[i]
+])
+
+# the locations of comprehensions and every expressions, each of which add a level of nesting
+_nested[i] contains util.to_location_no_text(comprehension.location) if {
+	some i, comprehension
+	ast.found.comprehensions[i][comprehension]
+}
+
+_nested[i] contains util.to_location_no_text(expr.location) if {
+	some i, expr
+	ast.found.expressions[i][expr]
+
+	expr.terms.domain
+}
@@ -0,0 +1,61 @@
+package regal.complexity_test
+
+import data.regal.capabilities
+import data.regal.complexity
+import data.regal.config
+
+test_metrics if {
+	module := regal.parse_module("p.rego", `package p
+
+allow := true
+
+allow if input.user.admin
+
+f(x) := 1 if {
+	every y in x {
+		y > count([z | some z in y; z > input.min])
+	}
+} else := 2 if {
+	x == data.q.limit
+} else := 3
+`)
+	metrics := complexity.metrics with input as module
+		with config.capabilities as capabilities.provided
+
+	metrics == {
+		"data.p.allow": {
+			"location": {"file": "p.rego", "row": 3, "col": 1, "end": {"row": 3, "col": 6}},
+			"function": false,
+			"definitions": 2,
+			"expressions": 1,
+			"nesting": 0,
+			"fan_out": 1,
+			"else": 0,
+		},
+		"data.p.f": {
+			"location": {"file": "p.rego", "row": 7, "col": 1, "end": {"row": 7, "col": 2}},
+			"function": true,
+			"definitions": 1,
+			"expressions": 5,
+			"nesting": 2,
+			"fan_out": 2,
+			"else": 2,
+		},
+	}
+}
+
+test_fan_in if {
+	fan_in := complexity.fan_in with data.regal.aggregated.reference_graph as {
+		"data.p.allow": {"data.p.admin", "data.q.user"},
+		"data.p.deny": {"data.p.admin"},
+		"data.p.admin": {"data.q.user", "data.p.admin"},
+		"data.q.user": set(),
+	}
+
+	fan_in == {
+		"data.p.allow": 0,
+		"data.p.deny": 0,
+		"data.p.admin": 2,
+		"data.q.user": 2,
+	}
+}
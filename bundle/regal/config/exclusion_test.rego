@@ -60,21 +60,13 @@ exclude(pattern, file) if {
 	glob.match(p, ["/"], file)
 }
 
-rules_config_error := {"rules": {"test": {"test-case": {"level": "error"}}}}
-
-rules_config_ignore_delta := {"rules": {"test": {"test-case": {"ignore": {"files": ["p.rego"]}}}}}
-
 config_ignore := {"ignore": {"files": ["p.rego"]}}
 
 test_excluded_file_default if not config.excluded_file("test", "test-case", "p.rego")
 	with data.eval.params as params({})
-	with config.merged_config as rules_config_error
 
 test_excluded_file_with_ignore if {
-	compiled := config.patterns_compiler(config_ignore.ignore.files) with config.merged_config as object.union(
-		rules_config_error,
-		rules_config_ignore_delta,
-	)
+	compiled := config.patterns_compiler(config_ignore.ignore.files)
 
 	config.excluded_file("test", "test-case", "p.rego")
 		with data.regal.util as "obnoxious formatter"
@@ -82,7 +74,6 @@ test_excluded_file_with_ignore if {
 }
 
 test_excluded_file_cli_overrides_config if not config.excluded_file("test", "test-case", "p.rego")
-	with config.merged_config as config_ignore
 	with data.eval.params as params({"ignore_files": [""]})
 
 test_trailing_slash[pattern] if {
@@ -4,19 +4,7 @@ import data.regal.lsp.completion.providers.builtins
 
 test_simple_builtin_completion if {
 	items := builtins.items
-		with input as {
-			"params": {
-				"textDocument": {"uri": "file:///p.rego"},
-				"position": {"line": 3, "character": 10},
-			},
-			"regal": {"file": {"lines": [
-				"package p",
-				"",
-				"allow if {",
-				"    b := c",
-				"}",
-			]}},
-		}
+		with input as _input_in_rule_body
 		with data.workspace.builtins as _builtins
 
 	items == {
@@ -117,19 +105,7 @@ test_no_completion_of_deprecated_builtin if {
 	builtins_deprecated := [object.union(_builtins[0], {"deprecated": true})]
 	items := builtins.items
 		with data.workspace.builtins as builtins_deprecated
-		with input as {
-			"params": {
-				"textDocument": {"uri": "file:///p.rego"},
-				"position": {"line": 3, "character": 10},
-			},
-			"regal": {"file": {"lines": [
-				"package p",
-				"",
-				"allow if {",
-				"    b := c",
-				"}",
-			]}},
-		}
+		with input as _input_in_rule_body
 
 	count(items) == 0
 }
@@ -138,19 +114,7 @@ test_no_completion_of_infix_builtin if {
 	builtins_deprecated := [object.union(_builtins[0], {"infix": "🔄"})]
 	items := builtins.items
 		with data.workspace.builtins as builtins_deprecated
-		with input as {
-			"params": {
-				"textDocument": {"uri": "file:///p.rego"},
-				"position": {"line": 3, "character": 10},
-			},
-			"regal": {"file": {"lines": [
-				"package p",
-				"",
-				"allow if {",
-				"    b := c",
-				"}",
-			]}},
-		}
+		with input as _input_in_rule_body
 
 	count(items) == 0
 }
@@ -173,6 +137,20 @@ test_no_completion_in_default_rule if {
 	count(items) == 0
 }
 
+_input_in_rule_body := {
+	"params": {
+		"textDocument": {"uri": "file:///p.rego"},
+		"position": {"line": 3, "character": 10},
+	},
+	"regal": {"file": {"lines": [
+		"package p",
+		"",
+		"allow if {",
+		"    b := c",
+		"}",
+	]}},
+}
+
 _builtins := [
 	{
 		"name": "count",
@@ -10,16 +10,7 @@ test_inlayhints_rendered_correctly_no_tooltip_resolve if {
 	}`)
 
 	res := inlayhint.result.response
-		with input as {
-			"regal": {"file": {
-				"parse_errors": [],
-				"uri": "file:///p.rego",
-			}},
-			"params": {"range": {
-				"start": {"line": 0, "character": 0},
-				"end": {"line": 100, "character": 100},
-			}},
-		}
+		with input as _input_whole_file
 		with data.workspace.parsed["file:///p.rego"] as module
 		with data.workspace.builtins as _builtins
 
@@ -59,16 +50,7 @@ test_inlayhints_rendered_correctly_tooltip_resolve if {
 	}`)
 
 	res := inlayhint.result.response
-		with input as {
-			"regal": {"file": {
-				"parse_errors": [],
-				"uri": "file:///p.rego",
-			}},
-			"params": {"range": {
-				"start": {"line": 0, "character": 0},
-				"end": {"line": 100, "character": 100},
-			}},
-		}
+		with input as _input_whole_file
 		with data.client.capabilities.textDocument.inlayHint.resolveSupport.properties as ["tooltip"]
 		with data.workspace.parsed["file:///p.rego"] as module
 		with data.workspace.builtins as _builtins
@@ -112,16 +94,7 @@ test_inlayhints_rendered_correctly_custom_function if {
 	inlay_hints if custom(a, b)`)
 
 	res := inlayhint.result.response
-		with input as {
-			"regal": {"file": {
-				"parse_errors": [],
-				"uri": "file:///p.rego",
-			}},
-			"params": {"range": {
-				"start": {"line": 0, "character": 0},
-				"end": {"line": 100, "character": 100},
-			}},
-		}
+		with input as _input_whole_file
 		with data.workspace.parsed["file:///p.rego"] as module
 		with data.workspace.builtins as _builtins
 
@@ -162,16 +135,7 @@ test_inlayhints_rendered_correctly_custom_function_non_var_argument if {
 	inlay_hints if custom(a, b)`)
 
 	res := inlayhint.result.response
-		with input as {
-			"regal": {"file": {
-				"parse_errors": [],
-				"uri": "file:///p.rego",
-			}},
-			"params": {"range": {
-				"start": {"line": 0, "character": 0},
-				"end": {"line": 100, "character": 100},
-			}},
-		}
+		with input as _input_whole_file
 		with data.workspace.parsed["file:///p.rego"] as module
 		with data.workspace.builtins as _builtins
 
@@ -278,6 +242,17 @@ test_null_if_parse_errors if {
 	res == null
 }
 
+_input_whole_file := {
+	"regal": {"file": {
+		"parse_errors": [],
+		"uri": "file:///p.rego",
+	}},
+	"params": {"range": {
+		"start": {"line": 0, "character": 0},
+		"end": {"line": 100, "character": 100},
+	}},
+}
+
 _builtins := {"startswith": {"decl": {"args": [
 	{"name": "base", "type": "string", "description": "base string"},
 	{"name": "search", "type": "string", "description": "search string"},
@@ -25,6 +25,7 @@ default result.response.ranges := set()
 # This is currently an experimental feature kept behind a flag to not accidedntally result in a poor
 # user experience for anyone who perhaps mistakenly enabled linked editing in their editor. Handler, and
 # the code kept here, to allow us to quickly test out the feature for different use-cases later.
+# regal ignore:opa-runtime-env
 result.response.ranges := ranges if util.parse_bool(opa.runtime().env.REGAL_EXPERIMENTAL)
 
 # METADATA
@@ -10,6 +10,7 @@ package regal.main
 
 import data.regal.aggregators
 import data.regal.ast
+import data.regal.complexity
 import data.regal.config
 import data.regal.notices
 import data.regal.prepared
@@ -40,6 +41,13 @@ lint.ignore_directives[input.regal.file.name] := ast.ignore_directives if "lint"
 # description: all violations from non-aggregate rules
 lint.violations := report if "lint" in input.regal.operations
 
+# METADATA
+# description: all violations from non-aggregate rules suppressed by ignore directives, collected for stats
+lint.suppressed := suppressed if {
+	"lint" in input.regal.operations
+	"stats" in input.regal.operations
+}
+
 # METADATA
 # description: map of all aggregated data from aggregate rules, keyed by category/title
 lint.aggregates := aggregate if "collect" in input.regal.operations
@@ -48,6 +56,27 @@ lint.aggregates := aggregate if "collect" in input.regal.operations
 # description: all violations from aggregate rules
 lint.aggregate.violations := aggregate_report if "aggregate" in input.regal.operations
 
+# METADATA
+# description: all violations from aggregate rules suppressed by ignore directives, collected for stats
+lint.aggregate.suppressed := aggregate_suppressed if {
+	"aggregate" in input.regal.operations
+	"stats" in input.regal.operations
+}
+
+# METADATA
+# description: complexity metrics for all rules and functions, when requested
+lint.complexity := complexity.metrics if {
+	"lint" in input.regal.operations
+	"complexity" in input.regal.operations
+}
+
+# METADATA
+# description: fan-in of all rules and functions across all files, when complexity metrics are requested
+lint.aggregate.complexity := complexity.fan_in if {
+	"aggregate" in input.regal.operations
+	"complexity" in input.regal.operations
+}
+
 # METADATA
 # description: prepared state for linting, after Rego preparation step
 lint.prepared := prepared.prepare if "prepare" in input.regal.operations
@@ -132,6 +161,34 @@ report contains violation if {
 	not _ignored(violation, ast.ignore_directives)
 }
 
+# METADATA
+# description: violations from bundled rules which were suppressed by ignore directives
+suppressed contains violation if {
+	some category, title
+	_rules_to_run[category][title]
+
+	object.get(prepared.notices, [category, title], set()) == set()
+	some violation in data.regal.rules[category][title].report
+
+	_ignored(violation, ast.ignore_directives)
+}
+
+# METADATA
+# description: violations from custom rules which were suppressed by ignore directives
+suppressed contains violation if {
+	not _globally_ignored
+
+	file_name_relative_to_root := trim_prefix(input.regal.file.name, concat("", [config.path_prefix, "/"]))
+
+	some category, title
+	violation := data.custom.regal.rules[category][title].report[_]
+
+	not config.ignored_rule(category, title)
+	not config.excluded_file(category, title, file_name_relative_to_root)
+
+	_ignored(violation, ast.ignore_directives)
+}
+
 # METADATA
 # description: collect common data used by aggregate rules
 aggregate[input.regal.file.name].common contains {
@@ -143,6 +200,12 @@ aggregate[input.regal.file.name].common contains {
 	"imports": aggregators.imports,
 }
 
+# METADATA
+# description: collects references between rules, for computing fan-in when complexity metrics are requested
+aggregate[input.regal.file.name].complexity contains {"references": aggregators.references} if {
+	"complexity" in input.regal.operations
+}
+
 # METADATA
 # description: collects aggregates in bundled rules
 # scope: rule
@@ -184,28 +247,41 @@ _mark_if_empty(entries) := {{}} if {
 } else := entries
 
 # METADATA
-# description: Check bundled rules using aggregated data
+# description: Check bundled and custom rules using aggregated data
 # schemas:
 #   - input: schema.regal.aggregate
 aggregate_report contains violation if {
+	some violation in _aggregate_violations
+
+	not _aggregate_ignored(violation)
+}
+
+# METADATA
+# description: aggregate violations suppressed by ignore directives, collected for stats
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_suppressed contains violation if {
+	some violation in _aggregate_violations
+
+	_aggregate_ignored(violation)
+}
+
+# METADATA
+# description: violations from bundled rules using aggregated data
+# schemas:
+#   - input: schema.regal.aggregate
+_aggregate_violations contains violation if {
 	some category, title
 	_rules_to_run[category][title]
 
 	some violation in data.regal.rules[category][title].aggregate_report
-
-	not _ignored(violation, util.keys_to_numbers(object.get(
-		input.ignore_directives,
-		# some aggregate violations won't have a location at all, like no-defined-entrypoint
-		object.get(violation, ["location", "file"], ""),
-		{},
-	)))
 }
 
 # METADATA
-# description: Check custom rules using aggregated data
+# description: violations from custom rules using aggregated data
 # schemas:
 #   - input: schema.regal.aggregate
-aggregate_report contains violation if {
+_aggregate_violations contains violation if {
 	data.custom.regal
 
 	some key, aggregate in _aggregate_report_inputs
@@ -218,13 +294,16 @@ aggregate_report contains violation if {
 
 	# regal ignore:with-outside-test-context
 	some violation in data.custom.regal.rules[category][title].aggregate_report with input as input_for_rule
-
-	# don't assume that the author included a location in the violation, although they really should
-	ignore_directives := object.get(input, ["ignore_directives", object.get(violation, ["location", "file"], "")], {})
-
-	not _ignored(violation, util.keys_to_numbers(ignore_directives))
 }
 
+# some aggregate violations won't have a location at all, like no-defined-entrypoint,
+# and we don't assume that the author of a custom rule included one, although they really should
+_aggregate_ignored(violation) if _ignored(violation, util.keys_to_numbers(object.get(
+	input,
+	["ignore_directives", object.get(violation, ["location", "file"], "")],
+	{},
+)))
+
 _remove_empty_aggregates(aggregates) := {"aggregate": set()} if {
 	aggregates == {"aggregate": {set()}}
 } else := aggregates
@@ -196,7 +196,6 @@ test_exclude_files_rule_config_with_path_prefix_relative_name if {
 	compiled := config.patterns_compiler(rules_config.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as rules_config
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with data.internal.prepared.ignore_patterns.files.testing.test as compiled
 		with input.regal.file.name as "bar/p.rego"
@@ -207,10 +206,11 @@ test_exclude_files_rule_config_with_path_prefix_relative_name if {
 
 test_not_exclude_files_rule_config_with_path_prefix_relative_name if {
 	cfg := {"testing": {"test": {"level": "error", "ignore": {"files": ["notmatching/*"]}}}}
+	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
+		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "bar/p.rego"
 		with config.path_prefix as "/foo" # ignored as not prefix of input file
 
@@ -222,7 +222,6 @@ test_exclude_files_rule_config_with_path_prefix if {
 	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "/foo/bar/p.rego"
@@ -236,7 +235,6 @@ test_exclude_files_rule_config_with_root_path_prefix if {
 	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "/foo/bar/p.rego"
@@ -250,7 +248,6 @@ test_not_exclude_files_rule_config_with_path_prefix if {
 	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "/foo/bar/p.rego"
@@ -264,7 +261,6 @@ test_exclude_files_rule_config_with_uri_and_path_prefix if {
 	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "file:///foo/bar/p.rego"
@@ -275,10 +271,11 @@ test_exclude_files_rule_config_with_uri_and_path_prefix if {
 
 test_not_exclude_files_rule_config_with_uri_and_path_prefix if {
 	cfg := {"testing": {"test": {"level": "error", "ignore": {"files": ["notmatching/*"]}}}}
+	pat := config.patterns_compiler(cfg.testing.test.ignore.files)
 
 	rules_to_run := main._rules_to_run
-		with config.rules as cfg
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
+		with data.internal.prepared.ignore_patterns.files.testing.test as pat
 		with input.regal.file.name as "file:///foo/bar/p.rego"
 		with config.path_prefix as "file:///foo"
 
@@ -81,10 +81,7 @@ test_main_lint if {
 }
 
 test_rules_to_run_not_excluded if {
-	cfg := {"rules": {"testing": {"test": {"level": "error"}}}}
-
 	rules_to_run := main._rules_to_run
-		with config.merged_config as cfg
 		with input.regal.file.name as "p.rego"
 		with data.internal.prepared.rules_to_run as {"testing": {"test"}}
 		with config.excluded_file as false
@@ -200,3 +200,19 @@ infix_expr_location(terms) := location(regex.replace(
 	`^(\d+:\d+:).+:(\d+:\d+)$`,
 	`$1$2`,
 ))
+
+# METADATA
+# description: |
+#   returns a location object for text found in a metadata annotation, like a related resource, from
+#   the first line of the annotation containing the text. when the text can't be found, like if it's
+#   written over several lines, the location of the annotation itself is returned
+location_in_annotation(annotation, text) := location($"{row}:{col}:{row}:{col + count(text)}") if {
+	loc := util.to_location_object(annotation.location)
+
+	row := [row |
+		some row in numbers.range(loc.row, loc.end.row)
+		contains(input.regal.file.lines[row - 1], text)
+	][0]
+
+	col := indexof(input.regal.file.lines[row - 1], text) + 1
+} else := location(annotation)
@@ -0,0 +1,306 @@
+# METADATA
+# description: Rule or function definitions with conflicting outputs
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/conflicting-outputs
+package regal.rules.bugs["conflicting-outputs"]
+
+import data.regal.aggregated
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+# METADATA
+# description: |
+#   collects each definition of a complete rule or function returning a constant value, along with
+#   its arguments and body. these are only normalized when reporting, as few rules are defined more
+#   than once
+aggregate contains definition if {
+	some rule in input.rules
+
+	not rule.default
+	not rule.head.key
+
+	every term in util.rest(rule.head.ref) {
+		term.type == "string"
+	}
+
+	ast.is_constant(rule.head.value)
+
+	definition := {
+		"name": $"{ast.package_name_full}.{ast.ref_static_to_string(rule.head.ref)}",
+		"args": object.get(rule.head, "args", []),
+		"value": rule.head.value,
+		"body": object.get(rule, "body", []),
+		"location": rule.head.ref[0].location,
+	}
+}
+
+# expressions that may take part in telling definitions apart, which excludes assignments, `some` and
+# `every` declarations and expressions using `with`. those are commonly the largest ones of a body,
+# and leaving them out saves normalizing them
+_condition(expr) if {
+	not expr.with
+	not expr.terms.symbols
+	not expr.terms.domain
+	not expr.terms[0].value[0].value == "assign"
+}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some name, definitions in _definitions
+	some i, [file, definition] in definitions
+
+	other := [[other_file, other_definition] |
+		some j, [other_file, other_definition] in definitions
+
+		j < i
+
+		_conflicting(definition, other_definition)
+	][0]
+
+	[other_file, other_definition] := other
+	other_row := split(other_definition.location, ":")[0]
+	kind := _kind(definition.args)
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		aggregated.location_object(definition.location, file),
+		{"description": $"{kind} {name} may produce a different value than its definition at {other_file}:{other_row}"},
+	))
+}
+
+# all definitions of each rule or function defined more than once, normalized and sorted for a
+# stable order
+_definitions[name] := sort([[file, _normalized_definition(definition)] |
+	some [file, definition] in definitions
+]) if {
+	some name, definitions in _definitions_by_name
+
+	count(definitions) > 1
+}
+
+# the argument patterns and body expressions of a definition, without locations, and with the vars
+# declared as arguments renamed by their position
+_normalized_definition(definition) := {
+	"args": [_arg_pattern(arg) | some arg in definition.args],
+	"value": _normalized(definition.value, {}),
+	"conditions": _normalized(
+		[expr | some expr in definition.body; _condition(expr)],
+		_arg_renames(definition.args),
+	),
+	"location": definition.location,
+}
+
+# grouped in a single pass over the aggregates, as there is one for nearly every rule in the workspace
+_definitions_by_name[definition.name] contains [file, definition] if {
+	some file, aggregates in input.aggregates_internal
+	some definition in aggregates["bugs/conflicting-outputs"]
+}
+
+_conflicting(definition, other) if {
+	definition.value != other.value
+	count(definition.args) == count(other.args)
+
+	every i, pattern in definition.args {
+		_overlapping(pattern, other.args[i])
+	}
+
+	not _mutually_exclusive(definition.conditions, other.conditions)
+	not _excluded_by_args(definition.args, other.args, other.conditions)
+	not _excluded_by_args(other.args, definition.args, definition.conditions)
+}
+
+_kind(args) := "Function" if args != []
+_kind(args) := "Rule" if args == []
+
+# an argument matching any value, like a variable or a composite value containing variables
+_any := {"type": "var"}
+
+_arg_pattern(arg) := _normalized(arg, {}) if ast.is_constant(arg)
+_arg_pattern(arg) := _any if not ast.is_constant(arg)
+
+_overlapping(pattern, other) if pattern == other
+_overlapping(pattern, _) if _is_any(pattern)
+_overlapping(_, other) if _is_any(other)
+
+_is_any(pattern) if pattern == _any
+
+# one body contains an expression that the other body contains the negation of
+_mutually_exclusive(conditions, other) if {
+	some expr in conditions
+	some other_expr in other
+
+	some [negated, positive] in [[expr, other_expr], [other_expr, expr]]
+
+	negated.negated
+	object.remove(negated, ["negated"]) == positive
+}
+
+# both bodies compare the same term for equality with different constants
+_mutually_exclusive(conditions, other) if {
+	some expr in conditions
+	some other_expr in other
+
+	[term, value] := _compared(expr)
+	[other_term, other_value] := _compared(other_expr)
+
+	term == other_term
+	value != other_value
+}
+
+# both bodies compare the same terms using complementary operators, like `==` and `!=`
+_mutually_exclusive(conditions, other) if {
+	some expr in conditions
+	some other_expr in other
+
+	not expr.negated
+	not other_expr.negated
+
+	array.slice(expr.terms, 1, 3) == array.slice(other_expr.terms, 1, 3)
+
+	operators := [ast.ref_to_string(expr.terms[0].value), ast.ref_to_string(other_expr.terms[0].value)]
+	operators in _complementary
+}
+
+# both bodies check the same term to start (or end) with different constants, where neither constant
+# is a prefix (or suffix) of the other, like `startswith(s, "a")` and `startswith(s, "b")`
+_mutually_exclusive(conditions, other) if {
+	some expr in conditions
+	some other_expr in other
+
+	[operator, term, value] := _affix_check(expr)
+	[other_operator, other_term, other_value] := _affix_check(other_expr)
+
+	[operator, term] == [other_operator, other_term]
+
+	not _holds(operator, [value, other_value])
+	not _holds(operator, [other_value, value])
+}
+
+# both bodies check the same term to be a prefix (or suffix) of constants with a different first (or
+# last) character, like `startswith("true", s)` and `startswith("false", s)`. only the empty string
+# satisfies both, which isn't considered a conflict
+_mutually_exclusive(conditions, other) if {
+	some expr in conditions
+	some other_expr in other
+
+	[operator, term, value] := _affix_of_constant(expr)
+	[other_operator, other_term, other_value] := _affix_of_constant(other_expr)
+
+	[operator, term] == [other_operator, other_term]
+
+	_edge(operator, value) != _edge(operator, other_value)
+}
+
+_affix_check(expr) := [operator, expr.terms[1], expr.terms[2].value] if {
+	not expr.negated
+
+	operator := ast.ref_to_string(expr.terms[0].value)
+	operator in {"startswith", "endswith"}
+
+	not ast.is_constant(expr.terms[1])
+	expr.terms[2].type == "string"
+}
+
+_affix_of_constant(expr) := [operator, expr.terms[2], expr.terms[1].value] if {
+	not expr.negated
+
+	operator := ast.ref_to_string(expr.terms[0].value)
+	operator in {"startswith", "endswith"}
+
+	expr.terms[1].type == "string"
+	not ast.is_constant(expr.terms[2])
+}
+
+_edge("startswith", str) := substring(str, 0, 1)
+_edge("endswith", str) := substring(str, count(str) - 1, 1)
+
+_complementary := {
+	["equal", "neq"], ["neq", "equal"],
+	["lt", "gte"], ["gte", "lt"],
+	["gt", "lte"], ["lte", "gt"],
+}
+
+# a constant argument in one definition fails a condition on the same argument in the other,
+# like `f(0)` and `f(x) if x > 0`
+_excluded_by_args(args, other_args, other_conditions) if {
+	some i, pattern in args
+	not _is_any(pattern)
+	_is_any(other_args[i])
+
+	some expr in other_conditions
+
+	count(expr.terms) == 3
+
+	operands := [operand |
+		some term in array.slice(expr.terms, 1, 3)
+		operand := _operand(term, $"$arg{i}", pattern.value)
+	]
+	count(operands) == 2
+
+	operator := ast.ref_to_string(expr.terms[0].value)
+	operator in _evaluated_operators
+
+	negated := object.get(expr, "negated", false)
+
+	_fails(operator, operands, negated)
+}
+
+_fails(operator, operands, false) if not _holds(operator, operands)
+_fails(operator, operands, true) if _holds(operator, operands)
+
+_operand(term, var, value) := value if {
+	term.type == "var"
+	term.value == var
+}
+
+_operand(term, _, _) := term.value if term.type in ast.scalar_types
+
+_evaluated_operators := {"equal", "neq", "lt", "lte", "gt", "gte", "startswith", "endswith", "contains"}
+
+_holds("equal", [a, b]) if a == b
+_holds("neq", [a, b]) if a != b
+_holds("lt", [a, b]) if a < b
+_holds("lte", [a, b]) if a <= b
+_holds("gt", [a, b]) if a > b
+_holds("gte", [a, b]) if a >= b
+_holds("startswith", [a, b]) if startswith(a, b)
+_holds("endswith", [a, b]) if endswith(a, b)
+_holds("contains", [a, b]) if contains(a, b)
+
+_compared(expr) := [expr.terms[i], expr.terms[j]] if {
+	not expr.negated
+
+	expr.terms[0].value[0].value in {"equal", "eq"}
+
+	some [i, j] in [[1, 2], [2, 1]]
+
+	not ast.is_constant(expr.terms[i])
+	ast.is_constant(expr.terms[j])
+}
+
+# the same argument may be named differently in each definition of a function, so vars
+# declared as arguments are renamed by their position, like "$arg0" for the first one
+_arg_renames(args) := {arg.value: $"$arg{i}" |
+	some i, arg in args
+	arg.type == "var"
+}
+
+# removes the location of each term in the AST node, and renames vars according to renames. only
+# terms are patched, so string constants equal to the name of a var, or to "location", are left as is
+_normalized(node, renames) := json.patch(node, array.concat(
+	[{"op": "remove", "path": array.flatten([path, "location"])} |
+		walk(node, [path, term])
+		term.location
+	],
+	[{"op": "replace", "path": array.flatten([path, "value"]), "value": rename} |
+		renames != {}
+
+		walk(node, [path, term])
+		term.type == "var"
+
+		rename := renames[term.value]
+	],
+))
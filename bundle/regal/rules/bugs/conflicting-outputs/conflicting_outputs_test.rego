@@ -0,0 +1,152 @@
+package regal.rules.bugs["conflicting-outputs_test"]
+
+import data.regal.rules.bugs["conflicting-outputs"] as rule
+
+test_fail_complete_rule_with_conflicting_values_across_files if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nrole := \"admin\" if input.user.admin\n",
+		"package p\n\nrole := \"guest\" if input.user.guest\n",
+	])
+
+	r == {{
+		"category": "bugs",
+		"description": "Rule data.p.role may produce a different value than its definition at p1.rego:3",
+		"level": "error",
+		"location": {
+			"file": "p2.rego",
+			"row": 3,
+			"col": 1,
+			"end": {"row": 3, "col": 5},
+			"text": "role := \"guest\" if input.user.guest",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/conflicting-outputs",
+		}],
+		"title": "conflicting-outputs",
+	}}
+}
+
+test_fail_implicit_true_conflicts_with_false if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nallow if input.admin\n\nallow := false if input.blocked\n",
+		"package q\n\nallow := false\n",
+	])
+
+	{v.description | some v in r} == {"Rule data.p.allow may produce a different value than its definition at p1.rego:3"}
+}
+
+test_fail_function_with_overlapping_args if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nf(1) := \"one\"\n\nf(2) := \"two\"\n",
+		"package p\n\nf(_) := \"any\"\n",
+	])
+
+	{v.description | some v in r} == {"Function data.p.f may produce a different value than its definition at p1.rego:3"}
+}
+
+test_success_same_value if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nallow if input.admin\n",
+		"package p\n\nallow if input.superuser\n",
+	])
+
+	r == set()
+}
+
+test_success_mutually_exclusive_conditions if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nallow if input.admin\n\nlevel := 1 if input.role == \"a\"\n\ng(x) := 1 if x > 10\n",
+		"package p\n\nallow := false if not input.admin\n\nlevel := 2 if \"b\" == input.role\n\ng(y) := 2 if not y > 10\n",
+	])
+
+	r == set()
+}
+
+test_success_complementary_comparisons if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nkind(args) := \"function\" if args != []\n\nsize(n) := \"small\" if n < 10\n",
+		"package p\n\nkind(args) := \"rule\" if args == []\n\nsize(n) := \"large\" if n >= 10\n",
+	])
+
+	r == set()
+}
+
+test_success_mutually_exclusive_affixes if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nscheme(url) := \"https\" if startswith(url, \"https://\")\n",
+		"package p\n\nscheme(url) := \"http\" if startswith(url, \"http://\")\n",
+		"package p\n\nbool(s) := \"true\" if startswith(\"true\", s)\n",
+		"package p\n\nbool(s) := \"false\" if startswith(\"false\", s)\n",
+	])
+
+	r == set()
+}
+
+test_fail_overlapping_affixes if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nscheme(url) := \"http\" if startswith(url, \"http\")\n",
+		"package p\n\nscheme(url) := \"https\" if startswith(url, \"https\")\n",
+		"package p\n\nkind(s) := \"test\" if startswith(\"test\", s)\n",
+		"package p\n\nkind(s) := \"text\" if startswith(\"text\", s)\n",
+	])
+
+	{v.description | some v in r} == {
+		"Function data.p.scheme may produce a different value than its definition at p1.rego:3",
+		"Function data.p.kind may produce a different value than its definition at p3.rego:3",
+	}
+}
+
+test_success_constant_args_failing_conditions_of_other_definition if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\ntype(0) := \"var\"\n\nclient(\"Zed\") := 1\n",
+		"package p\n\ntype(x) := \"string\" if x > 0\n\nclient(name) := 2 if contains(name, \"IntelliJ\")\n",
+	])
+
+	r == set()
+}
+
+test_fail_constant_args_passing_conditions_of_other_definition if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nclient(\"IntelliJ IDEA\") := 1\n",
+		"package p\n\nclient(name) := 2 if contains(name, \"IntelliJ\")\n",
+	])
+
+	{v.location.row | some v in r} == {3}
+}
+
+test_success_functions_with_disjoint_args if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\nf(1, _) := \"one\"\n",
+		"package p\n\nf(2, _) := \"two\"\n",
+	])
+
+	r == set()
+}
+
+test_success_non_constant_values_and_defaults if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package p\n\ndefault x := 1\n\nx := input.x\n\ny := input.y if input.a\n",
+		"package p\n\nx := 2 if input.b\n\ny := 3 if input.b\n\ns contains 1\n\ns contains 2\n",
+	])
+
+	r == set()
+}
+
+test_normalized_removes_locations_and_renames_vars_only if {
+	body := regal.parse_module("p.rego", "package p\n\nf(x) := 1 if x == \"x\"\n").rules[0].body
+
+	rule._normalized(body, {"x": "$arg0"}) == [{"terms": [
+		{"type": "ref", "value": [{"type": "var", "value": "equal"}]},
+		{"type": "var", "value": "$arg0"},
+		{"type": "string", "value": "x"},
+	]}]
+}
+
+_aggregates(policies) := {file: {"bugs/conflicting-outputs": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
@@ -0,0 +1,26 @@
+# METADATA
+# description: Call to `count` on value that can't be counted
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/count-on-scalar
+package regal.rules.bugs["count-on-scalar"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	call := ast.function_calls[_][_]
+
+	call.name == "count"
+
+	kinds := ast.inferred_kinds(call.args[0])
+
+	kinds & {"array", "object", "set", "string"} == set()
+
+	type := ast.inferred_type(call.args[0]).type
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.location(call),
+		{"description": $"Call to `count` on value of type {type}, which can't be counted"},
+	))
+}
@@ -0,0 +1,43 @@
+package regal.rules.bugs["count-on-scalar_test"]
+
+import data.regal.ast
+import data.regal.rules.bugs["count-on-scalar"] as rule
+
+test_fail_count_on_number if {
+	r := rule.report with input as ast.policy(`allow if {
+		n := to_number(input.n)
+		count(n) > 1
+	}`)
+
+	r == {{
+		"category": "bugs",
+		"description": "Call to `count` on value of type number, which can't be counted",
+		"level": "error",
+		"location": {
+			"col": 3,
+			"file": "policy.rego",
+			"row": 5,
+			"end": {
+				"col": 8,
+				"row": 5,
+			},
+			"text": "\t\tcount(n) > 1",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/count-on-scalar",
+		}],
+		"title": "count-on-scalar",
+	}}
+}
+
+test_success_count_on_countable_values if {
+	r := rule.report with input as ast.policy(`allow if {
+		s := concat(".", ["a", "b"])
+		count(s) > 1
+		count(input.users) > 1
+		count({1, 2}) > 1
+	}`)
+
+	r == set()
+}
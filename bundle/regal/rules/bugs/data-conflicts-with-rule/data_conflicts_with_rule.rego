@@ -0,0 +1,36 @@
+# METADATA
+# description: Data file conflicts with rule
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/data-conflicts-with-rule
+package regal.rules.bugs["data-conflicts-with-rule"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some data_file in aggregated.data_files
+	some key in data_file.keys
+
+	_conflicts(key.leaf, object.get(aggregated.rule_tree, key.path, null))
+
+	path := concat(".", key.path)
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": key.location,
+		"description": $"Data at {path} conflicts with rule of the same path",
+	})
+}
+
+# a rule is declared at the path of the key
+_conflicts(_, tree) if tree == {}
+
+# the key is a value at the path of a package, or a prefix of a rule
+_conflicts(leaf, tree) if {
+	leaf == true
+	is_object(tree)
+	tree != {}
+}
@@ -0,0 +1,66 @@
+package regal.rules.bugs["data-conflicts-with-rule_test"]
+
+import data.regal.rules.bugs["data-conflicts-with-rule"] as rule
+
+test_fail_data_key_at_rule_path if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.data_files as {"policy/data.yaml": {"keys": [{
+			"path": ["data", "policy", "allow"],
+			"leaf": true,
+			"location": _location(1, "allow: true"),
+		}]}}
+
+	r == {{
+		"category": "bugs",
+		"description": "Data at data.policy.allow conflicts with rule of the same path",
+		"level": "error",
+		"location": _location(1, "allow: true"),
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/data-conflicts-with-rule",
+		}],
+		"title": "data-conflicts-with-rule",
+	}}
+}
+
+test_fail_data_value_at_package_path if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.data_files as {"data.yaml": {"keys": [{
+			"path": ["data", "policy"],
+			"leaf": true,
+			"location": _location(1, "policy: true"),
+		}]}}
+
+	count(r) == 1
+}
+
+test_success_data_next_to_rules if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.data_files as {"data.yaml": {"keys": [
+			{
+				"path": ["data", "policy"],
+				"leaf": false,
+				"location": _location(1, "policy:"),
+			},
+			{
+				"path": ["data", "policy", "roles"],
+				"leaf": true,
+				"location": _location(2, "  roles: []"),
+			},
+		]}}
+
+	r == set()
+}
+
+_aggregates := {"p1.rego": {"common": {{"rule_tree": {"data": {"policy": {"allow": {}}}}}}}}
+
+_location(row, text) := {
+	"file": "policy/data.yaml",
+	"row": row,
+	"col": 1,
+	"end": {"row": row, "col": 6},
+	"text": text,
+}
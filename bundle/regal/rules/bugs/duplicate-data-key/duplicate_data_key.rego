@@ -0,0 +1,23 @@
+# METADATA
+# description: Duplicate key in data file
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/duplicate-data-key
+package regal.rules.bugs["duplicate-data-key"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	key := aggregated.data_files[_].duplicates[_]
+
+	path := concat(".", key.path)
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": key.location,
+		"description": $"Duplicate key {path} in data file",
+	})
+}
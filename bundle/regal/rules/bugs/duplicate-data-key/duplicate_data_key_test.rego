@@ -0,0 +1,37 @@
+package regal.rules.bugs["duplicate-data-key_test"]
+
+import data.regal.rules.bugs["duplicate-data-key"] as rule
+
+test_fail_duplicate_data_key if {
+	location := {
+		"file": "data.yaml",
+		"row": 3,
+		"col": 3,
+		"end": {"row": 3, "col": 8},
+		"text": "  admin: false",
+	}
+
+	r := rule.aggregate_report with input.data_files as {"data.yaml": {"duplicates": [{
+		"path": ["data", "users", "admin"],
+		"leaf": true,
+		"location": location,
+	}]}}
+
+	r == {{
+		"category": "bugs",
+		"description": "Duplicate key data.users.admin in data file",
+		"level": "error",
+		"location": location,
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/duplicate-data-key",
+		}],
+		"title": "duplicate-data-key",
+	}}
+}
+
+test_success_no_duplicate_data_keys if {
+	r := rule.aggregate_report with input.data_files as {"data.yaml": {"duplicates": []}}
+
+	r == set()
+}
@@ -0,0 +1,37 @@
+# METADATA
+# description: Comparison of values that can never be equal
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/impossible-comparison
+package regal.rules.bugs["impossible-comparison"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	terms := input.rules[_].body[_].terms
+
+	terms[0].value[0].value == "equal"
+	count(terms) == 3
+
+	# comparing two constants is reported by constant-condition
+	not _both_scalar(terms[1].type, terms[2].type)
+
+	lhs := ast.inferred_kinds(terms[1])
+	rhs := ast.inferred_kinds(terms[2])
+
+	lhs & rhs == set()
+
+	lhs_type := ast.inferred_type(terms[1]).type
+	rhs_type := ast.inferred_type(terms[2]).type
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.infix_expr_location(terms),
+		{"description": $"Comparison of {lhs_type} and {rhs_type} can never be true"},
+	))
+}
+
+_both_scalar(lhs_type, rhs_type) if {
+	lhs_type in ast.scalar_types
+	rhs_type in ast.scalar_types
+}
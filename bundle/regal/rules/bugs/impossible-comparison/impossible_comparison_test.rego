@@ -0,0 +1,81 @@
+package regal.rules.bugs["impossible-comparison_test"]
+
+import data.regal.ast
+import data.regal.rules.bugs["impossible-comparison"] as rule
+
+test_fail_string_compared_to_number if {
+	r := rule.report with input as ast.policy(`allow if {
+		x := count(input.users)
+		x == "admin"
+	}`)
+
+	r == {{
+		"category": "bugs",
+		"description": "Comparison of number and string can never be true",
+		"level": "error",
+		"location": {
+			"col": 3,
+			"file": "policy.rego",
+			"row": 5,
+			"end": {
+				"col": 7,
+				"row": 5,
+			},
+			"text": "\t\tx == \"admin\"",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/impossible-comparison",
+		}],
+		"title": "impossible-comparison",
+	}}
+}
+
+test_fail_compared_to_input_with_schema if {
+	r := rule.report with input as ast.policy(`# METADATA
+# schemas:
+#   - input:
+#       type: object
+#       properties:
+#         age:
+#           type: number
+allow if input.age == "18"`)
+
+	r == {{
+		"category": "bugs",
+		"description": "Comparison of number and string can never be true",
+		"level": "error",
+		"location": {
+			"col": 10,
+			"file": "policy.rego",
+			"row": 10,
+			"end": {
+				"col": 22,
+				"row": 10,
+			},
+			"text": "allow if input.age == \"18\"",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/impossible-comparison",
+		}],
+		"title": "impossible-comparison",
+	}}
+}
+
+test_success_comparison_of_compatible_types if {
+	r := rule.report with input as ast.policy(`allow if {
+		x := count(input.users)
+		x == input.max
+		y := input.name
+		y == "admin"
+	}`)
+
+	r == set()
+}
+
+test_success_constant_comparison_left_to_constant_condition if {
+	r := rule.report with input as ast.policy(`allow if 1 == "1"`)
+
+	r == set()
+}
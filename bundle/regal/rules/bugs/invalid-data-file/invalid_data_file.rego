@@ -0,0 +1,21 @@
+# METADATA
+# description: Data file can't be parsed
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/invalid-data-file
+package regal.rules.bugs["invalid-data-file"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some name, file in aggregated.data_files
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": file.error.location,
+		"description": $"Data file {name} can't be parsed: {file.error.message}",
+	})
+}
@@ -0,0 +1,37 @@
+package regal.rules.bugs["invalid-data-file_test"]
+
+import data.regal.rules.bugs["invalid-data-file"] as rule
+
+test_fail_invalid_data_file if {
+	location := {
+		"file": "data.json",
+		"row": 2,
+		"col": 1,
+		"end": {"row": 2, "col": 13},
+		"text": "  \"users\": [",
+	}
+
+	r := rule.aggregate_report with input.data_files as {"data.json": {
+		"keys": [],
+		"duplicates": [],
+		"error": {"message": "did not find expected node content", "location": location},
+	}}
+
+	r == {{
+		"category": "bugs",
+		"description": "Data file data.json can't be parsed: did not find expected node content",
+		"level": "error",
+		"location": location,
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/invalid-data-file",
+		}],
+		"title": "invalid-data-file",
+	}}
+}
+
+test_success_valid_data_file if {
+	r := rule.aggregate_report with input.data_files as {"data.json": {"keys": [], "duplicates": []}}
+
+	r == set()
+}
@@ -0,0 +1,34 @@
+# METADATA
+# description: Iteration over value that isn't a collection
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/non-collection-iteration
+package regal.rules.bugs["non-collection-iteration"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	some collection in _iterated
+
+	kinds := ast.inferred_kinds(collection)
+
+	kinds & {"array", "object", "set"} == set()
+
+	type := ast.inferred_type(collection).type
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.location(collection),
+		{"description": $"Iteration over value of type {type}, which isn't a collection"},
+	))
+}
+
+# some x in coll
+_iterated contains regal.last(call.value) if {
+	[call] := ast.found.symbols[_][_]
+
+	call.type == "call"
+}
+
+# every x in coll { ... }
+_iterated contains ast.found.every[_][_].domain
@@ -0,0 +1,76 @@
+package regal.rules.bugs["non-collection-iteration_test"]
+
+import data.regal.ast
+import data.regal.rules.bugs["non-collection-iteration"] as rule
+
+test_fail_some_in_over_string if {
+	r := rule.report with input as ast.policy(`allow if {
+		name := concat(".", ["a", "b"])
+		some x in name
+		x == "a"
+	}`)
+
+	r == {{
+		"category": "bugs",
+		"description": "Iteration over value of type string, which isn't a collection",
+		"level": "error",
+		"location": {
+			"col": 13,
+			"file": "policy.rego",
+			"row": 5,
+			"end": {
+				"col": 17,
+				"row": 5,
+			},
+			"text": "\t\tsome x in name",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/non-collection-iteration",
+		}],
+		"title": "non-collection-iteration",
+	}}
+}
+
+test_fail_every_over_number if {
+	r := rule.report with input as ast.policy(`allow if {
+		n := count(input.users)
+		every x in n {
+			x > 1
+		}
+	}`)
+
+	r == {{
+		"category": "bugs",
+		"description": "Iteration over value of type number, which isn't a collection",
+		"level": "error",
+		"location": {
+			"col": 14,
+			"file": "policy.rego",
+			"row": 5,
+			"end": {
+				"col": 15,
+				"row": 5,
+			},
+			"text": "\t\tevery x in n {",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/non-collection-iteration",
+		}],
+		"title": "non-collection-iteration",
+	}}
+}
+
+test_success_iteration_over_collections if {
+	r := rule.report with input as ast.policy(`allow if {
+		names := {n | some n in input.names}
+		some x in names
+		some y in input.users
+		every z in [1, 2] {
+			z > x
+		}
+	}`)
+
+	r == set()
+}
@@ -0,0 +1,29 @@
+# METADATA
+# description: Argument to function expecting a string may not be a string
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bugs/non-string-argument
+package regal.rules.bugs["non-string-argument"]
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+
+report contains violation if {
+	call := ast.found.calls[_][_]
+	name := ast.ref_to_string(call[0].value)
+
+	some i, arg in array.slice(call, 1, count(call))
+
+	config.capabilities.builtins[name].decl.args[i] == "string"
+
+	kinds := ast.inferred_kinds(arg)
+	kinds != {"string"}
+
+	type := ast.inferred_type(arg).type
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.location(arg),
+		{"description": $"Argument to {name} should be a string, but is of type {type}"},
+	))
+}
@@ -0,0 +1,81 @@
+package regal.rules.bugs["non-string-argument_test"]
+
+import data.regal.ast
+import data.regal.capabilities
+import data.regal.config
+import data.regal.rules.bugs["non-string-argument"] as rule
+
+test_fail_number_passed_to_string_function if {
+	r := rule.report with input as ast.policy(`allow if {
+		n := count(input.users)
+		startswith(n, "1")
+	}`)
+		with config.capabilities as capabilities.provided
+
+	r == {{
+		"category": "bugs",
+		"description": "Argument to startswith should be a string, but is of type number",
+		"level": "error",
+		"location": {
+			"col": 14,
+			"file": "policy.rego",
+			"row": 5,
+			"end": {
+				"col": 15,
+				"row": 5,
+			},
+			"text": "\t\tstartswith(n, \"1\")",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/non-string-argument",
+		}],
+		"title": "non-string-argument",
+	}}
+}
+
+test_fail_possibly_non_string_value_passed_to_string_function if {
+	r := rule.report with input as ast.policy(`# METADATA
+# schemas:
+#   - input:
+#       type: object
+#       properties:
+#         name:
+#           anyOf:
+#             - type: string
+#             - type: number
+allow if lower(input.name) == "admin"`)
+		with config.capabilities as capabilities.provided
+
+	r == {{
+		"category": "bugs",
+		"description": "Argument to lower should be a string, but is of type any<number, string>",
+		"level": "error",
+		"location": {
+			"col": 16,
+			"file": "policy.rego",
+			"row": 12,
+			"end": {
+				"col": 26,
+				"row": 12,
+			},
+			"text": "allow if lower(input.name) == \"admin\"",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bugs/non-string-argument",
+		}],
+		"title": "non-string-argument",
+	}}
+}
+
+test_success_strings_passed_to_string_function if {
+	r := rule.report with input as ast.policy(`allow if {
+		name := concat(".", ["a", "b"])
+		startswith(name, "a")
+		lower(input.name) == "admin"
+	}`)
+		with config.capabilities as capabilities.provided
+
+	r == set()
+}
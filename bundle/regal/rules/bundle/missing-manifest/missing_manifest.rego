@@ -0,0 +1,35 @@
+# METADATA
+# description: Bundle missing .manifest file
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bundle/missing-manifest
+# schemas:
+#   - input: schema.regal.ast
+package regal.rules.bundle["missing-manifest"]
+
+import data.regal.aggregated
+import data.regal.result
+import data.regal.util
+
+# METADATA
+# description: collects the location of the package declaration
+aggregate contains {"package_location": util.to_location_object(input.package.location)}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	count(aggregated.bundles) > 1
+
+	some bundle in aggregated.bundles
+	not bundle.manifest
+
+	file := min(object.keys(bundle.files))
+
+	some item in input.aggregates_internal[file]["bundle/missing-manifest"]
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": object.union(item.package_location, {"file": file}),
+		"description": $"Bundle {bundle.path} has no .manifest file declaring its roots, but is loaded with other bundles",
+	})
+}
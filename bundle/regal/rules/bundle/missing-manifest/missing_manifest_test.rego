@@ -0,0 +1,58 @@
+package regal.rules.bundle["missing-manifest_test"]
+
+import data.regal.rules.bundle["missing-manifest"] as rule
+
+test_fail_bundle_without_manifest_among_others if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [
+			{"path": "a", "manifest": {"file": "a/.manifest"}, "files": {"a/p.rego": {}}},
+			{"path": "b", "files": {"b/q.rego": {}, "b/p.rego": {}}},
+		]
+
+	r == {{
+		"category": "bundle",
+		"description": "Bundle b has no .manifest file declaring its roots, but is loaded with other bundles",
+		"level": "error",
+		"location": {
+			"file": "b/p.rego",
+			"row": 1,
+			"col": 1,
+			"end": {"row": 1, "col": 8},
+			"text": "package",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bundle/missing-manifest",
+		}],
+		"title": "missing-manifest",
+	}}
+}
+
+test_success_single_bundle_without_manifest if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [{"path": "b", "files": {"b/p.rego": {}}}]
+
+	r == set()
+}
+
+test_success_all_bundles_with_manifest if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [
+			{"path": "a", "manifest": {"file": "a/.manifest"}, "files": {"a/p.rego": {}}},
+			{"path": "b", "manifest": {"file": "b/.manifest"}, "files": {"b/p.rego": {}}},
+		]
+
+	r == set()
+}
+
+_aggregates[file] := {"bundle/missing-manifest": {{"package_location": {
+	"row": 1,
+	"col": 1,
+	"end": {"row": 1, "col": 8},
+	"text": "package",
+}}}} if {
+	some file in ["a/p.rego", "b/p.rego", "b/q.rego"]
+}
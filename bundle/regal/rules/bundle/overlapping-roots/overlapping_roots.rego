@@ -0,0 +1,46 @@
+# METADATA
+# description: Bundle roots overlap
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bundle/overlapping-roots
+package regal.rules.bundle["overlapping-roots"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some [file, i, root] in _roots
+	some [other_file, j, other] in _roots
+
+	# report each pair only once, on the root declared last
+	[other_file, j] < [file, i]
+
+	_overlap(split(root.value, "/"), split(other.value, "/"))
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": root.location,
+		"description": _description(root.value, other.value, file, other_file),
+	})
+}
+
+_roots contains [bundle.manifest.file, i, root] if {
+	some bundle in aggregated.bundles
+	some i, root in bundle.manifest.roots
+}
+
+_overlap(a, b) if _contains(a, b)
+_overlap(a, b) if _contains(b, a)
+
+# the empty root contains all paths
+_contains([""], _)
+
+_contains(root, path) if array.slice(path, 0, count(root)) == root
+
+_description(root, other, file, other_file) := $`Root "{root}" overlaps with root "{other}"` if file == other_file
+
+_description(root, other, file, other_file) := $`Root "{root}" overlaps with root "{other}" in {other_file}` if {
+	file != other_file
+}
@@ -0,0 +1,57 @@
+package regal.rules.bundle["overlapping-roots_test"]
+
+import data.regal.rules.bundle["overlapping-roots"] as rule
+
+test_fail_overlapping_roots_in_manifest if {
+	r := rule.aggregate_report with input.bundles as [_bundle("a/.manifest", ["authz", "authz/users"])]
+
+	r == {{
+		"category": "bundle",
+		"description": `Root "authz/users" overlaps with root "authz"`,
+		"level": "error",
+		"location": _location("a/.manifest", 1, "authz/users"),
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bundle/overlapping-roots",
+		}],
+		"title": "overlapping-roots",
+	}}
+}
+
+test_fail_empty_root_overlaps_all_roots if {
+	r := rule.aggregate_report with input.bundles as [_bundle("a/.manifest", ["", "authz"])]
+
+	{v.description | some v in r} == {`Root "authz" overlaps with root ""`}
+}
+
+test_fail_overlapping_roots_in_different_manifests if {
+	r := rule.aggregate_report with input.bundles as [
+		_bundle("a/.manifest", ["authz/users"]),
+		_bundle("b/.manifest", ["authz"]),
+	]
+
+	{v.description | some v in r} == {`Root "authz" overlaps with root "authz/users" in a/.manifest`}
+}
+
+test_success_roots_not_overlapping if {
+	r := rule.aggregate_report with input.bundles as [
+		_bundle("a/.manifest", ["authz", "users"]),
+		_bundle("b/.manifest", ["authzv2"]),
+		{"path": "c", "files": {}},
+	]
+
+	r == set()
+}
+
+_bundle(file, roots) := {"path": file, "files": {}, "manifest": {
+	"file": file,
+	"roots": [{"value": root, "location": _location(file, i, root)} | some i, root in roots],
+}}
+
+_location(file, i, text) := {
+	"file": file,
+	"row": i + 1,
+	"col": 1,
+	"end": {"row": i + 1, "col": count(text) + 1},
+	"text": text,
+}
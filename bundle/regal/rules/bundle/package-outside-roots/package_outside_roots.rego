@@ -0,0 +1,49 @@
+# METADATA
+# description: Package outside of bundle roots
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bundle/package-outside-roots
+# schemas:
+#   - input: schema.regal.ast
+package regal.rules.bundle["package-outside-roots"]
+
+import data.regal.aggregated
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+# METADATA
+# description: collects the location of the package declaration
+aggregate contains {"package_location": util.to_location_object(input.package.location)}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some bundle in aggregated.bundles
+
+	# without declared roots, all packages are permitted
+	declared := bundle.manifest.roots
+
+	some file, _ in bundle.files
+	path := input.aggregates_internal[file].common[_].package_path
+
+	every root in declared {
+		not _contains(split(root.value, "/"), path)
+	}
+
+	some item in input.aggregates_internal[file]["bundle/package-outside-roots"]
+
+	pkg := concat(".", path)
+	roots := concat(", ", [$`"{root.value}"` | some root in declared])
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": object.union(item.package_location, {"file": file}),
+		"description": $"Package {pkg} is outside of the roots declared in {bundle.manifest.file}: {roots}",
+	})
+}
+
+# the empty root contains all paths
+_contains([""], _)
+
+_contains(root, path) if array.slice(path, 0, count(root)) == root
@@ -0,0 +1,88 @@
+package regal.rules.bundle["package-outside-roots_test"]
+
+import data.regal.rules.bundle["package-outside-roots"] as rule
+
+test_aggregate_package_location if {
+	module := regal.parse_module("p.rego", "package authz.users")
+
+	rule.aggregate with input as module == {{"package_location": {
+		"col": 1,
+		"row": 1,
+		"end": {"col": 8, "row": 1},
+		"text": "package",
+	}}}
+}
+
+test_fail_package_outside_roots if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates({"a/p.rego": ["users", "admin"]})
+		with input.bundles as [_bundle(["authz", "users/alice"], {"a/p.rego"})]
+
+	r == {{
+		"category": "bundle",
+		"description": `Package users.admin is outside of the roots declared in a/.manifest: "authz", "users/alice"`,
+		"level": "error",
+		"location": {
+			"file": "a/p.rego",
+			"row": 1,
+			"col": 1,
+			"end": {"row": 1, "col": 8},
+			"text": "package",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bundle/package-outside-roots",
+		}],
+		"title": "package-outside-roots",
+	}}
+}
+
+test_fail_package_prefix_of_root if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates({"a/p.rego": ["authz"]})
+		with input.bundles as [_bundle(["authz/users"], {"a/p.rego"})]
+
+	count(r) == 1
+}
+
+test_success_packages_inside_roots if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates({"a/p1.rego": ["authz"], "a/p2.rego": ["users", "alice", "roles"]})
+		with input.bundles as [_bundle(["authz", "users/alice"], {"a/p1.rego", "a/p2.rego"})]
+
+	r == set()
+}
+
+test_success_empty_root if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates({"a/p.rego": ["authz"]})
+		with input.bundles as [_bundle([""], {"a/p.rego"})]
+
+	r == set()
+}
+
+test_success_no_roots_declared if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates({"a/p.rego": ["authz"]})
+		with input.bundles as [{"path": "a", "manifest": {"file": "a/.manifest"}, "files": {"a/p.rego": {}}}]
+
+	r == set()
+}
+
+_bundle(roots, files) := {
+	"path": "a",
+	"manifest": {"file": "a/.manifest", "roots": [{"value": root} | some root in roots]},
+	"files": {file: {} | some file in files},
+}
+
+_aggregates(paths) := {file: {
+	"common": {{"package_path": path}},
+	"bundle/package-outside-roots": {{"package_location": {
+		"row": 1,
+		"col": 1,
+		"end": {"row": 1, "col": 8},
+		"text": "package",
+	}}},
+} |
+	some file, path in paths
+}
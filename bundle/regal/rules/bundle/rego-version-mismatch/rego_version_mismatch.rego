@@ -0,0 +1,27 @@
+# METADATA
+# description: Rego version in manifest doesn't match syntax
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bundle/rego-version-mismatch
+package regal.rules.bundle["rego-version-mismatch"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some bundle in aggregated.bundles
+	some file, attributes in bundle.files
+
+	attributes.rego_version.parses == false
+
+	declared := attributes.rego_version.value
+	used := 1 - declared
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": attributes.rego_version.location,
+		"description": $"Rego v{declared} declared for {file}, which uses v{used} syntax",
+	})
+}
@@ -0,0 +1,36 @@
+package regal.rules.bundle["rego-version-mismatch_test"]
+
+import data.regal.rules.bundle["rego-version-mismatch"] as rule
+
+test_fail_declared_version_not_used if {
+	r := rule.aggregate_report with input.bundles as [{"path": "a", "files": {
+		"a/p.rego": {"rego_version": {"value": 0, "parses": false, "location": _location}},
+		"a/q.rego": {"rego_version": {"value": 0, "parses": true, "location": _location}},
+	}}]
+
+	r == {{
+		"category": "bundle",
+		"description": "Rego v0 declared for a/p.rego, which uses v1 syntax",
+		"level": "error",
+		"location": _location,
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bundle/rego-version-mismatch",
+		}],
+		"title": "rego-version-mismatch",
+	}}
+}
+
+test_success_no_declared_version if {
+	r := rule.aggregate_report with input.bundles as [{"path": "a", "files": {"a/p.rego": {}}}]
+
+	r == set()
+}
+
+_location := {
+	"file": "a/.manifest",
+	"row": 2,
+	"col": 3,
+	"end": {"row": 2, "col": 17},
+	"text": `  "rego_version": 0`,
+}
@@ -0,0 +1,37 @@
+# METADATA
+# description: Wasm entrypoint in manifest doesn't resolve to a rule
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/bundle/unresolved-wasm-entrypoint
+package regal.rules.bundle["unresolved-wasm-entrypoint"]
+
+import data.regal.aggregated
+import data.regal.result
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some bundle in aggregated.bundles
+	some resolver in bundle.manifest.wasm
+
+	path := array.flatten(["data", split(resolver.entrypoint, "/")])
+
+	not _resolves(path)
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": resolver.location,
+		"description": $"Entrypoint {resolver.entrypoint} for wasm module {resolver.module} doesn't resolve to a rule",
+	})
+}
+
+# the entrypoint is a rule or package
+_resolves(path) if object.get(aggregated.rule_tree, path, null) != null
+
+# the entrypoint is a path into the value of a rule
+_resolves(path) if {
+	some i in numbers.range(2, count(path) - 1)
+	rule := object.get(aggregated.rule_tree, array.slice(path, 0, i), null)
+
+	rule == {}
+}
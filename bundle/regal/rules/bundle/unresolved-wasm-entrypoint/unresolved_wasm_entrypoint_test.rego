@@ -0,0 +1,60 @@
+package regal.rules.bundle["unresolved-wasm-entrypoint_test"]
+
+import data.regal.rules.bundle["unresolved-wasm-entrypoint"] as rule
+
+test_fail_unresolved_entrypoint if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [_bundle("authz/deny")]
+
+	r == {{
+		"category": "bundle",
+		"description": "Entrypoint authz/deny for wasm module /policy.wasm doesn't resolve to a rule",
+		"level": "error",
+		"location": _location,
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/bundle/unresolved-wasm-entrypoint",
+		}],
+		"title": "unresolved-wasm-entrypoint",
+	}}
+}
+
+test_success_entrypoint_resolves_to_rule if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [_bundle("authz/allow")]
+
+	r == set()
+}
+
+test_success_entrypoint_resolves_to_package if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [_bundle("authz")]
+
+	r == set()
+}
+
+test_success_entrypoint_resolves_to_value_of_rule if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates
+		with input.bundles as [_bundle("authz/allow/reason")]
+
+	r == set()
+}
+
+_aggregates := {"p.rego": {"common": {{"rule_tree": {"data": {"authz": {"allow": {}}}}}}}}
+
+_bundle(entrypoint) := {"path": "a", "files": {}, "manifest": {
+	"file": "a/.manifest",
+	"wasm": [{"entrypoint": entrypoint, "module": "/policy.wasm", "location": _location}],
+}}
+
+_location := {
+	"file": "a/.manifest",
+	"row": 3,
+	"col": 21,
+	"end": {"row": 3, "col": 33},
+	"text": `    {"entrypoint": "authz/deny", "module": "/policy.wasm"}`,
+}
@@ -0,0 +1,19 @@
+# METADATA
+# description: Related resource not a valid URL
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/custom/invalid-related-resource
+package regal.rules.custom["invalid-related-resource"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	some annotation in ast.annotations
+	some resource in annotation.related_resources
+
+	# OPA accepts any ref it can parse as a URL, which includes relative ones like "docs"
+	not regex.match(`^https?://[^\s/?#]+([/?#]\S*)?$`, resource.ref)
+
+	violation := result.fail(rego.metadata.chain(), result.location_in_annotation(annotation, resource.ref))
+}
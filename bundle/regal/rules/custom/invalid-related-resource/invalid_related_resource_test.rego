@@ -0,0 +1,60 @@
+package regal.rules.custom["invalid-related-resource_test"]
+
+import data.regal.config
+
+import data.regal.rules.custom["invalid-related-resource"] as rule
+
+test_fail_related_resource_not_a_url if {
+	r := rule.report with input as regal.parse_module("p.rego", `# METADATA
+# related_resources:
+#   - docs/policy.md
+package p
+`)
+		with config.rules as {"custom": {"invalid-related-resource": {"level": "error"}}}
+
+	r == {{
+		"category": "custom",
+		"description": "Related resource not a valid URL",
+		"level": "error",
+		"location": {
+			"file": "p.rego",
+			"row": 3,
+			"col": 7,
+			"end": {"row": 3, "col": 21},
+			"text": "#   - docs/policy.md",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/custom/invalid-related-resource",
+		}],
+		"title": "invalid-related-resource",
+	}}
+}
+
+test_fail_related_resource_without_host_or_with_other_scheme if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+# METADATA
+# related_resources:
+#   - ref: https:///policy
+#     description: Policy
+#   - ftp://example.com/policy
+allow := true
+`)
+		with config.rules as {"custom": {"invalid-related-resource": {"level": "error"}}}
+
+	{v.location.row | some v in r} == {5, 7}
+}
+
+test_success_related_resources_valid_urls if {
+	r := rule.report with input as regal.parse_module("p.rego", `# METADATA
+# related_resources:
+#   - https://example.com
+#   - ref: http://example.com/docs/policy?version=1#allow
+#     description: Policy
+package p
+`)
+		with config.rules as {"custom": {"invalid-related-resource": {"level": "error"}}}
+
+	r == set()
+}
@@ -0,0 +1,37 @@
+# METADATA
+# description: Metadata annotation missing required attribute
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/custom/required-metadata-attributes
+package regal.rules.custom["required-metadata-attributes"]
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+
+report contains violation if {
+	some annotation in ast.annotations
+	some attribute in _required(annotation)
+
+	_missing(annotation, attribute)
+
+	violation := result.fail(rego.metadata.chain(), result.location_and_description(
+		annotation,
+		$"Metadata with scope {annotation.scope} missing required attribute: {attribute}",
+	))
+}
+
+_required(annotation) := {attribute |
+	some attribute in object.get(_cfg, ["attributes", annotation.scope], [])
+} | {attribute |
+	annotation.entrypoint == true
+
+	some attribute in object.get(_cfg, "entrypoint-attributes", [])
+}
+
+_missing(annotation, attribute) if not annotation[attribute]
+
+# attributes like `authors: []` are provided, but are as good as missing
+_missing(annotation, attribute) if annotation[attribute] in {"", [], {}}
+
+_cfg := config.rules.custom["required-metadata-attributes"]
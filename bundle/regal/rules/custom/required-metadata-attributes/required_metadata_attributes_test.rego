@@ -0,0 +1,90 @@
+package regal.rules.custom["required-metadata-attributes_test"]
+
+import data.regal.config
+
+import data.regal.rules.custom["required-metadata-attributes"] as rule
+
+test_fail_package_missing_required_attributes if {
+	r := rule.report with input as regal.parse_module("p.rego", `# METADATA
+# title: Policy
+package p
+`)
+		with config.rules as _rules({"package": ["title", "description"]}, [])
+
+	r == {{
+		"category": "custom",
+		"description": "Metadata with scope package missing required attribute: description",
+		"level": "error",
+		"location": {
+			"file": "p.rego",
+			"row": 1,
+			"col": 1,
+			"end": {"row": 2, "col": 16},
+			"text": "# METADATA",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/custom/required-metadata-attributes",
+		}],
+		"title": "required-metadata-attributes",
+	}}
+}
+
+test_fail_empty_attribute_is_missing if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+# METADATA
+# scope: document
+# authors: []
+allow := true
+`)
+		with config.rules as _rules({"document": ["authors"]}, [])
+
+	{v.description | some v in r} == {"Metadata with scope document missing required attribute: authors"}
+}
+
+test_fail_entrypoint_missing_description if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+# METADATA
+# entrypoint: true
+allow := true
+`)
+		with config.rules as _rules({}, ["description"])
+
+	{v.description | some v in r} == {"Metadata with scope document missing required attribute: description"}
+}
+
+test_success_required_attributes_provided if {
+	r := rule.report with input as regal.parse_module("p.rego", `# METADATA
+# title: Policy
+# description: Policy for all things
+package p
+
+# METADATA
+# description: Allow all things
+# entrypoint: true
+allow := true
+`)
+		with config.rules as _rules({"package": ["title", "description"]}, ["description"])
+
+	r == set()
+}
+
+test_success_attributes_not_required_for_other_scopes if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+# METADATA
+# title: Allow
+allow := true
+`)
+		with config.rules as _rules({"package": ["description"], "document": ["authors"]}, [])
+
+	r == set()
+}
+
+_rules(attributes, entrypoint_attributes) := {"custom": {"required-metadata-attributes": {
+	"level": "error",
+	"attributes": attributes,
+	"entrypoint-attributes": entrypoint_attributes,
+}}}
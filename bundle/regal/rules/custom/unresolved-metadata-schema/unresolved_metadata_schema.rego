@@ -0,0 +1,48 @@
+# METADATA
+# description: Schema referenced in metadata not found
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/custom/unresolved-metadata-schema
+package regal.rules.custom["unresolved-metadata-schema"]
+
+import data.regal.aggregated
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+
+# METADATA
+# description: collects the schemas referenced in metadata annotations, like `schema.k8s.pod`
+aggregate contains {"name": name, "location": location} if {
+	some annotation in ast.annotations
+	some schema in annotation.schemas
+
+	# schemas may also be defined inline, using `definition`
+	schema.schema
+
+	name := concat(".", array.slice(schema.schema, 1, 100))
+
+	# the path is written the same way as the ref, like `input.review`, and directly followed by a colon
+	path := $"{concat(".", schema.path)}:"
+	location := result.location_in_annotation(annotation, path).location
+}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	config.rules.custom["unresolved-metadata-schema"]["schema-paths"] != []
+
+	some file, aggregates in input.aggregates_internal
+	some entry in aggregates["custom/unresolved-metadata-schema"]
+
+	not entry.name in aggregated.schema_files
+	not entry.name in _provided
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": object.union(entry.location, {"file": file}),
+		"description": $"Schema schema.{entry.name} not found in any of the configured schema paths",
+	})
+}
+
+# the schemas Regal provides for custom linter rules
+_provided := {"regal.ast", "regal.aggregate"}
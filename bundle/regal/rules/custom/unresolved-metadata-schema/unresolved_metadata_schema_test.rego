@@ -0,0 +1,113 @@
+package regal.rules.custom["unresolved-metadata-schema_test"]
+
+import data.regal.config
+
+import data.regal.rules.custom["unresolved-metadata-schema"] as rule
+
+test_aggregate_schema_references if {
+	a := rule.aggregate with input as regal.parse_module("p.rego", `# METADATA
+# schemas:
+#   - input: schema.k8s.pod
+#   - data.users: schema["users"]
+#   - input.name: {"type": "string"}
+package p
+`)
+
+	a == {
+		{
+			"name": "k8s.pod",
+			"location": {
+				"file": "p.rego",
+				"row": 3,
+				"col": 7,
+				"end": {"row": 3, "col": 13},
+				"text": "#   - input: schema.k8s.pod",
+			},
+		},
+		{
+			"name": "users",
+			"location": {
+				"file": "p.rego",
+				"row": 4,
+				"col": 7,
+				"end": {"row": 4, "col": 18},
+				"text": `#   - data.users: schema["users"]`,
+			},
+		},
+	}
+}
+
+test_fail_schema_not_found if {
+	r := rule.aggregate_report with input as _input(["k8s.pod"])
+		with config.rules as _rules(["schemas"])
+
+	r == {{
+		"category": "custom",
+		"description": "Schema schema.users not found in any of the configured schema paths",
+		"level": "error",
+		"location": {
+			"file": "p.rego",
+			"row": 4,
+			"col": 7,
+			"end": {"row": 4, "col": 18},
+			"text": `#   - data.users: schema["users"]`,
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/custom/unresolved-metadata-schema",
+		}],
+		"title": "unresolved-metadata-schema",
+	}}
+}
+
+test_success_all_schemas_found if {
+	r := rule.aggregate_report with input as _input(["k8s.pod", "users"])
+		with config.rules as _rules(["schemas"])
+
+	r == set()
+}
+
+test_success_regal_schemas_provided if {
+	module := regal.parse_module("p.rego", `# METADATA
+# schemas:
+#   - input: schema.regal.ast
+package custom.regal.rules.naming["foo"]
+`)
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as module
+
+	r := rule.aggregate_report with input as {
+		"aggregates_internal": {"p.rego": {
+			"custom/unresolved-metadata-schema": agg,
+		}},
+		"schema_files": [],
+	}
+		with config.rules as _rules(["schemas"])
+
+	r == set()
+}
+
+test_success_no_schema_paths_configured if {
+	r := rule.aggregate_report with input as _input([])
+		with config.rules as _rules([])
+
+	r == set()
+}
+
+_input(schema_files) := {
+	"aggregates_internal": {"p.rego": {"custom/unresolved-metadata-schema": agg}},
+	"schema_files": schema_files,
+} if {
+	module := regal.parse_module("p.rego", `# METADATA
+# schemas:
+#   - input: schema.k8s.pod
+#   - data.users: schema["users"]
+package p
+`)
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as module
+}
+
+_rules(schema_paths) := {"custom": {"unresolved-metadata-schema": {"level": "error", "schema-paths": schema_paths}}}
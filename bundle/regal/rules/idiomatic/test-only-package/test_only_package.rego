@@ -0,0 +1,74 @@
+# METADATA
+# description: Package only referenced from tests
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/idiomatic/test-only-package
+package regal.rules.idiomatic["test-only-package"]
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.config
+import data.regal.result
+
+# METADATA
+# description: collects the rules and functions declared in each module, and the refs found in them
+aggregate contains {"references": aggregators.references}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	entrypoints := aggregated.reference_entrypoints(_cfg.entrypoints)
+
+	some pkg, referrers in _referrers
+
+	every referrer in referrers {
+		referrer in _test_rules
+	}
+
+	every name in _package_rules[pkg] {
+		not name in entrypoints
+	}
+
+	file := min(_package_files[pkg])
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		aggregated.location_object(aggregated.references[file].location, file),
+		{"description": $"Package {pkg} is only referenced from tests"},
+	))
+}
+
+_cfg := object.union({"entrypoints": []}, object.get(config.rules, ["idiomatic", "test-only-package"], {}))
+
+_package_files[module.package] contains file if {
+	some file, module in aggregated.references
+	not module.test
+}
+
+_package_rules[module.package] contains name if {
+	some module in aggregated.references
+	not module.test
+
+	some name, _ in module.rules
+}
+
+_package_of[name] := pkg if {
+	some pkg, names in _package_rules
+	some name in names
+}
+
+_test_rules contains name if {
+	some module in aggregated.references
+	module.test
+
+	some name, _ in module.rules
+}
+
+# rules and functions outside of the package referring to any rule or function in it
+_referrers[pkg] contains referrer if {
+	some referrer, targets in aggregated.reference_graph
+	some target in targets
+
+	pkg := _package_of[target]
+	object.get(_package_of, referrer, null) != pkg
+}
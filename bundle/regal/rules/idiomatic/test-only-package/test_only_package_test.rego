@@ -0,0 +1,53 @@
+package regal.rules.idiomatic["test-only-package_test"]
+
+import data.regal.config
+
+import data.regal.rules.idiomatic["test-only-package"] as rule
+
+test_fail_package_only_referenced_from_tests if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates(_policies)
+
+	r == {{
+		"category": "idiomatic",
+		"description": "Package data.mocks is only referenced from tests",
+		"level": "error",
+		"location": {
+			"file": "p3.rego",
+			"row": 1,
+			"col": 1,
+			"end": {"row": 1, "col": 8},
+			"text": "package mocks",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/idiomatic/test-only-package",
+		}],
+		"title": "test-only-package",
+	}}
+}
+
+test_success_package_with_configured_entrypoint if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"idiomatic": {"test-only-package": {"entrypoints": ["data.mocks.users"]}}}
+
+	r == set()
+}
+
+_policies := [
+	"package main\n\nimport data.lib\n\n# METADATA\n# entrypoint: true\nallow if lib.admin\n",
+	"package lib\n\nadmin if input.user == \"admin\"\n",
+	"package mocks\n\nusers := [\"admin\"]\n",
+	concat("\n", [
+		"package lib_test\n\nimport data.lib\nimport data.mocks\n",
+		"test_admin if lib.admin with input.user as mocks.users[0]\n",
+	]),
+]
+
+_aggregates(policies) := {file: {"idiomatic/test-only-package": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
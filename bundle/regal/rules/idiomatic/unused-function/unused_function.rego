@@ -0,0 +1,39 @@
+# METADATA
+# description: Function never referenced
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/idiomatic/unused-function
+package regal.rules.idiomatic["unused-function"]
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.config
+import data.regal.result
+
+# METADATA
+# description: collects the rules and functions declared in each module, and the refs found in them
+aggregate contains {"references": aggregators.references}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	reachable := aggregated.reachable_rules(_cfg.entrypoints, _cfg["ignore-test-references"])
+
+	some file, module in aggregated.references
+	not module.test
+
+	some name, rule in module.rules
+	rule.function
+	not name in reachable
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		aggregated.location_object(rule.location, file),
+		{"description": $"Function {name} is never referenced from any entrypoint"},
+	))
+}
+
+_cfg := object.union(
+	{"entrypoints": [], "ignore-test-references": false},
+	object.get(config.rules, ["idiomatic", "unused-function"], {}),
+)
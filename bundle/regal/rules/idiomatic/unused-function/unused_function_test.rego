@@ -0,0 +1,54 @@
+package regal.rules.idiomatic["unused-function_test"]
+
+import data.regal.config
+
+import data.regal.rules.idiomatic["unused-function"] as rule
+
+test_fail_functions_not_reachable_from_entrypoint if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates(_policies)
+
+	r == {{
+		"category": "idiomatic",
+		"description": "Function data.lib.unused is never referenced from any entrypoint",
+		"level": "error",
+		"location": {
+			"file": "p2.rego",
+			"row": 5,
+			"col": 1,
+			"end": {"row": 5, "col": 7},
+			"text": "unused(x) := x * 2",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/idiomatic/unused-function",
+		}],
+		"title": "unused-function",
+	}}
+}
+
+test_fail_functions_only_referenced_from_tests_when_test_references_ignored if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"idiomatic": {"unused-function": {"ignore-test-references": true}}}
+
+	descriptions := {violation.description | some violation in r}
+
+	descriptions == {
+		"Function data.lib.unused is never referenced from any entrypoint",
+		"Function data.lib.tested is never referenced from any entrypoint",
+	}
+}
+
+_policies := [
+	"package main\n\nimport data.lib\n\n# METADATA\n# entrypoint: true\nallow if lib.double(1) == 2\n",
+	"package lib\n\ndouble(x) := x * 2\n\nunused(x) := x * 2\n\ntested(x) := x\n",
+	"package lib_test\n\nimport data.lib\n\ntest_tested if lib.tested(1) == 1\n",
+]
+
+_aggregates(policies) := {file: {"idiomatic/unused-function": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
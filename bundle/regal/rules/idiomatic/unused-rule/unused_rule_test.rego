@@ -0,0 +1,83 @@
+package regal.rules.idiomatic["unused-rule_test"]
+
+import data.regal.config
+
+import data.regal.rules.idiomatic["unused-rule"] as rule
+
+test_fail_rules_not_reachable_from_entrypoint if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates(_policies)
+
+	r == {
+		_with_location("Rule data.main.unused is never referenced from any entrypoint", {
+			"file": "p1.rego",
+			"row": 9,
+			"col": 1,
+			"end": {"row": 9, "col": 7},
+			"text": "unused if true",
+		}),
+		_with_location("Rule data.lib.orphan is never referenced from any entrypoint", {
+			"file": "p2.rego",
+			"row": 7,
+			"col": 1,
+			"end": {"row": 7, "col": 7},
+			"text": "orphan := 1",
+		}),
+	}
+}
+
+test_fail_rules_only_referenced_from_tests_when_test_references_ignored if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"idiomatic": {"unused-rule": {"ignore-test-references": true}}}
+
+	descriptions := {violation.description | some violation in r}
+
+	descriptions == {
+		"Rule data.main.unused is never referenced from any entrypoint",
+		"Rule data.lib.orphan is never referenced from any entrypoint",
+		"Rule data.lib.tested is never referenced from any entrypoint",
+	}
+}
+
+test_success_rules_referenced_from_configured_entrypoints if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"idiomatic": {"unused-rule": {"entrypoints": ["main.unused", "data.lib.orphan"]}}}
+
+	r == set()
+}
+
+test_success_no_entrypoints if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		"package lib\n\nunused := true\n",
+		"package other\n\nalso_unused := true\n",
+	])
+
+	r == set()
+}
+
+_policies := [
+	"package main\n\nimport data.lib\n\n# METADATA\n# entrypoint: true\nallow if lib.admin\n\nunused if true\n",
+	"package lib\n\nadmin if _helper\n\n_helper := true\n\norphan := 1\n\ntested := 2\n\nf(x) := x\n",
+	"package lib_test\n\nimport data.lib\n\ntest_tested if lib.tested\n",
+]
+
+_aggregates(policies) := {file: {"idiomatic/unused-rule": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
+
+_with_location(description, location) := {
+	"category": "idiomatic",
+	"description": description,
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": "https://www.openpolicyagent.org/projects/regal/rules/idiomatic/unused-rule",
+	}],
+	"title": "unused-rule",
+}
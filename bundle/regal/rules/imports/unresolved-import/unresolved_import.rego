@@ -27,6 +27,7 @@ aggregate_report contains violation if {
 	# for map generating / general ref head rules
 	not _wildcard_match(path, _except_imports)
 	not _is_resolved_ref(path, rule_tree.data)
+	not _in_data_files(path)
 
 	violation := result.fail(rego.metadata.chain(), aggregated.location_object(location, file))
 }
@@ -50,6 +51,11 @@ _is_resolved_ref(ref_path, rule_tree) if {
 	object.get(rule_tree, array.slice(ref_path, 0, i), false) == {} # regal ignore:superfluous-object-get
 }
 
+# imports of documents in data files, or of anything nested in their values
+_in_data_files(path) if object.get(aggregated.data_tree, array.flatten(["data", path]), false) != false
+
+_in_data_files(path) if _is_resolved_ref(path, object.get(aggregated.data_tree, "data", {}))
+
 _except_imports contains split(trim_prefix(str, "data."), ".") if {
 	some str in config.rules.imports["unresolved-import"]["except-imports"]
 }
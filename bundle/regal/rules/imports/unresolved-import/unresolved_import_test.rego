@@ -68,6 +68,23 @@ test_success_no_unresolved_imports if {
 	r == set()
 }
 
+test_success_imports_resolved_by_data_files if {
+	p1 := `package foo
+	import data.users
+	import data.roles.admin.permissions
+
+	x := 1
+	`
+	r := rule.aggregate_report
+		with input.aggregates_internal as _imports_agg("p1.rego", p1)
+		with input.data_files as {
+			"users/data.json": {"tree": {"data": {"users": {}}}},
+			"data.yaml": {"tree": {"data": {"roles": {"admin": {}}}}},
+		}
+
+	r == set()
+}
+
 test_success_unresolved_imports_are_excepted if {
 	p1 := `package foo
 	import data.bar.x
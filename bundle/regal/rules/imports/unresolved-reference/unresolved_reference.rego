@@ -30,8 +30,10 @@ aggregate_report contains violation if {
 
 	# a reference is considered resolved with respect to a rule if
 	# it indexes into a rule, or is the prefix of a rule, or the
-	# reference is ignored in the config
+	# reference is ignored in the config. the same goes for refs to
+	# documents in data files
 	not _is_resolved_ref(ref_path, rule_tree)
+	not _is_resolved_ref(ref_path, aggregated.data_tree)
 	not {
 		some exception in config.rules.imports["unresolved-reference"]["except-paths"]
 		glob.match(exception, [], ref_name)
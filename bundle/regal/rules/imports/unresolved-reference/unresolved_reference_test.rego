@@ -43,6 +43,22 @@ test_success_no_unresolved_reference if {
 	r == set()
 }
 
+test_success_reference_resolved_by_data_file if {
+	p1 := "package foo\n\nimport data.bar\nx := bar.baz.qux\n"
+	p2 := "package bar\n\nx := 1\n"
+
+	agg1 := rule.aggregate with input as regal.parse_module("p1.rego", p1)
+	agg2 := rule.aggregate with input as regal.parse_module("p2.rego", p2)
+
+	r := rule.aggregate_report
+		with input.aggregates_internal as util.with_source_files("imports/unresolved-reference", [agg1, agg2])
+		with input.aggregates_internal["p1.rego"].common as _lines(p1)
+		with input.aggregates_internal["p2.rego"].common as _lines(p2)
+		with input.data_files as {"bar/data.json": {"tree": {"data": {"bar": {"baz": {}}}}}}
+
+	r == set()
+}
+
 test_fail_identifies_unresolved_reference_with_alias if {
 	p1 := "package foo\n\nimport data.bar as baz\nx := baz.qux\n"
 	p2 := "package bar\n\nx := 1\n"
@@ -242,7 +258,6 @@ test_success_builtin_names_are_ignored if {
 	fun(foo) := time.now_ns
 	`)
 		with data.regal.ast.builtin_names as {"time.now_ns"}
-		with data.regal.ast.builtin_namespaces as {"time"}
 
 	r := rule.aggregate_report with input as {"aggregate": agg}
 
@@ -255,7 +270,6 @@ test_fail_builtin_namespaces_are_not_ignored if {
 	agg := rule.aggregate
 		with input as regal.parse_module("p1.rego", p1)
 		with data.regal.ast.builtin_names as {"time.now_ns"}
-		with data.regal.ast.builtin_namespaces as {"time"}
 
 	r := rule.aggregate_report
 		with input.aggregates_internal as util.with_source_files("imports/unresolved-reference", [agg])
@@ -1,7 +1,5 @@
 package regal.rules.imports["use-rego-v1_test"]
 
-import data.regal.capabilities
-import data.regal.config
 import data.regal.rules.imports["use-rego-v1"] as rule
 
 test_fail_missing_rego_v1_import if {
@@ -10,7 +8,6 @@ test_fail_missing_rego_v1_import if {
 
 	foo if not bar
 	`)
-		with config.capabilities as capabilities.provided
 
 	r == {{
 		"category": "imports",
@@ -40,6 +37,5 @@ test_success_rego_v1_import if {
 
 	foo if not bar
 	`)
-		with config.capabilities as capabilities.provided
 	r == set()
 }
@@ -0,0 +1,21 @@
+# METADATA
+# description: Comprehension can't be indexed
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/performance/comprehension-not-indexed
+package regal.rules.performance["comprehension-not-indexed"]
+
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+report contains violation if {
+	some comprehension
+	ast.found.comprehensions[_][comprehension]
+
+	loc := util.to_location_object(comprehension.location)
+
+	ast.index_outcomes.comprehensions[$"{loc.row}:{loc.col}"].indexed == false
+
+	violation := result.fail(rego.metadata.chain(), result.location(comprehension))
+}
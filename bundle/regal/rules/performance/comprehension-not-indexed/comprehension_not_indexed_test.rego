@@ -0,0 +1,52 @@
+package regal.rules.performance["comprehension-not-indexed_test"]
+
+import data.regal.ast
+
+import data.regal.rules.performance["comprehension-not-indexed"] as rule
+
+test_fail_comprehension_not_indexed if {
+	r := rule.report with input as ast.policy(`names contains name if {
+	some user in input.users
+	name := count([role | some role in input.roles; startswith(role, user)])
+}`)
+
+	r == {{
+		"category": "performance",
+		"description": "Comprehension can't be indexed",
+		"level": "error",
+		"location": {
+			"col": 16,
+			"end": {
+				"col": 73,
+				"row": 5,
+			},
+			"file": "policy.rego",
+			"row": 5,
+			"text": "\tname := count([role | some role in input.roles; startswith(role, user)])",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/performance/comprehension-not-indexed",
+		}],
+		"title": "comprehension-not-indexed",
+	}}
+}
+
+test_success_comprehension_indexed if {
+	r := rule.report with input as ast.policy(`names contains name if {
+	some user in input.users
+	roles := [role | some role in input.roles; role.user == user]
+	name := count(roles)
+}`)
+
+	r == set()
+}
+
+test_success_comprehension_not_in_loop if {
+	r := rule.report with input as ast.policy(`f(user) := count([role |
+	some role in input.roles
+	startswith(role, user)
+])`)
+
+	r == set()
+}
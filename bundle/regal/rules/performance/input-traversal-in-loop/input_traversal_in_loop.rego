@@ -0,0 +1,66 @@
+# METADATA
+# description: Expensive traversal of `input` inside loop
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/performance/input-traversal-in-loop
+package regal.rules.performance["input-traversal-in-loop"]
+
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+report contains violation if {
+	some rule_index, call
+	ast.function_calls[rule_index][call].name in {"walk", "json.filter"}
+
+	_input(call.args[0])
+
+	_in_loop(rule_index, util.to_location_object(call.location))
+
+	violation := result.fail(rego.metadata.chain(), result.location(call))
+}
+
+_input(term) if {
+	term.type == "var"
+	term.value == "input"
+}
+
+# only static refs, as a ref like input.rules[i] likely traverses
+# a different part of the input for each iteration
+_input(term) if {
+	term.type == "ref"
+	term.value[0].value == "input"
+
+	ast.static_ref(term)
+}
+
+# call is in any body expression following an iteration in that same body,
+# which covers both rule bodies and the bodies of comprehensions
+_in_loop(rule_index, loc) if {
+	walk(input.rules[rule_index], [_, body])
+
+	is_array(body)
+
+	some i, expr in body
+	_iteration(expr.terms)
+
+	some later in array.slice(body, i + 1, count(body))
+
+	util.contains_location(util.to_location_object(later.location), loc)
+}
+
+# call is in the body of an `every` construct
+_in_loop(rule_index, loc) if {
+	expr := ast.found.every[rule_index][_].body[_]
+
+	util.contains_location(util.to_location_object(expr.location), loc)
+}
+
+# some x in coll
+_iteration(terms) if terms.symbols[0].type == "call"
+
+# walk(x, [path, value])
+_iteration(terms) if {
+	terms[0].value[0].value == "walk"
+	count(terms) == 3
+}
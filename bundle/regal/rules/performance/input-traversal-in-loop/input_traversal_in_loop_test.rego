@@ -0,0 +1,103 @@
+package regal.rules.performance["input-traversal-in-loop_test"]
+
+import data.regal.ast
+
+import data.regal.rules.performance["input-traversal-in-loop"] as rule
+
+test_fail_walk_input_in_loop if {
+	r := rule.report with input as ast.policy(`deny contains name if {
+	some name in data.forbidden
+	walk(input, [_, value])
+	value == name
+}`)
+
+	r == {with_location({
+		"col": 2,
+		"end": {
+			"col": 6,
+			"row": 5,
+		},
+		"file": "policy.rego",
+		"row": 5,
+		"text": "\twalk(input, [_, value])",
+	})}
+}
+
+test_fail_json_filter_input_in_comprehension_loop if {
+	r := rule.report with input as ast.policy(`filtered := [f |
+	some path in data.paths
+	f := json.filter(input.resource, [path])
+]`)
+
+	r == {with_location({
+		"col": 7,
+		"end": {
+			"col": 18,
+			"row": 5,
+		},
+		"file": "policy.rego",
+		"row": 5,
+		"text": "\tf := json.filter(input.resource, [path])",
+	})}
+}
+
+test_fail_walk_input_in_every if {
+	r := rule.report with input as ast.policy(`allow if {
+	every name in data.forbidden {
+		not _found(name)
+	}
+}
+
+_found(name) if {
+	walk(input, [_, name])
+}
+
+deny if {
+	every name in data.forbidden {
+		walk(input.resource, [_, value])
+		value != name
+	}
+}`)
+
+	r == {with_location({
+		"col": 3,
+		"end": {
+			"col": 7,
+			"row": 15,
+		},
+		"file": "policy.rego",
+		"row": 15,
+		"text": "\t\twalk(input.resource, [_, value])",
+	})}
+}
+
+test_success_walk_input_not_in_loop if {
+	r := rule.report with input as ast.policy(`values contains value if {
+	walk(input, [_, value])
+	value.type == "secret"
+}`)
+
+	r == set()
+}
+
+test_success_walk_different_part_of_input_in_loop if {
+	r := rule.report with input as ast.policy(`values contains value if {
+	some resource in input.resources
+	walk(resource, [_, value])
+	value.type == "secret"
+}`)
+
+	r == set()
+}
+
+with_location(location) := {
+	"category": "performance",
+	"description": "Expensive traversal of `input` inside loop",
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": "https://www.openpolicyagent.org/projects/regal/rules/performance/input-traversal-in-loop",
+	}],
+	"title": "input-traversal-in-loop",
+}
@@ -0,0 +1,79 @@
+# METADATA
+# description: Prefer lookup over iteration
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/performance/lookup-over-iteration
+package regal.rules.performance["lookup-over-iteration"]
+
+import data.regal.ast
+import data.regal.result
+
+# some x in coll
+# x == y
+report contains violation if {
+	some rule_index, i
+	expr := input.rules[rule_index].body[i]
+
+	[call] := expr.terms.symbols
+	call.value[0].value[1].value in {"member_2", "member_3"}
+
+	# the key in `some k, v in coll`, or the value in `some x in coll`
+	var := call.value[1]
+	var.type == "var"
+	not ast.is_wildcard(var)
+
+	# the value var must not be used if the key is what's compared
+	_unused_value(rule_index, call.value)
+
+	some later in array.slice(input.rules[rule_index].body, i + 1, count(input.rules[rule_index].body))
+
+	_compared(input.rules[rule_index], later, var.value)
+	_occurrences(input.rules[rule_index], var.value) == 2
+
+	violation := result.fail(rego.metadata.chain(), result.location(expr))
+}
+
+_unused_value(_, terms) if count(terms) == 3
+
+_unused_value(rule_index, terms) if {
+	count(terms) == 4
+	value := terms[2]
+
+	value.type == "var"
+	_occurrences(input.rules[rule_index], value.value) == 1
+}
+
+_compared(rule, expr, name) if {
+	not expr.negated
+	not expr.with
+
+	expr.terms[0].value[0].value in {"eq", "equal"}
+	count(expr.terms) == 3
+
+	some i in [1, 2]
+	expr.terms[i].type == "var"
+	expr.terms[i].value == name
+
+	_bound(rule, expr.terms[3 - i])
+}
+
+# vars on the other side of the comparison must be bound before it, or else
+# it's not a comparison but an assignment
+_bound(_, term) if term.type != "var"
+
+_bound(_, term) if {
+	term.type == "var"
+	term.value in ast.identifiers
+}
+
+_bound(rule, term) if {
+	term.type == "var"
+	not ast.is_wildcard(term)
+	ast.is_in_local_scope(rule, term.location, term.value)
+}
+
+_occurrences(rule, name) := count([1 |
+	walk(rule, [_, node])
+	node.type == "var"
+	node.value == name
+])
@@ -0,0 +1,82 @@
+package regal.rules.performance["lookup-over-iteration_test"]
+
+import data.regal.ast
+
+import data.regal.rules.performance["lookup-over-iteration"] as rule
+
+test_fail_iteration_for_membership if {
+	r := rule.report with input as ast.policy(`allow if {
+	some role in input.user.roles
+	role == data.admin_role
+}`)
+
+	r == {with_location({
+		"col": 2,
+		"end": {
+			"col": 31,
+			"row": 4,
+		},
+		"file": "policy.rego",
+		"row": 4,
+		"text": "\tsome role in input.user.roles",
+	})}
+}
+
+test_fail_nested_iteration_for_key_lookup if {
+	r := rule.report with input as ast.policy(`admins contains user.name if {
+	some user in input.users
+	some id, _ in data.admins
+	id == user.id
+}`)
+
+	r == {with_location({
+		"col": 2,
+		"end": {
+			"col": 27,
+			"row": 5,
+		},
+		"file": "policy.rego",
+		"row": 5,
+		"text": "\tsome id, _ in data.admins",
+	})}
+}
+
+test_success_iteration_var_used_elsewhere if {
+	r := rule.report with input as ast.policy(`roles contains role if {
+	some role in input.user.roles
+	role == data.admin_role
+}`)
+
+	r == set()
+}
+
+test_success_value_used_in_key_lookup if {
+	r := rule.report with input as ast.policy(`admins contains admin.name if {
+	some user in input.users
+	some id, admin in data.admins
+	id == user.id
+}`)
+
+	r == set()
+}
+
+test_success_unification_not_comparison if {
+	r := rule.report with input as ast.policy(`allow if {
+	some role in input.user.roles
+	role = x
+}`)
+
+	r == set()
+}
+
+with_location(location) := {
+	"category": "performance",
+	"description": "Prefer lookup over iteration",
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": "https://www.openpolicyagent.org/projects/regal/rules/performance/lookup-over-iteration",
+	}],
+	"title": "lookup-over-iteration",
+}
@@ -0,0 +1,90 @@
+# METADATA
+# description: Rule can't be indexed
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/performance/rule-not-indexed
+package regal.rules.performance["rule-not-indexed"]
+
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+report contains violation if {
+	some rule_index
+	expr := input.rules[rule_index].body[_]
+
+	not expr.negated
+	not expr.with
+
+	expr.terms[0].value[0].value in {"eq", "equal"}
+	count(expr.terms) == 3
+
+	_defeats_indexing(expr.terms[1], expr.terms[2])
+
+	rule := input.rules[rule_index]
+	loc := util.to_location_object(rule.location)
+	outcome := ast.index_outcomes.rules[$"{loc.row}:{loc.col}"]
+
+	outcome.indexed == false
+
+	# when no definition is indexed, like for rules iterating over input to collect things,
+	# there's nothing the index could skip, and no reason to single out this definition
+	outcome.indexed_definitions > 0
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.location(expr),
+		{"description": _message(ast.rule_names_ordered[rule_index], outcome)},
+	))
+}
+
+_message(name, outcome) := concat("", [
+	$"Comparison prevents rule {name} from being indexed ",
+	$"({outcome.indexed_definitions} of {outcome.definitions} definitions indexed)",
+])
+
+# input.x == lower(data.y), input.x == data.y
+_defeats_indexing(lhs, rhs) if {
+	_input_ref(lhs)
+	_non_constant(rhs)
+}
+
+_defeats_indexing(lhs, rhs) if {
+	_input_ref(rhs)
+	_non_constant(lhs)
+}
+
+# input.users[i].name == "admin"
+_defeats_indexing(lhs, rhs) if {
+	_non_ground_input_ref(lhs)
+	rhs.type in ast.scalar_types
+}
+
+_defeats_indexing(lhs, rhs) if {
+	_non_ground_input_ref(rhs)
+	lhs.type in ast.scalar_types
+}
+
+# values computed from input, like count(input.x), differ between inputs, and could never
+# be replaced by a constant value to make the comparison indexable
+_non_constant(term) if {
+	term.type in {"call", "ref", "object", "set", "arraycomprehension", "setcomprehension", "objectcomprehension"}
+
+	not _references_input(term)
+}
+
+_references_input(term) if {
+	some value
+	walk(term, [_, value])
+
+	_input_ref(value)
+}
+
+_input_ref(term) if {
+	term.type == "ref"
+	term.value[0].value == "input"
+}
+
+_non_ground_input_ref(term) if {
+	_input_ref(term)
+	not ast.static_ref(term)
+}
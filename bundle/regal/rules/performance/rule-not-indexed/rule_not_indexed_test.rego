@@ -0,0 +1,128 @@
+package regal.rules.performance["rule-not-indexed_test"]
+
+import data.regal.ast
+
+import data.regal.rules.performance["rule-not-indexed"] as rule
+
+test_fail_ref_compared_to_call if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == "GET"
+}
+
+allow if {
+	input.method == upper(data.config.method)
+}`)
+
+	r == {with_location(
+		{
+			"col": 2,
+			"end": {
+				"col": 43,
+				"row": 8,
+			},
+			"file": "policy.rego",
+			"row": 8,
+			"text": "\tinput.method == upper(data.config.method)",
+		},
+		"Comparison prevents rule allow from being indexed (1 of 2 definitions indexed)",
+	)}
+}
+
+test_fail_non_ground_ref_compared_to_constant if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == "GET"
+}
+
+allow if {
+	some i
+	input.users[i].name == "admin"
+}`)
+
+	r == {with_location(
+		{
+			"col": 2,
+			"end": {
+				"col": 32,
+				"row": 9,
+			},
+			"file": "policy.rego",
+			"row": 9,
+			"text": "\tinput.users[i].name == \"admin\"",
+		},
+		"Comparison prevents rule allow from being indexed (1 of 2 definitions indexed)",
+	)}
+}
+
+test_success_all_definitions_indexed if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == "GET"
+}
+
+allow if {
+	input.method == "POST"
+	input.user == lower(input.owner)
+}`)
+
+	r == set()
+}
+
+test_success_single_definition if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == upper(input.verb)
+}`)
+
+	r == set()
+}
+
+test_success_compared_to_value_from_input if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == "GET"
+}
+
+allow if {
+	input.parse_errors != []
+	count(input.lines) == input.parsed_lines
+}`)
+
+	r == set()
+}
+
+test_success_no_definition_indexed if {
+	r := rule.report with input as ast.policy(`report contains name if {
+	some i
+	input.rules[i].head.value.type == "ref"
+	name := input.rules[i].head.name
+}
+
+report contains name if {
+	some i
+	input.rules[i].head.value.type == "var"
+	name := input.rules[i].head.value.value
+}`)
+
+	r == set()
+}
+
+test_success_wildcard_ref_indexed if {
+	r := rule.report with input as ast.policy(`allow if {
+	input.method == "GET"
+}
+
+allow if {
+	input.roles[_] == "admin"
+}`)
+
+	r == set()
+}
+
+with_location(location, description) := {
+	"category": "performance",
+	"description": description,
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": "https://www.openpolicyagent.org/projects/regal/rules/performance/rule-not-indexed",
+	}],
+	"title": "rule-not-indexed",
+}
@@ -0,0 +1,26 @@
+# METADATA
+# description: Default rule grants access
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/default-allow
+package regal.rules.security["default-allow"]
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+
+report contains violation if {
+	some i, rule in input.rules
+	rule.default
+
+	name := ast.rule_names_ordered[i]
+	rule.head.value.type == "boolean"
+
+	_permissive(name, rule.head.value.value)
+
+	violation := result.fail(rego.metadata.chain(), result.ranged_location_between(rule, rule.head.value))
+}
+
+_permissive(name, true) if name in config.rules.security["default-allow"]["allow-rule-names"]
+
+_permissive(name, false) if name in config.rules.security["default-allow"]["deny-rule-names"]
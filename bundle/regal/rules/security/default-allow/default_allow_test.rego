@@ -0,0 +1,69 @@
+package regal.rules.security["default-allow_test"]
+
+import data.regal.ast
+import data.regal.config
+
+import data.regal.rules.security["default-allow"] as rule
+
+test_fail_default_allow_true if {
+	r := rule.report
+		with input as ast.policy("default allow := true")
+		with config.rules as _config
+
+	r == {{
+		"category": "security",
+		"description": "Default rule grants access",
+		"level": "error",
+		"location": {
+			"col": 1,
+			"end": {
+				"col": 22,
+				"row": 3,
+			},
+			"file": "policy.rego",
+			"row": 3,
+			"text": "default allow := true",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/security/default-allow",
+		}],
+		"title": "default-allow",
+	}}
+}
+
+test_fail_default_deny_false if {
+	r := rule.report
+		with input as ast.policy("default deny := false")
+		with config.rules as _config
+
+	count(r) == 1
+}
+
+test_success_default_deny_by_default[policy] if {
+	some policy in [
+		"default allow := false",
+		"default deny := true",
+		"default enabled := true",
+		"default allow := {}",
+	]
+
+	r := rule.report
+		with input as ast.policy(policy)
+		with config.rules as _config
+
+	r == set()
+}
+
+test_success_configured_rule_names if {
+	r := rule.report
+		with input as ast.policy("default allow := true\n\ndefault authorized := true")
+		with config.rules as {"security": {"default-allow": {
+			"allow-rule-names": ["authorized"],
+			"deny-rule-names": [],
+		}}}
+
+	{violation.location.text | some violation in r} == {"default authorized := true"}
+}
+
+_config := {"security": {"default-allow": {"allow-rule-names": ["allow"], "deny-rule-names": ["deny"]}}}
@@ -0,0 +1,136 @@
+# METADATA
+# description: Hard-coded secret
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/hardcoded-secret
+package regal.rules.security["hardcoded-secret"]
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+
+# METADATA
+# description: rules, variables and attributes with names like `password` assigned a string literal
+report contains violation if {
+	some [name, value] in _named_secrets
+
+	violation := result.fail(rego.metadata.chain(), result.ranged_location_between(name, value))
+}
+
+# METADATA
+# description: |
+#   string literals assigned or compared matching the format of well-known secrets, like tokens
+#   and private keys
+report contains violation if {
+	some term in _terms.string
+
+	regex.match(_secret_value_pattern, term.value)
+
+	not term.location in _named_secret_locations
+
+	violation := result.fail(rego.metadata.chain(), result.location(term))
+}
+
+# all patterns combined, as matching a single pattern against each string is much cheaper
+_secret_value_pattern := concat("|", [$"(?:{pattern})" | some pattern in [
+	`^(AKIA|ASIA)[0-9A-Z]{16}$`, # AWS access key ID
+	`^gh[pousr]_[A-Za-z0-9]{36,}$`, # GitHub token
+	`^xox[abposr]-[A-Za-z0-9-]{10,}$`, # Slack token
+	`^AIza[0-9A-Za-z_-]{35}$`, # Google API key
+	`^eyJ[A-Za-z0-9_-]+\.eyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+$`, # JWT
+	`-----BEGIN ([A-Z]+ )?PRIVATE KEY-----`, # PEM encoded private key
+]])
+
+# string and object terms of values assigned or compared, collected in a single walk of those values,
+# which is skipped altogether for the vast majority of files, where no line could possibly contain a secret
+_terms[term.type] contains term if {
+	_may_contain_secrets
+
+	some value in _assigned_or_compared
+
+	walk(value, [_, term])
+
+	term.type in {"string", "object"}
+}
+
+# values of rules and functions, like `headers := {"X-Api-Key": "abc"}`
+_assigned_or_compared contains rule.head.value if some rule in input.rules
+
+# both sides of assignments and comparisons, like `token := "abc"` or `input.token == "abc"`
+_assigned_or_compared contains term if {
+	call := ast.found.calls[_][_]
+
+	call[0].value[0].value in {"assign", "eq", "equal", "neq"}
+
+	some term in array.slice(call, 1, 3)
+}
+
+# true if the file contains either the start of a well-known secret format (keep in sync with the
+# patterns above), or a quoted object key matching the configured secret name pattern, as needed for
+# object attributes like `{"password": "secret"}`. the file is searched as a whole, as matching each
+# line separately is much more expensive
+_may_contain_secrets if {
+	regex.match(`AKIA|ASIA|gh[pousr]_|xox[abposr]-|AIza|eyJ|-----BEGIN`, _text)
+} else if {
+	some key in regex.find_n(`"[^"\n]+"\s*:|\x60[^\x60]+\x60\s*:`, _text, -1)
+
+	_secret_name(trim_right(substring(key, 1, -1), "\"`: \t"))
+}
+
+# true if the file contains a name matching the configured secret name pattern followed by
+# an assignment of a string, like `password := "secret"`
+_may_contain_named_secrets if {
+	some [_, name] in regex.find_all_string_submatch_n(`([A-Za-z_][A-Za-z0-9_]*)\s*:?=\s*["\x60]`, _text, -1)
+
+	_secret_name(name)
+}
+
+_text := concat("\n", input.regal.file.lines)
+
+# rules, like `password := "secret"`
+_named_secrets contains [regal.last(rule.head.ref), rule.head.value] if {
+	_may_contain_named_secrets
+
+	some rule in ast.rules
+
+	_secret_value(rule.head.value)
+	_secret_name(regal.last(rule.head.ref).value)
+}
+
+# variables, like `password := "secret"` in a rule body
+_named_secrets contains [call[1], call[2]] if {
+	_may_contain_named_secrets
+
+	call := ast.found.calls[_][_]
+
+	call[0].value[0].value in {"assign", "eq"}
+	call[1].type == "var"
+
+	_secret_value(call[2])
+	_secret_name(call[1].value)
+}
+
+# object attributes, like `{"password": "secret"}`
+_named_secrets contains [key, value] if {
+	some term in _terms.object
+	some [key, value] in term.value
+
+	key.type == "string"
+
+	_secret_value(value)
+	_secret_name(key.value)
+}
+
+_named_secret_locations contains value.location if some [_, value] in _named_secrets
+
+_secret_name(name) if regex.match(config.rules.security["hardcoded-secret"]["secret-name-pattern"], name)
+
+# empty strings and strings containing whitespace are unlikely to be secrets, and
+# much more likely to be e.g. names of headers or environment variables. checked
+# before the name, as it's much cheaper to rule out most values than most names
+_secret_value(term) if {
+	term.type == "string"
+	term.value != ""
+
+	not regex.match(`\s`, term.value)
+}
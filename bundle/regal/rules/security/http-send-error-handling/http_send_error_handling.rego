@@ -0,0 +1,56 @@
+# METADATA
+# description: '`http.send` without explicit error handling'
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/http-send-error-handling
+package regal.rules.security["http-send-error-handling"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	some rule_index
+	call := ast.function_calls[rule_index][_]
+
+	call.name == "http.send"
+	call.args[0].type == "object"
+
+	every pair in call.args[0].value {
+		pair[0].value != "raise_error"
+	}
+
+	not _status_code_checked(rule_index, call.location)
+
+	violation := result.fail(rego.metadata.chain(), result.location(call))
+}
+
+# response assigned to a variable, like `response := http.send(...)`, with its status code
+# checked in the same rule
+_status_code_checked(rule_index, location) if {
+	some expr in ast.found.calls[rule_index]
+
+	expr[0].value[0].value in {"assign", "eq"}
+	expr[1].type == "var"
+	expr[2].type == "call"
+	expr[2].value[0].location == location
+
+	_status_code_ref(ast.found.refs[rule_index][_].value, expr[1].value)
+}
+
+# response assigned to a rule, like `response := http.send(...)`, with its status code
+# checked in any rule of the module
+_status_code_checked(rule_index, location) if {
+	head := input.rules[rule_index].head
+
+	count(head.ref) == 1
+	head.value.type == "call"
+	head.value.value[0].location == location
+
+	_status_code_ref(ast.found.refs[_][_].value, head.ref[0].value)
+}
+
+_status_code_ref(ref, name) if {
+	ref[0].type == "var"
+	ref[0].value == name
+	ref[1].value == "status_code"
+}
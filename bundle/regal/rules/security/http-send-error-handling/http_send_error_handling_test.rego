@@ -0,0 +1,68 @@
+package regal.rules.security["http-send-error-handling_test"]
+
+import data.regal.ast
+
+import data.regal.rules.security["http-send-error-handling"] as rule
+
+test_fail_http_send_without_raise_error if {
+	r := rule.report with input as ast.policy(`r := http.send({"method": "GET", "url": input.url})`)
+
+	r == {{
+		"category": "security",
+		"description": "`http.send` without explicit error handling",
+		"level": "error",
+		"location": {
+			"col": 6,
+			"end": {
+				"col": 15,
+				"row": 3,
+			},
+			"file": "policy.rego",
+			"row": 3,
+			"text": `r := http.send({"method": "GET", "url": input.url})`,
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/security/http-send-error-handling",
+		}],
+		"title": "http-send-error-handling",
+	}}
+}
+
+test_fail_http_send_with_status_code_of_other_response_checked if {
+	policy := concat("\n", [
+		"r if {",
+		"\tresponse := http.send({\"method\": \"GET\", \"url\": input.url})",
+		"\tother := http.send({\"method\": \"GET\", \"url\": input.url, \"raise_error\": false})",
+		"\tother.status_code == 200",
+		"\tresponse.body.allow",
+		"}",
+	])
+
+	r := rule.report with input as ast.policy(policy)
+
+	count(r) == 1
+}
+
+test_success_http_send_with_raise_error[policy] if {
+	some policy in [
+		`r := http.send({"method": "GET", "url": input.url, "raise_error": false})`,
+		`r := http.send({"method": "GET", "url": input.url, "raise_error": true})`,
+		`r := http.send(input.request)`,
+	]
+
+	r := rule.report with input as ast.policy(policy)
+
+	r == set()
+}
+
+test_success_http_send_with_status_code_checked[policy] if {
+	some policy in [
+		"r if {\n\tresponse := http.send({\"method\": \"GET\", \"url\": input.url})\n\tresponse.status_code == 200\n}",
+		"response := http.send({\"method\": \"GET\", \"url\": input.url})\n\nr if response.status_code == 200",
+	]
+
+	r := rule.report with input as ast.policy(policy)
+
+	r == set()
+}
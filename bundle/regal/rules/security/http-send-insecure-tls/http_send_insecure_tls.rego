@@ -0,0 +1,23 @@
+# METADATA
+# description: TLS verification disabled in `http.send`
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/http-send-insecure-tls
+package regal.rules.security["http-send-insecure-tls"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	call := ast.function_calls[_][_]
+
+	call.name == "http.send"
+	call.args[0].type == "object"
+
+	some [key, value] in call.args[0].value
+
+	key.value == "tls_insecure_skip_verify"
+	value.value == true
+
+	violation := result.fail(rego.metadata.chain(), result.ranged_location_between(key, value))
+}
@@ -0,0 +1,41 @@
+package regal.rules.security["http-send-insecure-tls_test"]
+
+import data.regal.ast
+
+import data.regal.rules.security["http-send-insecure-tls"] as rule
+
+test_fail_tls_insecure_skip_verify if {
+	r := rule.report with input as ast.policy(`r := http.send({"url": input.url, "tls_insecure_skip_verify": true})`)
+
+	r == {{
+		"category": "security",
+		"description": "TLS verification disabled in `http.send`",
+		"level": "error",
+		"location": {
+			"col": 35,
+			"end": {
+				"col": 67,
+				"row": 3,
+			},
+			"file": "policy.rego",
+			"row": 3,
+			"text": `r := http.send({"url": input.url, "tls_insecure_skip_verify": true})`,
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/security/http-send-insecure-tls",
+		}],
+		"title": "http-send-insecure-tls",
+	}}
+}
+
+test_success_tls_verification_enabled[policy] if {
+	some policy in [
+		`r := http.send({"url": input.url})`,
+		`r := http.send({"url": input.url, "tls_insecure_skip_verify": false})`,
+	]
+
+	r := rule.report with input as ast.policy(policy)
+
+	r == set()
+}
@@ -0,0 +1,31 @@
+# METADATA
+# description: JWT decoded without verification
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/jwt-decode-without-verify
+package regal.rules.security["jwt-decode-without-verify"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	call := ast.function_calls[_][_]
+
+	call.name == "io.jwt.decode"
+
+	not _verified
+
+	violation := result.fail(rego.metadata.chain(), result.location(call))
+}
+
+# io.jwt.decode_verify both verifies and decodes, and any of the io.jwt.verify_* functions
+# is assumed to be used to verify the token decoded elsewhere in the same module
+_verified if {
+	call := ast.function_calls[_][_]
+
+	_verifying_function(call.name)
+}
+
+_verifying_function("io.jwt.decode_verify")
+
+_verifying_function(name) if startswith(name, "io.jwt.verify_")
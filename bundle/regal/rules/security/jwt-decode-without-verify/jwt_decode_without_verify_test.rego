@@ -0,0 +1,42 @@
+package regal.rules.security["jwt-decode-without-verify_test"]
+
+import data.regal.ast
+
+import data.regal.rules.security["jwt-decode-without-verify"] as rule
+
+test_fail_jwt_decode_without_verify if {
+	r := rule.report with input as ast.policy(`claims := io.jwt.decode(input.token)[1]`)
+
+	r == {{
+		"category": "security",
+		"description": "JWT decoded without verification",
+		"level": "error",
+		"location": {
+			"col": 11,
+			"end": {
+				"col": 24,
+				"row": 3,
+			},
+			"file": "policy.rego",
+			"row": 3,
+			"text": `claims := io.jwt.decode(input.token)[1]`,
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/security/jwt-decode-without-verify",
+		}],
+		"title": "jwt-decode-without-verify",
+	}}
+}
+
+test_success_jwt_decode_with_verify[policy] if {
+	some policy in [
+		"claims := io.jwt.decode(input.token)[1] if io.jwt.verify_rs256(input.token, data.cert)",
+		"claims := io.jwt.decode(input.token)[1]\n\nvalid if io.jwt.verify_es256(input.token, data.cert)",
+		"claims := io.jwt.decode_verify(input.token, {\"cert\": data.cert})[2]",
+	]
+
+	r := rule.report with input as ast.policy(policy)
+
+	r == set()
+}
@@ -0,0 +1,53 @@
+# METADATA
+# description: Environment variables read from `opa.runtime()`
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/security/opa-runtime-env
+package regal.rules.security["opa-runtime-env"]
+
+import data.regal.ast
+import data.regal.result
+
+# METADATA
+# description: direct reference to environment variables, like `opa.runtime().env.SECRET`
+report contains violation if {
+	ref := ast.found.refs[_][_]
+
+	ref.value[0].type == "call"
+	_runtime_call(ref.value[0].value[0].value)
+	ref.value[1].value == "env"
+
+	violation := result.fail(rego.metadata.chain(), result.location(ref))
+}
+
+# METADATA
+# description: reference to environment variables via a variable assigned the result of `opa.runtime()`
+report contains violation if {
+	some rule_index, calls in ast.found.calls
+	some assignment in calls
+
+	assignment[0].value[0].value in {"assign", "eq"}
+	assignment[1].type == "var"
+	assignment[2].type == "call"
+	_runtime_call(assignment[2].value[0].value)
+
+	some ref in ast.found.refs[rule_index]
+	ref.value[0].type == "var"
+	ref.value[0].value == assignment[1].value
+	ref.value[1].value == "env"
+
+	violation := result.fail(rego.metadata.chain(), result.location(ref))
+}
+
+# METADATA
+# description: rules returning the whole runtime object, including all environment variables
+report contains violation if {
+	some rule in ast.rules
+
+	rule.head.value.type == "call"
+	_runtime_call(rule.head.value.value[0].value)
+
+	violation := result.fail(rego.metadata.chain(), result.location(rule.head.value))
+}
+
+_runtime_call(ref) if ast.ref_to_string(ref) == "opa.runtime"
@@ -0,0 +1,53 @@
+package regal.rules.security["opa-runtime-env_test"]
+
+import data.regal.ast
+
+import data.regal.rules.security["opa-runtime-env"] as rule
+
+test_fail_opa_runtime_env_reference if {
+	r := rule.report with input as ast.policy(`secret := opa.runtime().env.SECRET`)
+
+	r == {{
+		"category": "security",
+		"description": "Environment variables read from `opa.runtime()`",
+		"level": "error",
+		"location": {
+			"col": 11,
+			"end": {
+				"col": 35,
+				"row": 3,
+			},
+			"file": "policy.rego",
+			"row": 3,
+			"text": `secret := opa.runtime().env.SECRET`,
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/security/opa-runtime-env",
+		}],
+		"title": "opa-runtime-env",
+	}}
+}
+
+test_fail_opa_runtime_env_reference_via_var if {
+	r := rule.report with input as ast.policy("secret := rt.env.SECRET if {\n\trt := opa.runtime()\n}")
+
+	{violation.location.text | some violation in r} == {"secret := rt.env.SECRET if {"}
+}
+
+test_fail_rule_returning_runtime if {
+	r := rule.report with input as ast.policy("runtime := opa.runtime()")
+
+	{violation.location.text | some violation in r} == {"runtime := opa.runtime()"}
+}
+
+test_success_opa_runtime_without_env[policy] if {
+	some policy in [
+		"version := opa.runtime().version",
+		"commit := rt.commit if {\n\trt := opa.runtime()\n}",
+	]
+
+	r := rule.report with input as ast.policy(policy)
+
+	r == set()
+}
@@ -0,0 +1,73 @@
+# METADATA
+# description: Rule or function too complex
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/style/rule-complexity
+package regal.rules.style["rule-complexity"]
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.ast
+import data.regal.complexity
+import data.regal.config
+import data.regal.result
+
+report contains violation if {
+	some name, metrics in complexity.metrics
+	some metric, option in _options
+
+	value := metrics[metric]
+	max_value := _cfg[option]
+
+	value > max_value
+
+	kind := {false: "Rule", true: "Function"}[metrics.function]
+	short_name := trim_prefix(name, $"{ast.package_name_full}.")
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.with_text(metrics.location),
+		{"description": $"{kind} {short_name} exceeds max {_labels[metric]} ({value} > {max_value})"},
+	))
+}
+
+# METADATA
+# description: collects references to and from rules, for determining their fan-in
+aggregate contains {"references": aggregators.references}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	max_value := _cfg["max-fan-in"]
+
+	some file, references in aggregated.references
+	some name, rule in references.rules
+
+	value := complexity.fan_in[name]
+	value > max_value
+
+	kind := {false: "Rule", true: "Function"}[rule.function]
+	short_name := trim_prefix(name, $"{references.package}.")
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		aggregated.location_object(rule.location, file),
+		{"description": $"{kind} {short_name} exceeds max {_labels.fan_in} ({value} > {max_value})"},
+	))
+}
+
+_options := {
+	"expressions": "max-expressions",
+	"nesting": "max-nesting-depth",
+	"fan_out": "max-fan-out",
+	"else": "max-else-branches",
+}
+
+_labels := {
+	"expressions": "number of expressions",
+	"nesting": "nesting depth",
+	"fan_out": "fan-out",
+	"else": "number of else branches",
+	"fan_in": "fan-in",
+}
+
+_cfg := config.rules.style["rule-complexity"]
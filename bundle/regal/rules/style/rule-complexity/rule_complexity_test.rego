@@ -0,0 +1,120 @@
+package regal.rules.style["rule-complexity_test"]
+
+import data.regal.capabilities
+import data.regal.config
+
+import data.regal.rules.style["rule-complexity"] as rule
+
+test_fail_nesting_depth_exceeded if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+f(x) := [y | some y in x; every z in y { z > 0 }]
+`)
+		with config.rules as _rules({"max-nesting-depth": 1})
+		with config.capabilities as capabilities.provided
+
+	r == {{
+		"category": "style",
+		"description": "Function f exceeds max nesting depth (2 > 1)",
+		"level": "error",
+		"location": {
+			"file": "p.rego",
+			"row": 3,
+			"col": 1,
+			"end": {"row": 3, "col": 2},
+			"text": "f(x) := [y | some y in x; every z in y { z > 0 }]",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/style/rule-complexity",
+		}],
+		"title": "rule-complexity",
+	}}
+}
+
+test_fail_expressions_fan_out_and_else_branches_exceeded if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+role := "admin" if {
+	input.user.admin
+	input.user.active
+} else := "user" if {
+	input.user.active
+} else := "none"
+`)
+		with config.rules as _rules({"max-expressions": 2, "max-fan-out": 1, "max-else-branches": 1})
+		with config.capabilities as capabilities.provided
+
+	{v.description | some v in r} == {
+		"Rule role exceeds max number of expressions (3 > 2)",
+		"Rule role exceeds max fan-out (2 > 1)",
+		"Rule role exceeds max number of else branches (2 > 1)",
+	}
+}
+
+test_success_within_thresholds if {
+	r := rule.report with input as regal.parse_module("p.rego", `package p
+
+allow if {
+	some role in input.user.roles
+	role == "admin"
+}
+`)
+		with config.rules as _rules({
+			"max-expressions": 2,
+			"max-nesting-depth": 0,
+			"max-fan-out": 1,
+			"max-else-branches": 0,
+		})
+		with config.capabilities as capabilities.provided
+
+	r == set()
+}
+
+test_fail_fan_in_exceeded if {
+	r := rule.aggregate_report with input as _aggregates({
+		"p.rego": "package p\n\nadmin if input.user.admin\n",
+		"q.rego": "package q\n\nimport data.p\n\nallow if p.admin\n\ndeny if not p.admin\n",
+	})
+		with config.rules as _rules({"max-fan-in": 1})
+
+	r == {{
+		"category": "style",
+		"description": "Rule admin exceeds max fan-in (2 > 1)",
+		"level": "error",
+		"location": {
+			"file": "p.rego",
+			"row": 3,
+			"col": 1,
+			"end": {"row": 3, "col": 6},
+			"text": "admin if input.user.admin",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/style/rule-complexity",
+		}],
+		"title": "rule-complexity",
+	}}
+}
+
+test_success_fan_in_within_threshold if {
+	r := rule.aggregate_report with input as _aggregates({
+		"p.rego": "package p\n\nadmin if input.user.admin\n",
+		"q.rego": "package q\n\nimport data.p\n\nallow if p.admin\n",
+	})
+		with config.rules as _rules({"max-fan-in": 1})
+
+	r == set()
+}
+
+_aggregates(policies) := {"aggregates_internal": {file: {
+	"style/rule-complexity": agg,
+	"common": {{"lines": split(policy, "\n")}},
+} |
+	some file, policy in policies
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}}
+
+_rules(options) := {"style": {"rule-complexity": object.union({"level": "error"}, options)}}
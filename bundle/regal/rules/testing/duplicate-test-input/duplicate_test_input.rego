@@ -0,0 +1,67 @@
+# METADATA
+# description: Identical input provided in multiple tests
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/testing/duplicate-test-input
+package regal.rules.testing["duplicate-test-input"]
+
+import data.regal.ast
+import data.regal.config
+import data.regal.result
+import data.regal.util
+
+report contains violation if {
+	some mocks in _mocks_by_value
+
+	count(mocks) >= _cfg["min-occurrences"]
+
+	rows := concat(", ", [format_int(mock.location.row, 10) | some mock in array.slice(mocks, 1, count(mocks))])
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		result.location(mocks[0].with),
+		{"description": $"Input repeated on lines {rows}, consider a shared fixture"},
+	))
+}
+
+_cfg := object.union({"min-occurrences": 3}, object.get(config.rules, ["testing", "duplicate-test-input"], {}))
+
+# all composite values mocking input in tests, in order of appearance, with whitespace and
+# trailing commas removed from their text to allow comparing values regardless of formatting
+_input_mocks := [{"with": w, "location": location, "value": _normalized(_text(location))} |
+	some i, rule in input.rules
+	rule in ast.tests
+
+	some expr in ast.found.expressions[i]
+	some w in expr.with
+
+	w.target.value[0].value == "input"
+	count(w.target.value) == 1
+	w.value.type in {"object", "array", "set"}
+	w.value.value != []
+
+	# values containing variables may differ between tests even when their text doesn't
+	not _contains_var(w.value)
+
+	location := util.to_location_object(w.value.location)
+]
+
+# the text of the value only, as location text includes the whole first line of multi-line values
+_text(location) := substring(location.text, location.col - 1, -1) if {
+	location.row != location.end.row
+} else := location.text
+
+# mocks grouped by value, in order of appearance
+_mocks_by_value[mock.value] := [other |
+	some other in _input_mocks
+	other.value == mock.value
+] if {
+	some mock in _input_mocks
+}
+
+_normalized(text) := regex.replace(regex.replace(text, `\s+`, ""), `,([}\]])`, "$1")
+
+_contains_var(value) if {
+	walk(value, [_, term])
+
+	term.type == "var"
+}
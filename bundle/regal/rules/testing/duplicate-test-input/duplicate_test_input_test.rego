@@ -0,0 +1,146 @@
+package regal.rules.testing["duplicate-test-input_test"]
+
+import data.regal.config
+import data.regal.rules.testing["duplicate-test-input"] as rule
+
+test_fail_identical_input_in_tests if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	data.p.allow with input as {"user": "alice", "roles": ["admin"]}
+}
+
+test_b if {
+	not data.p.deny with input as {"user": "alice", "roles": ["admin"]}
+}
+
+test_c if {
+	data.p.audit with input as {"user": "alice", "roles": ["admin"]}
+}`)
+
+	r == {{
+		"category": "testing",
+		"description": "Input repeated on lines 8, 12, consider a shared fixture",
+		"level": "error",
+		"location": {
+			"file": "p_test.rego",
+			"row": 4,
+			"col": 15,
+			"end": {"row": 4, "col": 66},
+			"text": "\tdata.p.allow with input as {\"user\": \"alice\", \"roles\": [\"admin\"]}",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/testing/duplicate-test-input",
+		}],
+		"title": "duplicate-test-input",
+	}}
+}
+
+test_fail_identical_input_formatted_differently if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	data.p.allow with input as {"user": "alice", "roles": ["admin"]}
+}
+
+test_b if {
+	data.p.allow with input as {
+		"user": "alice",
+		"roles": ["admin"],
+	}
+}
+
+test_c if {
+	data.p.allow with input as {"user": "alice","roles": ["admin"]}
+}`)
+
+	{v.description | some v in r} == {"Input repeated on lines 8, 15, consider a shared fixture"}
+}
+
+test_fail_reported_once_for_each_input if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	data.p.allow with input as ["a", "b"]
+	data.p.allow with input as ["a", "b"]
+	data.p.allow with input as ["a", "b"]
+	data.p.allow with input as ["a", "b"]
+}`)
+
+	{v.description | some v in r} == {"Input repeated on lines 5, 6, 7, consider a shared fixture"}
+	count(r) == 1
+}
+
+test_success_input_repeated_fewer_times_than_min_occurrences if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_allow if {
+	data.p.allow with input as {"user": "alice"}
+}
+
+test_not_deny if {
+	not data.p.deny with input as {"user": "alice"}
+}`)
+
+	r == set()
+}
+
+test_fail_input_repeated_min_occurrences_configured if {
+	r := rule.report
+		with input as regal.parse_module("p_test.rego", `package p_test
+
+test_allow if {
+	data.p.allow with input as {"user": "alice"}
+}
+
+test_not_deny if {
+	not data.p.deny with input as {"user": "alice"}
+}`)
+		with config.rules as {"testing": {"duplicate-test-input": {"min-occurrences": 2}}}
+
+	{v.description | some v in r} == {"Input repeated on lines 8, consider a shared fixture"}
+}
+
+test_success_different_inputs if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	data.p.allow with input as {"user": "alice"}
+}
+
+test_b if {
+	data.p.allow with input as {"user": "bob"}
+}`)
+
+	r == set()
+}
+
+test_success_empty_and_scalar_inputs_ignored if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	data.p.allow with input as {}
+	data.p.allow with input as {}
+	data.p.allow with input as "foo"
+	data.p.allow with input as "foo"
+}`)
+
+	r == set()
+}
+
+test_success_inputs_with_variables_ignored if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_a if {
+	x := 1
+	data.p.allow with input as {"x": x}
+}
+
+test_b if {
+	x := 2
+	data.p.allow with input as {"x": x}
+}`)
+
+	r == set()
+}
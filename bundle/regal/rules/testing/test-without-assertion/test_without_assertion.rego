@@ -0,0 +1,39 @@
+# METADATA
+# description: Test without assertions
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/testing/test-without-assertion
+package regal.rules.testing["test-without-assertion"]
+
+import data.regal.ast
+import data.regal.result
+
+report contains violation if {
+	some rule in ast.tests
+
+	every expr in rule.body {
+		_no_assertion(expr)
+	}
+
+	violation := result.fail(rego.metadata.chain(), result.location(rule.head))
+}
+
+# `x := y` only fails if y is undefined
+_no_assertion(expr) if {
+	not expr.negated
+	expr.terms[0].value[0].value == "assign"
+}
+
+# `some x` and `some x in xs`
+_no_assertion(expr) if expr.terms.symbols
+
+_no_assertion(expr) if {
+	not expr.negated
+	expr.terms[0].value[0].value == "print"
+}
+
+_no_assertion(expr) if {
+	not expr.negated
+	expr.terms.type == "boolean"
+	expr.terms.value == true
+}
@@ -0,0 +1,58 @@
+package regal.rules.testing["test-without-assertion_test"]
+
+import data.regal.rules.testing["test-without-assertion"] as rule
+
+test_fail_test_without_assertions if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_allow if {
+	some user in data.users
+	x := data.p.allow with input as {"user": user}
+	print(x)
+}`)
+
+	r == {{
+		"category": "testing",
+		"description": "Test without assertions",
+		"level": "error",
+		"location": {
+			"file": "p_test.rego",
+			"row": 3,
+			"col": 1,
+			"end": {"row": 3, "col": 11},
+			"text": "test_allow if {",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/testing/test-without-assertion",
+		}],
+		"title": "test-without-assertion",
+	}}
+}
+
+test_fail_test_with_constant_body if {
+	r := rule.report with input as regal.parse_module("p_test.rego", "package p_test\n\ntest_allow if true\n")
+
+	count(r) == 1
+}
+
+test_success_test_with_assertions if {
+	r := rule.report with input as regal.parse_module("p_test.rego", `package p_test
+
+test_allow if {
+	x := data.p.allow
+	x == true
+}
+
+test_deny if not data.p.deny
+
+test_lookup if data.p.allow with input as {}`)
+
+	r == set()
+}
+
+test_success_not_a_test if {
+	r := rule.report with input as regal.parse_module("p_test.rego", "package p_test\n\nx if true\n")
+
+	r == set()
+}
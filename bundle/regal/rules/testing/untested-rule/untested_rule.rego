@@ -0,0 +1,62 @@
+# METADATA
+# description: Rule or function not referenced by any test
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/testing/untested-rule
+package regal.rules.testing["untested-rule"]
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.config
+import data.regal.result
+
+# METADATA
+# description: collects the rules and functions declared in each module, and the refs found in them
+aggregate contains {"references": aggregators.references}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	# without any tests, there's no coverage to report on
+	_tests != set()
+
+	some names in _package_rules
+
+	untested := names - _tested
+	count(names - untested) / count(names) < _cfg["min-tested-ratio"]
+
+	some name in untested
+	[file, location] := min(_rule_locations[name])
+
+	violation := result.fail(rego.metadata.chain(), object.union(
+		aggregated.location_object(location, file),
+		{"description": $"Rule {name} is not referenced by any test"},
+	))
+}
+
+_cfg := object.union({"min-tested-ratio": 1}, object.get(config.rules, ["testing", "untested-rule"], {}))
+
+_package_rules[module.package] contains name if {
+	some module in aggregated.references
+	not module.test
+
+	some name, _ in module.rules
+}
+
+_rule_locations[name] contains [file, rule.location] if {
+	some file, module in aggregated.references
+	not module.test
+
+	some name, rule in module.rules
+}
+
+_tests contains name if {
+	some module in aggregated.references
+	module.test
+
+	some name, _ in module.rules
+	startswith(regal.last(split(name, ".")), "test_")
+}
+
+_tested := graph.reachable(aggregated.reference_graph, _tests)
@@ -0,0 +1,83 @@
+package regal.rules.testing["untested-rule_test"]
+
+import data.regal.config
+
+import data.regal.rules.testing["untested-rule"] as rule
+
+test_fail_rules_not_referenced_from_tests if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"testing": {"untested-rule": {"level": "error"}}}
+
+	r == {
+		_with_location("Rule data.lib.untested is not referenced by any test", {
+			"file": "p1.rego",
+			"row": 7,
+			"col": 1,
+			"end": {"row": 7, "col": 9},
+			"text": "untested := 2",
+		}),
+		_with_location("Rule data.other.allow is not referenced by any test", {
+			"file": "p2.rego",
+			"row": 3,
+			"col": 1,
+			"end": {"row": 3, "col": 6},
+			"text": "allow if true",
+		}),
+	}
+}
+
+test_success_rules_referenced_transitively_from_tests if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates([
+			"package lib\n\nallow if _helper\n\n_helper := true\n",
+			"package lib_test\n\nimport data.lib\n\ntest_allow if lib.allow\n",
+		])
+		with config.rules as {"testing": {"untested-rule": {"level": "error"}}}
+
+	r == set()
+}
+
+test_success_packages_above_min_tested_ratio if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(_policies)
+		with config.rules as {"testing": {"untested-rule": {"level": "error", "min-tested-ratio": 0.5}}}
+
+	{violation.description | some violation in r} == {
+		"Rule data.other.allow is not referenced by any test",
+	}
+}
+
+test_success_no_tests if {
+	r := rule.aggregate_report
+		with input.aggregates_internal as _aggregates(["package lib\n\nallow if true\n"])
+		with config.rules as {"testing": {"untested-rule": {"level": "error"}}}
+
+	r == set()
+}
+
+_policies := [
+	"package lib\n\nallow if _helper\n\n_helper := true\n\nuntested := 2\n",
+	"package other\n\nallow if true\n",
+	"package lib_test\n\nimport data.lib\n\ntest_allow if lib.allow\n",
+]
+
+_aggregates(policies) := {file: {"testing/untested-rule": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
+
+_with_location(description, location) := {
+	"category": "testing",
+	"description": description,
+	"level": "error",
+	"location": location,
+	"related_resources": [{
+		"description": "documentation",
+		"ref": "https://www.openpolicyagent.org/projects/regal/rules/testing/untested-rule",
+	}],
+	"title": "untested-rule",
+}
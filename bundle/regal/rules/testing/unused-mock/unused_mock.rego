@@ -0,0 +1,86 @@
+# METADATA
+# description: Mocked rule or function never reached in test
+# related_resources:
+#   - description: documentation
+#     ref: https://www.openpolicyagent.org/projects/regal/rules/testing/unused-mock
+package regal.rules.testing["unused-mock"]
+
+import data.regal.aggregated
+import data.regal.aggregators
+import data.regal.ast
+import data.regal.result
+import data.regal.util
+
+# METADATA
+# description: collects the rules and functions declared in each module, and the refs found in them
+aggregate contains {"references": aggregators.references}
+
+# METADATA
+# description: |
+#   collects the target of each `with` in tests that refers to data, along with the names of
+#   everything referenced in the expression evaluated with the mock
+aggregate contains {"mock": mock, "tested": tested, "location": util.to_location_object(mocked.location)} if {
+	some i, rule in input.rules
+	rule in ast.tests
+
+	some expr in ast.found.expressions[i]
+	some mocked in expr.with
+
+	mock := aggregators.expanded_name(mocked.target)
+	names := {name |
+		walk(expr.terms, [_, term])
+
+		name := aggregators.expanded_name(term)
+	}
+
+	# the head of a ref is visited too, but only the full ref is of interest
+	tested := {name |
+		some name in names
+		not _prefix_of_any(name, names)
+	}
+}
+
+# METADATA
+# schemas:
+#   - input: schema.regal.aggregate
+aggregate_report contains violation if {
+	some file, mocks in _unused_mocks
+	some mock, locations in mocks
+
+	first := sort([[loc.row, loc.col] | some loc in locations])[0]
+
+	some location in locations
+	[location.row, location.col] == first
+
+	violation := result.fail(rego.metadata.chain(), {
+		"location": object.union(location, {"file": file}),
+		"description": _description(mock, count(locations)),
+	})
+}
+
+# the same mock is commonly repeated in several tests of a file, but only reported once
+_unused_mocks[file][entry.mock] contains entry.location if {
+	some file, entries in input.aggregates_internal
+	some entry in entries["testing/unused-mock"]
+
+	mocked := aggregated.resolve_ref(entry.mock)
+	mocked != set()
+
+	# mocks of whole packages, rather than rules or functions, are left alone
+	some target in mocked
+	startswith($"{entry.mock}.", $"{target}.")
+
+	tested := {name | some ref in entry.tested; some name in aggregated.resolve_ref(ref)}
+	tested != set()
+
+	mocked & graph.reachable(aggregated.reference_graph, tested) == set()
+}
+
+_description(mock, 1) := $"Mock of {mock} is never reached from the tested expression"
+
+_description(mock, n) := $"Mock of {mock} is never reached from the tested expression ({n - 1} more in file)" if n > 1
+
+_prefix_of_any(name, names) if {
+	some other in names
+	startswith(other, $"{name}.")
+}
@@ -0,0 +1,78 @@
+package regal.rules.testing["unused-mock_test"]
+
+import data.regal.rules.testing["unused-mock"] as rule
+
+test_fail_mock_not_reachable_from_tested_rule if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		_policy,
+		"package p_test\n\nimport data.p\n\ntest_allow if p.allow with p.roles as {}\n",
+	])
+
+	r == {{
+		"category": "testing",
+		"description": "Mock of data.p.roles is never reached from the tested expression",
+		"level": "error",
+		"location": {
+			"file": "p2.rego",
+			"row": 5,
+			"col": 23,
+			"end": {"row": 5, "col": 41},
+			"text": "with p.roles as {}",
+		},
+		"related_resources": [{
+			"description": "documentation",
+			"ref": "https://www.openpolicyagent.org/projects/regal/rules/testing/unused-mock",
+		}],
+		"title": "unused-mock",
+	}}
+}
+
+test_fail_repeated_mock_reported_once if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		_policy,
+		"package p_test\n\nimport data.p\n\ntest_a if p.allow with p.roles as {}\n\ntest_b if p.allow with p.roles as {}\n",
+	])
+
+	{[v.location.row, v.description] | some v in r} == {[
+		5,
+		"Mock of data.p.roles is never reached from the tested expression (1 more in file)",
+	]}
+}
+
+test_success_mock_reachable_from_tested_rule if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		_policy,
+		"package p_test\n\nimport data.p\n\ntest_deny if p.deny with p.roles as {}\n",
+	])
+
+	r == set()
+}
+
+test_success_mock_of_unknown_data_and_input if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		_policy,
+		"package p_test\n\nimport data.p\n\ntest_allow if p.allow with data.users as [] with input as {}\n",
+	])
+
+	r == set()
+}
+
+test_success_mock_of_package if {
+	r := rule.aggregate_report with input.aggregates_internal as _aggregates([
+		_policy,
+		"package q\n\nx := 1\n",
+		"package p_test\n\nimport data.p\n\ntest_allow if p.allow with data.q as {}\n",
+	])
+
+	r == set()
+}
+
+_policy := "package p\n\nallow if input.admin\n\ndeny if _has_role\n\n_has_role if roles[_]\n\nroles := {\"a\"}\n"
+
+_aggregates(policies) := {file: {"testing/unused-mock": agg, "common": {{"lines": split(policy, "\n")}}} |
+	some i, policy in policies
+	file := $"p{i + 1}.rego"
+
+	# regal ignore:with-outside-test-context
+	agg := rule.aggregate with input as regal.parse_module(file, policy)
+}
@@ -3,6 +3,8 @@ package cmd
 const (
 	// formatJSON is the JSON format value for the --format flag in various commands.
 	formatJSON = "json"
+	// formatJSONLines is the JSON Lines format value for the --format flag in various commands.
+	formatJSONLines = "jsonl"
 	// formatPretty is the pretty format value for the --format flag in various commands.
 	formatPretty = "pretty"
 	// formatCompact is the compact format value for the --format flag in various commands.
@@ -13,6 +15,10 @@ const (
 	formatFestive = "festive"
 	// formatSarif is the SARIF format value for the --format flag in various commands.
 	formatSarif = "sarif"
+	// formatHTML is the HTML format value for the --format flag in various commands.
+	formatHTML = "html"
+	// formatMarkdown is the Markdown format value for the --format flag in various commands.
+	formatMarkdown = "markdown"
 	// formatJunit is the JUnit format value for the --format flag in various commands.
 	formatJunit = "junit"
 )
@@ -0,0 +1,167 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	outil "github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/open-policy-agent/regal/internal/diff"
+	"github.com/open-policy-agent/regal/internal/explorer"
+	"github.com/open-policy-agent/regal/pkg/roast/encoding"
+)
+
+type exploreParams struct {
+	format      *outil.EnumFlag
+	strict      bool
+	annotations bool
+	print       bool
+	diff        bool
+	plan        bool
+}
+
+type exploreStage struct {
+	Name    string `json:"name"`
+	Output  string `json:"output,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Changed bool   `json:"changed"`
+}
+
+type exploreOutput struct {
+	File   string         `json:"file"`
+	Stages []exploreStage `json:"stages"`
+	Plan   string         `json:"plan,omitempty"`
+}
+
+func init() {
+	params := &exploreParams{format: outil.NewEnumFlag(formatPretty, []string{formatPretty, formatJSON})}
+
+	exploreCommand := &cobra.Command{
+		Use:   "explore <path>",
+		Short: "Show how the compiler transforms a Rego module",
+		Long: `Print the module after each stage of the OPA compiler, and optionally the plan (IR) compiled from it.
+
+Rules are often rewritten by the compiler in ways that are not obvious from the source, and seeing the module after
+each stage helps to understand why a rule behaves the way it does, or why it fails to compile. Use --diff to show only
+what changed between consecutive stages, and --plan to include the plan, which requires at least one rule annotated
+as an entrypoint.
+
+Exits with code 1 if compilation fails, after printing the stages that preceded the failure.`,
+
+		Args: cobra.ExactArgs(1),
+
+		RunE: wrapProfiling(func(args []string) error {
+			failed, err := explore(context.Background(), os.Stdout, args[0], params)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return exit(1)
+			}
+
+			if failed {
+				return exit(1)
+			}
+
+			return nil
+		}),
+	}
+
+	flags := exploreCommand.Flags()
+
+	flags.VarP(params.format, "format", "f", "set output format (pretty, json)")
+	flags.BoolVar(&params.strict, "strict", false, "enable strict mode for the compiler")
+	flags.BoolVar(&params.annotations, "annotations", false, "use type checking annotations in the compiler")
+	flags.BoolVar(&params.print, "print", false, "enable print statements in the compiler")
+	flags.BoolVar(&params.diff, "diff", false, "show the diff from the previous stage rather than the full module")
+	flags.BoolVar(&params.plan, "plan", false, "include the plan compiled from the module")
+
+	addPprofFlag(flags)
+
+	RootCommand.AddCommand(exploreCommand)
+}
+
+// explore writes the module at path after each compiler stage to w, and reports whether compilation failed.
+func explore(ctx context.Context, w io.Writer, path string, params *exploreParams) (failed bool, err error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+
+	rego := string(bs)
+	output := exploreOutput{File: path}
+	previous := ""
+
+	for _, cs := range explorer.CompilerStages(path, rego, params.strict, params.annotations, params.print) {
+		if cs.Error != "" {
+			output.Stages = append(output.Stages, exploreStage{Name: string(cs.Stage), Error: cs.Error})
+			failed = true
+
+			continue
+		}
+
+		module := cs.FormattedResult()
+		if module == "" {
+			// modules rewritten by later stages can't always be formatted
+			module = cs.Result.String()
+		}
+
+		stage := exploreStage{Name: string(cs.Stage), Output: module, Changed: module != previous}
+		if params.diff && len(output.Stages) > 0 {
+			prev := output.Stages[len(output.Stages)-1].Name
+			stage.Diff = diff.Unified(prev, stage.Name, previous, module)
+		}
+
+		output.Stages = append(output.Stages, stage)
+		previous = module
+	}
+
+	if params.plan && !failed {
+		if output.Plan, err = explorer.Plan(ctx, path, rego, params.print); err != nil {
+			return false, fmt.Errorf("failed to plan module: %w", err)
+		}
+	}
+
+	if params.format.String() == formatJSON {
+		e := encoding.JSON().NewEncoder(w)
+		e.SetIndent("", "  ")
+
+		return failed, e.Encode(output)
+	}
+
+	return failed, writeExplorePretty(w, output, params.diff)
+}
+
+func writeExplorePretty(w io.Writer, output exploreOutput, showDiff bool) error {
+	var errs []error
+
+	write := func(format string, args ...any) {
+		if _, err := fmt.Fprintf(w, format, args...); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for i, stage := range output.Stages {
+		switch {
+		case stage.Error != "":
+			write("# %s\n\n%s\n\n", stage.Name, stage.Error)
+		case i > 0 && !stage.Changed:
+			write("# %s (unchanged)\n\n", stage.Name)
+		case showDiff && i > 0:
+			write("# %s\n\n%s\n", stage.Name, stage.Diff)
+		default:
+			write("# %s\n\n%s\n", stage.Name, stage.Output)
+		}
+	}
+
+	if output.Plan != "" {
+		write("# Plan\n\n%s", output.Plan)
+	}
+
+	return errors.Join(errs...)
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bufio"
 	"errors"
 	"fmt"
 	"io"
@@ -14,6 +15,10 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/open-policy-agent/opa/v1/ast"
+
+	"github.com/open-policy-agent/regal/internal/diff"
+	"github.com/open-policy-agent/regal/internal/git"
 	rio "github.com/open-policy-agent/regal/internal/io"
 	"github.com/open-policy-agent/regal/internal/util"
 	"github.com/open-policy-agent/regal/pkg/config"
@@ -30,7 +35,14 @@ type fixParams struct {
 	lintAndFixParams
 
 	conflictMode string
+	git          string
+	maxPasses    int
+	codemods     repeatedStringFlag
+	only         repeatedStringFlag
+	skip         repeatedStringFlag
 	dryRun       bool
+	diff         bool
+	interactive  bool
 	verbose      bool
 	force        bool
 }
@@ -57,8 +69,16 @@ as it is important to understand why they were flagged.`, "\n", " ")
 
 The linter rules with automatic fixes available are currently:
 - %s
+
+Use --codemod to instead apply the edits of a Rego policy to all files, for migrations like
+replacing calls to a deprecated function. See the documentation on codemods for details.
 `, intro, strings.Join(fixableRules, "\n- "))
 		}(),
+		Example: `  regal fix policies/
+  regal fix --dry-run --diff policies/
+  regal fix --interactive --only use-assignment-operator policies/
+  regal fix --git=commit policies/
+  regal fix --codemod migrations/has_key.rego --dry-run policies/`,
 		PreRunE: func(_ *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return errors.New("at least one file or directory must be provided for fixing")
@@ -86,7 +106,21 @@ The linter rules with automatic fixes available are currently:
 	fixCommand.Flags().BoolVarP(&params.verbose, "verbose", "", false, "show the full changes applied in the console")
 	fixCommand.Flags().BoolVarP(&params.force, "force", "", false,
 		"allow fixing of files that have uncommitted changes in git or when git is not being used")
-	_ = fixCommand.Flags().MarkDeprecated("force", "git check feature removed, no longer needed")
+	_ = fixCommand.Flags().MarkDeprecated("force", "use --git=check to refuse fixing files with uncommitted changes")
+	fixCommand.Flags().BoolVarP(&params.diff, "diff", "", false, "show the changes of each fix as a unified diff")
+	fixCommand.Flags().BoolVarP(&params.interactive, "interactive", "i", false,
+		"show the changes of each fix and prompt whether to apply it")
+	fixCommand.Flags().VarP(&params.only, "only", "", "only apply fixes for rule, may be repeated")
+	fixCommand.Flags().VarP(&params.skip, "skip", "", "skip fixes for rule, may be repeated")
+	fixCommand.Flags().VarP(&params.codemods, "codemod", "",
+		"apply the edits of a codemod policy instead of linter fixes, may be repeated, "+
+			"with --dry-run the changes are shown as diffs")
+	fixCommand.Flags().IntVarP(&params.maxPasses, "max-passes", "", fixer.DefaultMaxPasses,
+		"maximum number of passes made to fix violations, where each pass lints and fixes all files")
+	fixCommand.Flags().StringVarP(&params.git, "git", "", "",
+		"use the git repository of the fixed files, where 'check' refuses to fix files with uncommitted changes, "+
+			"'stage' also stages the fixed files, and 'commit' also creates one commit per rule fixed "+
+			"(the repository is read from its .git directory, and all files must be in the same repository)")
 	fixCommand.Flags().StringVarP(&params.conflictMode, "on-conflict", "", "error",
 		"configure behavior when filename conflicts are detected. Options are 'error' (default) or 'rename'")
 
@@ -191,24 +225,77 @@ func fix(args []string, params *fixParams) (err error) {
 		return fmt.Errorf("could not find potential roots: %w", err)
 	}
 
-	f := fixer.NewFixer().RegisterRoots(roots...).RegisterFixes(fixes.NewDefaultFixes()...)
+	if params.maxPasses < 1 {
+		return fmt.Errorf("invalid number of max passes: %d, expected at least 1", params.maxPasses)
+	}
+
+	if !slices.Contains([]string{"", gitCheck, gitStage, gitCommit}, params.git) {
+		return fmt.Errorf("invalid git mode: %s, expected '%s', '%s' or '%s'", params.git, gitCheck, gitStage, gitCommit)
+	}
+
+	if params.dryRun && (params.git == gitStage || params.git == gitCommit) {
+		return fmt.Errorf("--git=%s can't be used with --dry-run, as no files are changed", params.git)
+	}
+
+	var (
+		selected []fixes.Fix
+		codemods []*fixes.Codemod
+	)
+
+	if params.codemods.isSet {
+		for _, path := range params.codemods.v {
+			policy, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read codemod: %w", err)
+			}
+
+			codemod, err := fixes.NewCodemod(ctx, path, string(policy))
+			if err != nil {
+				return err
+			}
+
+			codemods = append(codemods, codemod)
+		}
+	} else if selected, err = selectFixes(fixes.NewDefaultFixes(), params.only.v, params.skip.v); err != nil {
+		return err
+	}
+
+	var presenter *fixPresenter
+
+	if params.diff || params.interactive || (params.dryRun && params.codemods.isSet) {
+		presenter = &fixPresenter{
+			w:           outputWriter,
+			in:          bufio.NewReader(os.Stdin),
+			interactive: params.interactive,
+			applyAll:    make(map[string]bool),
+		}
+	}
+
+	var versionsMap map[string]ast.RegoVersion
 
 	if userConfigFile != nil {
-		versionsMap, err := config.AllRegoVersions(filepath.Dir(userConfigFile.Name()), &userConfig)
-		if err != nil {
+		if versionsMap, err = config.AllRegoVersions(filepath.Dir(userConfigFile.Name()), &userConfig); err != nil {
 			return fmt.Errorf("failed to get all Rego versions: %w", err)
 		}
-
-		f.SetRegoVersionsMap(versionsMap)
 	}
 
 	if !slices.Contains([]string{"error", "rename"}, params.conflictMode) {
 		return fmt.Errorf("invalid conflict mode: %s, expected 'error' or 'rename'", params.conflictMode)
 	}
 
-	// the default is error, so this is only set when it's rename
-	if params.conflictMode == "rename" {
-		f.SetOnConflictOperation(fixer.OnConflictRename)
+	newFixer := func() *fixer.Fixer {
+		f := fixer.NewFixer().RegisterRoots(roots...).SetMaxPasses(params.maxPasses)
+
+		if versionsMap != nil {
+			f.SetRegoVersionsMap(versionsMap)
+		}
+
+		// the default is error, so this is only set when it's rename
+		if params.conflictMode == "rename" {
+			f.SetOnConflictOperation(fixer.OnConflictRename)
+		}
+
+		return f
 	}
 
 	ignore := userConfig.Ignore.Files
@@ -240,11 +327,6 @@ func fix(args []string, params *fixParams) (err error) {
 		}
 	}
 
-	fileProvider, err := fileprovider.NewInMemoryFileProviderFromFS(absFiltered...)
-	if err != nil {
-		return fmt.Errorf("failed to create file provider: %w", err)
-	}
-
 	r, err := fixer.ReporterForFormat(params.format, outputWriter)
 	if err != nil {
 		return fmt.Errorf("failed to create reporter for format %s: %w", params.format, err)
@@ -252,76 +334,373 @@ func fix(args []string, params *fixParams) (err error) {
 
 	r.SetDryRun(params.dryRun)
 
-	fixReport, err := f.Fix(ctx, &l, fileProvider)
-	if err != nil {
-		return fmt.Errorf("failed to fix: %w", err)
+	var repo *git.Repository
+
+	if params.git != "" && len(absFiltered) > 0 {
+		if repo, err = git.OpenFiles(absFiltered...); err != nil {
+			return err
+		}
 	}
 
-	if fixReport.HasConflicts() {
-		if err = r.Report(fixReport); err != nil {
-			return fmt.Errorf("failed to output fix report: %w", err)
+	runs := []fixRun{{fixer: newFixer().RegisterFixes(selected...).RegisterCodemods(codemods...)}}
+
+	// a first run without writing anything or prompting tells which files would be changed, so that files
+	// with uncommitted changes are refused before any prompt rather than after all have been answered.
+	// Committing the fixes of each rule separately also requires running the fixer once per rule, as a file
+	// may have been fixed by more than one rule in a single run, and the first run tells which rules have
+	// fixes to apply, rather than running all of them.
+	if repo != nil && (params.git == gitCommit || params.interactive) {
+		fileProvider, err := fileprovider.NewInMemoryFileProviderFromFS(absFiltered...)
+		if err != nil {
+			return fmt.Errorf("failed to create file provider: %w", err)
 		}
 
-		return errors.New("fixing failed due to conflicts")
-	}
+		probeReport, err := runs[0].fixer.Fix(ctx, &l, fileProvider)
+		if err != nil {
+			return fmt.Errorf("failed to fix: %w", err)
+		}
+
+		if probeReport.HasConflicts() {
+			if err = r.Report(probeReport); err != nil {
+				return fmt.Errorf("failed to output fix report: %w", err)
+			}
 
-	if params.verbose {
-		if params.dryRun {
-			fmt.Fprintln(outputWriter, "Dry run mode enabled, the following changes would be made:")
+			return errors.New("fixing failed due to conflicts")
 		}
 
-		for _, file := range fileProvider.ModifiedFiles() {
-			fc, err := fileProvider.Get(file)
-			if err != nil {
-				return fmt.Errorf("failed to get file %s: %w", file, err)
+		touched := slices.Concat(fileProvider.ModifiedFiles(), fileProvider.DeletedFiles())
+		if err = checkUncommitted(repo, touched); err != nil {
+			return err
+		}
+
+		if params.git == gitCommit {
+			fixed := util.NewSet[string]()
+
+			for _, file := range probeReport.FixedFiles() {
+				for _, result := range probeReport.FixesForFile(file) {
+					fixed.Add(result.Title)
+				}
 			}
 
-			fmt.Fprintln(outputWriter, "Set:", file, "to:\n", fc, "\n----------")
+			runs = runs[:0]
+
+			for _, fix := range selected {
+				if fixed.Contains(fix.Name()) {
+					runs = append(runs, fixRun{fixer: newFixer().RegisterFixes(fix), name: fix.Name()})
+				}
+			}
+
+			for _, codemod := range codemods {
+				if fixed.Contains(codemod.Name()) {
+					runs = append(runs, fixRun{
+						fixer: newFixer().RegisterCodemods(codemod), name: codemod.Name(), codemod: true,
+					})
+				}
+			}
+
+			if len(runs) == 0 {
+				return util.WrapErr(r.Report(probeReport), "failed to output fix report")
+			}
 		}
+	}
 
-		for _, file := range fileProvider.DeletedFiles() {
-			fmt.Fprintln(outputWriter, "Delete:", file)
+	for _, run := range runs {
+		if presenter != nil {
+			run.fixer.SetConfirmFunc(presenter.confirm)
 		}
 	}
 
-	if !params.dryRun {
-		for _, file := range fileProvider.DeletedFiles() {
-			if err := os.Remove(file); err != nil {
-				return fmt.Errorf("failed to delete file %s: %w", file, err)
+	var lastReport *fixer.Report
+
+	files := absFiltered
+	reported := false
+
+	for _, run := range runs {
+		fileProvider, err := fileprovider.NewInMemoryFileProviderFromFS(files...)
+		if err != nil {
+			return fmt.Errorf("failed to create file provider: %w", err)
+		}
+
+		fixReport, err := run.fixer.Fix(ctx, &l, fileProvider)
+		if err != nil {
+			return fmt.Errorf("failed to fix: %w", err)
+		}
+
+		if fixReport.HasConflicts() {
+			if err = r.Report(fixReport); err != nil {
+				return fmt.Errorf("failed to output fix report: %w", err)
 			}
 
-			dirs, err := rio.DirCleanUpPaths(file, roots)
-			if err != nil {
-				return fmt.Errorf("failed to delete empty directories: %w", err)
+			return errors.New("fixing failed due to conflicts")
+		}
+
+		if params.verbose {
+			if params.dryRun {
+				fmt.Fprintln(outputWriter, "Dry run mode enabled, the following changes would be made:")
 			}
 
-			for _, dir := range dirs {
-				if err := os.Remove(dir); err != nil {
-					return fmt.Errorf("failed to delete directory %s: %w", dir, err)
+			for _, file := range fileProvider.ModifiedFiles() {
+				fc, err := fileProvider.Get(file)
+				if err != nil {
+					return fmt.Errorf("failed to get file %s: %w", file, err)
 				}
+
+				fmt.Fprintln(outputWriter, "Set:", file, "to:\n", fc, "\n----------")
+			}
+
+			for _, file := range fileProvider.DeletedFiles() {
+				fmt.Fprintln(outputWriter, "Delete:", file)
+			}
+		}
+
+		touched := slices.Concat(fileProvider.ModifiedFiles(), fileProvider.DeletedFiles())
+
+		if repo != nil {
+			if err = checkUncommitted(repo, touched); err != nil {
+				return err
 			}
 		}
 
-		for _, file := range fileProvider.ModifiedFiles() {
-			if err = os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
-				return fmt.Errorf("failed to create directory for file %s: %w", file, err)
+		if !params.dryRun {
+			if err := writeFixes(fileProvider, roots); err != nil {
+				return err
+			}
+
+			switch {
+			case repo == nil:
+			case params.git == gitStage:
+				err = repo.Stage(touched...)
+			case params.git == gitCommit && len(touched) > 0:
+				if err = repo.Stage(touched...); err == nil {
+					err = repo.Commit(fixCommitMessage(run, fixReport, repo.Root()), touched...)
+				}
 			}
 
-			fc, err := fileProvider.Get(file)
 			if err != nil {
-				return fmt.Errorf("failed to get file %s: %w", file, err)
+				return err
 			}
 
-			fileMode := fs.FileMode(0o600)
-			if fileInfo, err := os.Stat(file); err == nil {
-				fileMode = fileInfo.Mode()
+			// later runs need to read renamed files from their new location
+			files = slices.Concat(slices.DeleteFunc(files, func(file string) bool {
+				return slices.Contains(fileProvider.DeletedFiles(), file)
+			}), slices.DeleteFunc(fileProvider.ModifiedFiles(), func(file string) bool {
+				return slices.Contains(files, file)
+			}))
+		}
+
+		// when committing rule by rule, only rules with fixes are reported
+		if len(runs) == 1 || fixReport.TotalFixes() > 0 || len(fixReport.RolledBack()) > 0 {
+			if err = r.Report(fixReport); err != nil {
+				return fmt.Errorf("failed to output fix report: %w", err)
 			}
 
-			if err = os.WriteFile(file, []byte(fc), fileMode); err != nil {
-				return fmt.Errorf("failed to write file %s: %w", file, err)
+			reported = true
+		}
+
+		lastReport = fixReport
+
+		if presenter != nil && presenter.quit {
+			break
+		}
+	}
+
+	if !reported && lastReport != nil {
+		return util.WrapErr(r.Report(lastReport), "failed to output fix report")
+	}
+
+	return nil
+}
+
+// fixRun is one run of the fixer over all files. There is a single run with all fixes, unless the fixes of
+// each rule are committed separately, where there is one run per rule.
+type fixRun struct {
+	fixer *fixer.Fixer
+	// name of the rule or codemod fixed in the run, if only one
+	name    string
+	codemod bool
+}
+
+const (
+	gitCheck  = "check"
+	gitStage  = "stage"
+	gitCommit = "commit"
+)
+
+// writeFixes writes the modified files to disk, and removes the deleted ones, along with any directories
+// left empty by their removal.
+func writeFixes(fileProvider *fileprovider.InMemoryFileProvider, roots []string) error {
+	for _, file := range fileProvider.DeletedFiles() {
+		if err := os.Remove(file); err != nil {
+			return fmt.Errorf("failed to delete file %s: %w", file, err)
+		}
+
+		dirs, err := rio.DirCleanUpPaths(file, roots)
+		if err != nil {
+			return fmt.Errorf("failed to delete empty directories: %w", err)
+		}
+
+		for _, dir := range dirs {
+			if err := os.Remove(dir); err != nil {
+				return fmt.Errorf("failed to delete directory %s: %w", dir, err)
 			}
 		}
 	}
 
-	return util.WrapErr(r.Report(fixReport), "failed to output fix report")
+	for _, file := range fileProvider.ModifiedFiles() {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			return fmt.Errorf("failed to create directory for file %s: %w", file, err)
+		}
+
+		fc, err := fileProvider.Get(file)
+		if err != nil {
+			return fmt.Errorf("failed to get file %s: %w", file, err)
+		}
+
+		fileMode := fs.FileMode(0o600)
+		if fileInfo, err := os.Stat(file); err == nil {
+			fileMode = fileInfo.Mode()
+		}
+
+		if err = os.WriteFile(file, []byte(fc), fileMode); err != nil {
+			return fmt.Errorf("failed to write file %s: %w", file, err)
+		}
+	}
+
+	return nil
+}
+
+// checkUncommitted returns an error listing the files among those to be changed which have uncommitted
+// changes in the repository, if any.
+func checkUncommitted(repo *git.Repository, files []string) error {
+	uncommitted, err := repo.Uncommitted(files...)
+	if err != nil {
+		return err
+	}
+
+	if len(uncommitted) > 0 {
+		return fmt.Errorf("refusing to fix files with uncommitted changes, commit or stash them first:\n- %s",
+			strings.Join(util.Map(uncommitted, func(file string) string {
+				return relOrAbs(repo.Root(), file)
+			}), "\n- "))
+	}
+
+	return nil
+}
+
+// fixCommitMessage returns the message for the commit of the fixes made in run, listing the fixed files
+// relative to the root of the repository.
+func fixCommitMessage(run fixRun, fixReport *fixer.Report, root string) string {
+	subject := fmt.Sprintf("Fix %s violations", run.name)
+	if run.codemod {
+		subject = "Apply codemod " + run.name
+	}
+
+	fixed := util.Map(fixReport.FixedFiles(), func(file string) string {
+		if oldPath, ok := fixReport.OldPathForFile(file); ok {
+			return relOrAbs(root, oldPath) + " -> " + relOrAbs(root, file)
+		}
+
+		return relOrAbs(root, file)
+	})
+
+	return fmt.Sprintf("%s\n\nFixes applied by regal fix to:\n- %s\n", subject, strings.Join(fixed, "\n- "))
+}
+
+func relOrAbs(root, path string) string {
+	if rel, err := filepath.Rel(root, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+
+	return path
+}
+
+// selectFixes returns the fixes chosen by the --only and --skip flags, where both may only name rules
+// for which there are fixes available.
+func selectFixes(available []fixes.Fix, only, skip []string) ([]fixes.Fix, error) {
+	names := util.Map(available, fixes.Fix.Name)
+
+	for _, name := range slices.Concat(only, skip) {
+		if !slices.Contains(names, name) {
+			return nil, fmt.Errorf("no fix available for rule %s, available fixes are: %s", name, strings.Join(names, ", "))
+		}
+	}
+
+	return slices.DeleteFunc(slices.Clone(available), func(fix fixes.Fix) bool {
+		return (len(only) > 0 && !slices.Contains(only, fix.Name())) || slices.Contains(skip, fix.Name())
+	}), nil
+}
+
+// fixPresenter shows the changes of each fix as a unified diff before it is applied, and in interactive
+// mode, prompts whether to apply it.
+type fixPresenter struct {
+	w           io.Writer
+	in          *bufio.Reader
+	interactive bool
+	// applyAll holds the rules for which all fixes were chosen to be applied in interactive mode
+	applyAll map[string]bool
+	// quit is set when the user chose to stop fixing in interactive mode
+	quit bool
+}
+
+const fixPromptHelp = `y - apply this fix
+n - skip this fix
+a - apply this fix, and all other fixes for the same rule
+q - quit, keeping the fixes applied so far`
+
+func (p *fixPresenter) confirm(file, contents string, result fixes.FixResult) (bool, error) {
+	if p.interactive && p.applyAll[result.Title] {
+		return true, nil
+	}
+
+	to := file
+	if result.Rename != nil {
+		to = result.Rename.ToPath
+	}
+
+	if to != file {
+		fmt.Fprintf(p.w, "%s: %s -> %s\n", result.Title, file, to)
+	} else {
+		fmt.Fprintf(p.w, "%s: %s\n", result.Title, file)
+	}
+
+	fmt.Fprint(p.w, diff.Unified(file, to, contents, result.Contents))
+
+	if !p.interactive {
+		return true, nil
+	}
+
+	for {
+		fmt.Fprintf(p.w, "Apply fix for %s [y,n,a,q,?]? ", result.Title)
+
+		answer, err := p.in.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return false, fmt.Errorf("failed to read answer: %w", err)
+		}
+
+		switch strings.TrimSpace(answer) {
+		case "y":
+			return true, nil
+		case "n":
+			return false, nil
+		case "a":
+			p.applyAll[result.Title] = true
+
+			return true, nil
+		case "q":
+			p.quit = true
+
+			return false, fixer.ErrStopFixing
+		}
+
+		// no more input to read, so treat it like quitting rather than prompting forever
+		if errors.Is(err, io.EOF) {
+			fmt.Fprintln(p.w)
+
+			p.quit = true
+
+			return false, fixer.ErrStopFixing
+		}
+
+		fmt.Fprintln(p.w, fixPromptHelp)
+	}
 }
@@ -3,32 +3,72 @@ package cmd
 import (
 	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
 	"os/signal"
+	"slices"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
 	"github.com/spf13/cobra"
 
 	rio "github.com/open-policy-agent/regal/internal/io"
 	"github.com/open-policy-agent/regal/internal/lsp"
 	"github.com/open-policy-agent/regal/internal/lsp/connection"
 	"github.com/open-policy-agent/regal/internal/lsp/log"
+	"github.com/open-policy-agent/regal/internal/web"
+	plsp "github.com/open-policy-agent/regal/pkg/lsp"
 	"github.com/open-policy-agent/regal/pkg/version"
 )
 
+type languageServerParams struct {
+	listen         string
+	allowedOrigins []string
+	verbose        bool
+}
+
+// sessionServer runs language server sessions for clients connecting over the network, where each
+// session has its own language server instance, and with that, its own workspace.
+type sessionServer struct {
+	logger *log.Logger
+	params *languageServerParams
+	// done is closed when the last active session has disconnected
+	done chan struct{}
+	wg   sync.WaitGroup
+	// mu guards active and closed, and is held while adding to wg, so that no session can be added
+	// once shutdown has started waiting for the active ones to end
+	mu     sync.Mutex
+	active int
+	closed bool
+	once   sync.Once
+}
+
 func init() {
-	verbose := false
+	params := &languageServerParams{}
 
 	languageServerCommand := &cobra.Command{
 		Use:   "language-server",
 		Short: "Run the Regal Language Server",
-		Long:  `Start the Regal Language Server and listen on stdin/stdout for client editor messages.`,
+		Long: `Start the Regal Language Server and listen on stdin/stdout for client editor messages.
 
-		RunE: wrapProfiling(func([]string) error {
-			ctx, cancel := context.WithCancel(context.Background())
+Use --listen to instead accept any number of clients over the network, where each client session gets its own
+workspace. The server then shuts down once the last client has disconnected. Supported addresses are:
+
+  tcp://host:port        LSP base protocol messages over TCP
+  ws://host:port/path    one JSON-RPC message per WebSocket message, as used by web clients
+
+Clients are not authenticated, and may read any file the server has access to. Addresses without a host, like
+tcp://:5050, therefore only listen on localhost, and a warning is printed when listening on other interfaces.
+Don't expose the server to untrusted networks.`,
 
+		RunE: wrapProfiling(func([]string) error {
 			if exe, err := os.Executable(); err != nil {
 				fmt.Fprintln(os.Stderr, "error getting executable:", err)
 			} else {
@@ -39,60 +79,313 @@ func init() {
 			if os.Getenv("REGAL_DEBUG") != "" {
 				fmt.Fprintln(os.Stderr, "Debug mode enabled")
 
-				verbose = true
+				params.verbose = true
 			}
 
 			logLevel := log.LevelMessage
-			if verbose {
+			if params.verbose {
 				logLevel = log.LevelDebug
 			}
 
-			opts := &lsp.LanguageServerOptions{Logger: log.NewLogger(logLevel, os.Stderr)}
-			ls := lsp.NewLanguageServer(ctx, opts)
+			logger := log.NewLogger(logLevel, os.Stderr)
+
+			if params.listen != "" {
+				if err := listenLanguageServer(params, logger); err != nil {
+					fmt.Fprintln(os.Stderr, err)
+
+					return exit(1)
+				}
+
+				return nil
+			}
+
+			return stdioLanguageServer(params, logger)
+		}),
+	}
+
+	languageServerCommand.Flags().BoolVarP(&params.verbose, "verbose", "v", params.verbose, "Enable verbose logging")
+	languageServerCommand.Flags().StringVar(&params.listen, "listen", "",
+		"listen for clients on address instead of using stdin/stdout (tcp://host:port or ws://host:port/path)")
+	languageServerCommand.Flags().StringSliceVar(&params.allowedOrigins, "allowed-origin", []string{},
+		"origin allowed to connect over WebSocket, in addition to the server's own (use '*' to allow any origin)")
+
+	addPprofFlag(languageServerCommand.Flags())
+
+	RootCommand.AddCommand(languageServerCommand)
+}
+
+func stdioLanguageServer(params *languageServerParams, logger *log.Logger) error {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	stream := jsonrpc2.NewBufferedStream(rio.NewReadWriteCloser(os.Stdin, os.Stdout), jsonrpc2.VSCodeObjectCodec{})
+	conn, ls := startLanguageServerSession(ctx, stream, params, logger)
+
+	ls.StartWebServer(ctx)
+
+	defer conn.Close()
+
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+
+	select {
+	case <-conn.DisconnectNotify():
+		fmt.Fprintln(os.Stderr, "Connection closed")
+	case sig := <-sigChan:
+		fmt.Fprintln(os.Stderr, "signal: ", sig.String())
+	}
+
+	cancel()
+
+	shutdownLanguageServer(ls)
+
+	return nil
+}
+
+func listenLanguageServer(params *languageServerParams, logger *log.Logger) error {
+	addr, err := url.Parse(params.listen)
+	if err != nil || addr.Host == "" {
+		return fmt.Errorf("invalid listen address %q, expected tcp://host:port or ws://host:port/path", params.listen)
+	}
 
-			conf := connection.LoggingConfig{Logger: opts.Logger, LogInbound: verbose, LogOutbound: verbose}
-			copt := &connection.Options{LoggingConfig: conf}
+	if addr.Scheme != "tcp" && addr.Scheme != "ws" {
+		return fmt.Errorf("unsupported listen address scheme %q, expected tcp or ws", addr.Scheme)
+	}
+
+	host, port, err := net.SplitHostPort(addr.Host)
+	if err != nil {
+		return fmt.Errorf("invalid listen address %q: %w", params.listen, err)
+	}
 
-			conn := connection.NewWithOptions(ctx, rio.NewReadWriteCloser(os.Stdin, os.Stdout), ls.Handle, copt)
-			defer conn.Close()
+	// clients aren't authenticated, so only listen on other interfaces when asked to
+	if host == "" {
+		host = "localhost"
+	} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+		fmt.Fprintf(os.Stderr, "Warning: listening on %s, where any client that can connect may read files "+
+			"the server has access to\n", host)
+	}
 
-			ls.SetConn(conn)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var lc net.ListenConfig
+
+	listener, err := lc.Listen(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr.Host, err)
+	}
 
-			ls.StartDiagnosticsWorker(ctx)
-			ls.StartTestLocationsWorker(ctx)
-			ls.StartCommandWorker(ctx)
-			ls.StartConfigWorker(ctx)
-			ls.StartWorkspaceStateWorker(ctx)
-			ls.StartTemplateWorker(ctx)
-			ls.StartQueryCacheWorker(ctx)
-			ls.StartWebServer(ctx)
+	// the web server for docs and debugging is shared by all sessions, rather than each taking one of
+	// its preferred ports
+	var webServer sync.WaitGroup
 
-			sigChan := make(chan os.Signal, 1)
-			signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	webServer.Go(func() { web.NewServer(logger).Start(ctx) })
+	defer webServer.Wait()
 
-			select {
-			case <-conn.DisconnectNotify():
-				fmt.Fprintln(os.Stderr, "Connection closed")
-			case sig := <-sigChan:
-				fmt.Fprintln(os.Stderr, "signal: ", sig.String())
+	server := &sessionServer{logger: logger, params: params, done: make(chan struct{})}
+
+	var closeListener func() error
+
+	if addr.Scheme == "tcp" {
+		go server.acceptTCP(ctx, listener)
+
+		closeListener = listener.Close
+	} else {
+		httpServer := server.webSocketServer(ctx, cmp.Or(addr.Path, "/"))
+
+		go func() {
+			if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				fmt.Fprintln(os.Stderr, "server error:", err)
 			}
+		}()
 
-			cancel()
+		closeListener = httpServer.Close
+	}
+
+	fmt.Fprintf(os.Stderr, "Listening on %s://%s%s\n", addr.Scheme, listener.Addr(), addr.Path)
 
-			shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
-			defer shutdownCancel()
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
 
-			if err := ls.Shutdown(shutdownCtx); err != nil {
-				fmt.Fprintln(os.Stderr, "shutdown error:", err)
+	select {
+	case <-server.done:
+		fmt.Fprintln(os.Stderr, "Last session closed")
+	case sig := <-sigChan:
+		fmt.Fprintln(os.Stderr, "signal: ", sig.String())
+	}
+
+	if err := closeListener(); err != nil {
+		fmt.Fprintln(os.Stderr, "failed to close listener:", err)
+	}
+
+	// ending any sessions still active
+	cancel()
+
+	server.shutdown()
+
+	return nil
+}
+
+func (s *sessionServer) acceptTCP(ctx context.Context, listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if !errors.Is(err, net.ErrClosed) {
+				fmt.Fprintln(os.Stderr, "failed to accept connection:", err)
 			}
 
-			return nil
-		}),
+			return
+		}
+
+		if !s.track() {
+			_ = conn.Close()
+
+			return
+		}
+
+		go func() {
+			defer s.wg.Done()
+
+			s.serve(ctx, jsonrpc2.NewBufferedStream(conn, jsonrpc2.VSCodeObjectCodec{}), conn.RemoteAddr().String())
+		}()
+	}
+}
+
+func (s *sessionServer) webSocketServer(ctx context.Context, path string) *http.Server {
+	upgrader := websocket.Upgrader{}
+	if len(s.params.allowedOrigins) > 0 {
+		upgrader.CheckOrigin = func(r *http.Request) bool {
+			origin := r.Header.Get("Origin")
+
+			return origin == "" || slices.Contains(s.params.allowedOrigins, "*") ||
+				slices.Contains(s.params.allowedOrigins, origin) || origin == "http://"+r.Host
+		}
 	}
 
-	languageServerCommand.Flags().BoolVarP(&verbose, "verbose", "v", verbose, "Enable verbose logging")
+	mux := http.NewServeMux()
+	mux.HandleFunc(path, func(w http.ResponseWriter, r *http.Request) {
+		// handlers may still run after the server has been closed, as the connections of upgraded
+		// requests are hijacked, and thus not waited for by http.Server
+		if !s.track() {
+			http.Error(w, "server is shutting down", http.StatusServiceUnavailable)
 
-	addPprofFlag(languageServerCommand.Flags())
+			return
+		}
 
-	RootCommand.AddCommand(languageServerCommand)
+		defer s.wg.Done()
+
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "failed to upgrade connection:", err)
+
+			s.untrack()
+
+			return
+		}
+
+		// the session takes ownership of ws, which is closed along with the connection
+		s.serve(ctx, plsp.NewObjectStream(ws), r.RemoteAddr)
+	})
+
+	return &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+}
+
+// track adds an active session to wait for on shutdown, and reports false if shutdown has already
+// started, in which case the session must not be served.
+func (s *sessionServer) track() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return false
+	}
+
+	s.wg.Add(1)
+	s.active++
+
+	return true
+}
+
+// untrack removes a tracked session that was never served from the active ones. Unlike a session
+// that ends, this doesn't shut the server down if no sessions remain.
+func (s *sessionServer) untrack() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.active--
+}
+
+// shutdown stops any new sessions from being tracked, and waits for the active ones to end.
+func (s *sessionServer) shutdown() {
+	s.mu.Lock()
+	s.closed = true
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// serve runs a language server session, tracked by track, until the client disconnects, or ctx is done.
+func (s *sessionServer) serve(ctx context.Context, stream jsonrpc2.ObjectStream, remote string) {
+	fmt.Fprintln(os.Stderr, "Session started:", remote)
+
+	sessionCtx, cancel := context.WithCancel(ctx)
+
+	conn, ls := startLanguageServerSession(sessionCtx, stream, s.params, s.logger)
+
+	select {
+	case <-conn.DisconnectNotify():
+	case <-ctx.Done():
+	}
+
+	cancel()
+
+	_ = conn.Close()
+
+	shutdownLanguageServer(ls)
+
+	fmt.Fprintln(os.Stderr, "Session closed:", remote)
+
+	s.mu.Lock()
+	s.active--
+	last := s.active == 0
+	s.mu.Unlock()
+
+	if last {
+		s.once.Do(func() { close(s.done) })
+	}
+}
+
+func startLanguageServerSession(
+	ctx context.Context,
+	stream jsonrpc2.ObjectStream,
+	params *languageServerParams,
+	logger *log.Logger,
+) (*jsonrpc2.Conn, *lsp.LanguageServer) {
+	opts := &lsp.LanguageServerOptions{Logger: logger}
+	ls := lsp.NewLanguageServer(ctx, opts)
+
+	conf := connection.LoggingConfig{Logger: opts.Logger, LogInbound: params.verbose, LogOutbound: params.verbose}
+	copt := &connection.Options{LoggingConfig: conf}
+
+	conn := connection.NewStreamWithOptions(ctx, stream, ls.Handle, copt)
+
+	ls.SetConn(conn)
+
+	ls.StartDiagnosticsWorker(ctx)
+	ls.StartTestLocationsWorker(ctx)
+	ls.StartCommandWorker(ctx)
+	ls.StartConfigWorker(ctx)
+	ls.StartWorkspaceStateWorker(ctx)
+	ls.StartTemplateWorker(ctx)
+	ls.StartQueryCacheWorker(ctx)
+
+	return conn, ls
+}
+
+func shutdownLanguageServer(ls *lsp.LanguageServer) {
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer shutdownCancel()
+
+	if err := ls.Shutdown(shutdownCtx); err != nil {
+		fmt.Fprintln(os.Stderr, "shutdown error:", err)
+	}
 }
@@ -53,8 +53,30 @@ type lintParams struct {
 	failLevel   string
 	enablePrint bool
 	metrics     bool
+	concurrency int
 	profile     bool
+	stats       bool
+	complexity  bool
 	instrument  bool
+	stream      bool
+	progress    bool
+}
+
+// violationLevels counts violations by level, for determining the exit code.
+type violationLevels struct {
+	errors   int
+	warnings int
+}
+
+func (vl *violationLevels) add(violations []report.Violation) {
+	for i := range violations {
+		switch violations[i].Level {
+		case "error":
+			vl.errors++
+		case "warning":
+			vl.warnings++
+		}
+	}
 }
 
 func (params *lintAndFixParams) outputWriter() (io.Writer, error) {
@@ -74,7 +96,7 @@ func setCommonFlags(cmd *cobra.Command, params *lintAndFixParams) {
 	flags := cmd.Flags()
 	flags.StringVarP(&params.configFile, "config-file", "c", "", "set path of configuration file")
 	flags.StringVarP(&params.format, "format", "f", formatPretty,
-		"set output format (pretty, compact, json, github, sarif)")
+		"set output format (pretty, compact, json, jsonl, github, sarif, junit, html, markdown)")
 	flags.StringVarP(&params.outputFile, "output-file", "o", "",
 		"set file to use for linting output, defaults to stdout")
 	flags.BoolVar(&color.NoColor, "no-color", false, "disable color output")
@@ -114,7 +136,7 @@ func init() {
 			return nil
 		},
 		RunE: wrapProfiling(func(args []string) error {
-			rep, err := lint(args, params)
+			found, err := lint(args, params)
 			if err != nil {
 				log.SetOutput(os.Stderr)
 				log.Println(err)
@@ -122,26 +144,15 @@ func init() {
 				return exit(1)
 			}
 
-			errorsFound, warningsFound := 0, 0
-
-			for i := range rep.Violations {
-				switch rep.Violations[i].Level {
-				case "error":
-					errorsFound++
-				case "warning":
-					warningsFound++
-				}
-			}
-
 			exitCode := 0
-			if params.failLevel == "error" && errorsFound > 0 {
+			if params.failLevel == "error" && found.errors > 0 {
 				exitCode = 3
 			}
 
 			if params.failLevel == "warning" {
-				if errorsFound > 0 {
+				if found.errors > 0 {
 					exitCode = 3
-				} else if warningsFound > 0 {
+				} else if found.warnings > 0 {
 					exitCode = 2
 				}
 			}
@@ -163,17 +174,37 @@ func init() {
 		"enable metrics reporting (currently supported only for JSON output format)")
 	lintCommand.Flags().BoolVar(&params.profile, "profile", false,
 		"enable profiling metrics to be added to reporting (currently supported only for JSON output format)")
+	lintCommand.Flags().BoolVar(&params.stats, "stats", false,
+		"enable per-rule timing and violation statistics (supported for pretty, compact and JSON output formats), "+
+			"limiting concurrency to the number of CPUs")
+	lintCommand.Flags().BoolVar(&params.complexity, "complexity", false,
+		"enable complexity metrics of rules and functions to be added to reporting (supported for JSON output formats)")
 	lintCommand.Flags().BoolVar(&params.instrument, "instrument", false,
 		"enable instrumentation metrics to be added to reporting (currently supported only for JSON output format)")
+	lintCommand.Flags().IntVar(&params.concurrency, "concurrency", 0,
+		"set maximum number of files to lint concurrently (default no limit, or the number of CPUs when streaming)")
+	lintCommand.Flags().BoolVar(&params.stream, "stream", false,
+		"report violations as soon as each file has been linted (supported for compact and jsonl output formats, "+
+			"and always enabled for jsonl)")
+	lintCommand.Flags().BoolVar(&params.progress, "progress-events", false,
+		"write progress events as JSON Lines to stderr")
 
 	addPprofFlag(lintCommand.Flags())
 
 	RootCommand.AddCommand(lintCommand)
 }
 
-func lint(args []string, params *lintParams) (result report.Report, err error) {
+func lint(args []string, params *lintParams) (found violationLevels, err error) {
 	if params.profile && params.format != formatJSON {
-		return report.Report{}, errors.New("--profile requires --format json to display profiling data")
+		return found, errors.New("--profile requires --format json to display profiling data")
+	}
+
+	if params.stats && (params.format == formatSarif || params.format == formatJunit || params.format == formatHTML) {
+		return found, fmt.Errorf("--stats is not supported with --format %s", params.format)
+	}
+
+	if params.complexity && params.format != formatJSON && params.format != formatJSONLines {
+		return found, fmt.Errorf("--complexity is not supported with --format %s", params.format)
 	}
 
 	ctx, cancel := getLinterContext(params.lintAndFixParams)
@@ -181,7 +212,12 @@ func lint(args []string, params *lintParams) (result report.Report, err error) {
 
 	outputWriter, err := params.outputWriter()
 	if err != nil {
-		return report.Report{}, err
+		return found, err
+	}
+
+	rep, err := getReporter(params.format, outputWriter)
+	if err != nil {
+		return found, fmt.Errorf("failed to get reporter: %w", err)
 	}
 
 	regal := linter.NewLinter().
@@ -193,10 +229,36 @@ func lint(args []string, params *lintParams) (result report.Report, err error) {
 		WithEnabledRules(params.enable.v...).
 		WithDebugMode(params.debug).
 		WithProfiling(params.profile).
+		WithStats(params.stats).
+		WithComplexity(params.complexity).
 		WithInstrumentation(params.instrument).
 		WithCustomRulesPaths(params.rules.v...).
+		WithConcurrency(params.concurrency).
 		WithInputPaths(args)
 
+	if params.stream || params.format == formatJSONLines {
+		streamingReporter, ok := rep.(reporter.StreamingReporter)
+		if !ok {
+			return found, fmt.Errorf("--stream is not supported with --format %s", params.format)
+		}
+
+		regal = regal.WithViolationsHandler(func(ctx context.Context, violations []report.Violation) error {
+			found.add(violations)
+
+			return streamingReporter.PublishViolations(ctx, violations)
+		})
+	}
+
+	if params.progress {
+		progressReporter := reporter.NewProgressReporter(os.Stderr)
+
+		regal = regal.WithProgressHandler(func(event report.ProgressEvent) {
+			if err := progressReporter.PublishProgress(event); err != nil && params.debug {
+				log.Printf("failed to write progress event: %v", err)
+			}
+		})
+	}
+
 	if params.enablePrint {
 		regal = regal.WithPrintHook(topdown.NewPrintHook(os.Stderr))
 	}
@@ -239,7 +301,7 @@ func lint(args []string, params *lintParams) (result report.Report, err error) {
 
 	userConfig, path, err := loadUserConfig(params.lintAndFixParams, searchPath)
 	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to read user-provided config in %s: %w", path, err)
+		return found, fmt.Errorf("failed to read user-provided config in %s: %w", path, err)
 	}
 
 	if params.metrics {
@@ -252,20 +314,17 @@ func lint(args []string, params *lintParams) (result report.Report, err error) {
 
 	regal, err = regal.Prepare(ctx)
 	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to prepare for linting: %w", err)
+		return found, fmt.Errorf("failed to prepare for linting: %w", err)
 	}
 
-	result, err = regal.Lint(ctx)
+	result, err := regal.Lint(ctx)
 	if err != nil {
-		return report.Report{}, formatError(params.format, fmt.Errorf("error(s) encountered while linting: %w", err))
+		return found, formatError(params.format, fmt.Errorf("error(s) encountered while linting: %w", err))
 	}
 
-	rep, err := getReporter(params.format, outputWriter)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("failed to get reporter: %w", err)
-	}
+	found.add(result.Violations)
 
-	return result, rep.Publish(ctx, result)
+	return found, rep.Publish(ctx, result)
 }
 
 func updateCheckAndWarn(params *lintParams, regalRules *bundle.Bundle, userConfig *config.Config) {
@@ -300,6 +359,8 @@ func getReporter(format string, outputWriter io.Writer) (reporter.Reporter, erro
 		return reporter.NewCompactReporter(outputWriter), nil
 	case formatJSON:
 		return reporter.NewJSONReporter(outputWriter), nil
+	case formatJSONLines:
+		return reporter.NewJSONLinesReporter(outputWriter), nil
 	case formatGitHub:
 		return reporter.NewGitHubReporter(outputWriter), nil
 	case formatFestive:
@@ -308,6 +369,10 @@ func getReporter(format string, outputWriter io.Writer) (reporter.Reporter, erro
 		return reporter.NewSarifReporter(outputWriter), nil
 	case formatJunit:
 		return reporter.NewJUnitReporter(outputWriter), nil
+	case formatHTML:
+		return reporter.NewHTMLReporter(outputWriter), nil
+	case formatMarkdown:
+		return reporter.NewMarkdownReporter(outputWriter), nil
 	default:
 		return nil, fmt.Errorf("unknown format %s", format)
 	}
@@ -324,6 +389,15 @@ func formatError(format string, err error) error {
 			return fmt.Errorf("failed to format errors for output: %w", err)
 		}
 
+		return fmt.Errorf("%s", string(bs))
+	case formatJSONLines:
+		bs, err := json.Marshal(map[string]any{
+			"errors": []string{err.Error()},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to format errors for output: %w", err)
+		}
+
 		return fmt.Errorf("%s", string(bs))
 	case formatJunit:
 		testSuites := junit.Testsuites{
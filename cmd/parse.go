@@ -2,76 +2,335 @@ package cmd
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
-	"log"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/spf13/cobra"
 
-	"github.com/open-policy-agent/opa/v1/util"
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/topdown"
+	outil "github.com/open-policy-agent/opa/v1/util"
 
+	rbundle "github.com/open-policy-agent/regal/bundle"
+	"github.com/open-policy-agent/regal/internal/io/files"
+	"github.com/open-policy-agent/regal/internal/io/files/filter"
 	rp "github.com/open-policy-agent/regal/internal/parse"
 	"github.com/open-policy-agent/regal/pkg/roast/encoding"
 	"github.com/open-policy-agent/regal/pkg/roast/transform"
 )
 
+const (
+	// formatRoAST is the RoAST format value for the --format flag of the parse command.
+	formatRoAST = "roast"
+	// formatAST is the plain OPA AST format value for the --format flag of the parse command.
+	formatAST = "ast"
+)
+
+// parsedDocument is the output for a single file, which is either JSON, or a module to print as a tree.
+type parsedDocument struct {
+	module *ast.Module
+	json   json.RawMessage
+}
+
+type parseParams struct {
+	format   *outil.EnumFlag
+	query    string
+	combined bool
+}
+
 func init() {
+	params := &parseParams{
+		format: outil.NewEnumFlag(formatRoAST, []string{formatRoAST, formatAST, formatPretty, formatCompact}),
+	}
+
 	parseCommand := &cobra.Command{
 		Use:   "parse <path> [path [...]]",
 		Short: "Parse Rego source files with Regal enhancements included in output",
-		Long:  "This command works similar to `opa parse` but includes Regal enhancements in the AST output.",
+		Long: `This command works similar to ` + "`opa parse`" + ` but includes Regal enhancements in the AST output.
+
+Provide any number of files or directories to parse, or - to read a policy from stdin. The output is one document per
+parsed file, or with --combined, a single JSON object where each document is keyed by the name of the file.
+
+Output formats (--format):
+
+  roast     the RoAST JSON used as input to Regal rules (default)
+  ast       the plain OPA AST as JSON
+  pretty    the OPA AST as a tree
+  compact   RoAST without locations, one document per line
+
+Use --query to evaluate a query against the RoAST input of each file, and print the results instead of the AST. The
+Regal bundle is loaded, so helpers like data.regal.ast may be used in queries, just as in custom rules.`,
+
+		Example: `  regal parse policy.rego
+  regal parse --format compact policies/
+  cat policy.rego | regal parse -
+  regal parse --query 'data.regal.ast.rule_names' --combined policies/`,
 
 		PreRunE: func(_ *cobra.Command, args []string) error {
 			if len(args) == 0 {
 				return errors.New("no file to parse provided")
 			}
 
-			if len(args) > 1 {
-				return errors.New("only one file can be parsed at a time")
+			if params.combined && params.format.String() == formatPretty && params.query == "" {
+				return errors.New("--combined is not supported with --format pretty")
 			}
 
 			return nil
 		},
 
-		Run: func(_ *cobra.Command, args []string) {
-			if err := parse(args); err != nil {
-				log.SetOutput(os.Stderr)
-				log.Println(err)
-				os.Exit(1)
+		RunE: wrapProfiling(func(args []string) error {
+			if err := parse(context.Background(), os.Stdout, args, params); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return exit(1)
 			}
-		},
+
+			return nil
+		}),
 	}
+
+	flags := parseCommand.Flags()
+
+	flags.VarP(params.format, "format", "f", "set output format (roast, ast, pretty, compact)")
+	flags.StringVarP(&params.query, "query", "q", "", "evaluate query against the RoAST input of each file")
+	flags.BoolVar(&params.combined, "combined", false, "output a single JSON object keyed by file name")
+
+	addPprofFlag(flags)
+
 	RootCommand.AddCommand(parseCommand)
 }
 
-func parse(args []string) (err error) {
-	filename := args[0]
-
-	bs, err := os.ReadFile(filename)
+// parse writes the parsed representation of each file in paths to w. Files that fail to parse are reported
+// on stderr, and don't stop other files from being parsed, but do make parse return an error.
+func parse(ctx context.Context, w io.Writer, paths []string, params *parseParams) error {
+	filenames, err := parseFilenames(paths)
 	if err != nil {
 		return err
 	}
 
-	content := util.ByteSliceToString(bs)
+	var query *rego.PreparedEvalQuery
+	if params.query != "" {
+		pq, err := rego.New(
+			rego.Query(params.query),
+			rego.ParsedBundle("regal", rbundle.Loaded()),
+			rego.StoreReadAST(true),
+			rego.EnablePrintStatements(true),
+			rego.PrintHook(topdown.NewPrintHook(os.Stderr)),
+		).PrepareForEval(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to prepare query: %w", err)
+		}
+
+		query = &pq
+	}
+
+	combined := make(map[string]json.RawMessage, len(filenames))
+	failed := 0
+
+	for _, filename := range filenames {
+		doc, err := parseDocument(ctx, filename, params.format.String(), query)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+
+			failed++
+
+			continue
+		}
+
+		if params.combined {
+			combined[filename] = doc.json
+		} else if err := writeParseDocument(w, doc, params.format.String() == formatCompact); err != nil {
+			return err
+		}
+	}
+
+	if params.combined {
+		bs, err := json.Marshal(combined)
+		if err != nil {
+			return err
+		}
+
+		if err := writeParseDocument(w, parsedDocument{json: bs}, false); err != nil {
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed to parse", failed, len(filenames))
+	}
+
+	return nil
+}
+
+// parseFilenames expands directories in paths to the Rego files they contain, while files provided
+// explicitly are kept regardless of their extension.
+func parseFilenames(paths []string) ([]string, error) {
+	filenames := make([]string, 0, len(paths))
+
+	for _, path := range paths {
+		if path == "-" {
+			filenames = append(filenames, path)
+
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			filenames = append(filenames, path)
+
+			continue
+		}
+
+		filenames, err = files.DefaultWalkReducer(path, filenames).
+			WithFilters(filter.NotRego).
+			Reduce(files.PathAppendReducer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+	}
+
+	return filenames, nil
+}
+
+// parseDocument parses filename, or stdin if filename is -, and returns the document to output for it.
+func parseDocument(ctx context.Context, filename, format string, query *rego.PreparedEvalQuery) (parsedDocument, error) {
+	var (
+		doc parsedDocument
+		bs  []byte
+		err error
+	)
+
+	if filename == "-" {
+		filename = "stdin"
+		bs, err = io.ReadAll(os.Stdin)
+	} else {
+		bs, err = os.ReadFile(filename)
+	}
+
+	if err != nil {
+		return doc, err
+	}
+
+	content := outil.ByteSliceToString(bs)
 
 	module, err := rp.ModuleUnknownVersionWithOpts(filename, content, rp.ParserOptions())
 	if err != nil {
-		return err
+		return doc, err
+	}
+
+	if query == nil {
+		switch format {
+		case formatPretty:
+			return parsedDocument{module: module}, nil
+		case formatAST:
+			doc.json, err = json.Marshal(module)
+
+			return doc, err
+		}
 	}
 
 	value, err := transform.ToAST(filename, content, module, false)
 	if err != nil {
-		return err
+		return doc, err
+	}
+
+	if query != nil {
+		results, err := evalParseQuery(ctx, query, value)
+		if err != nil {
+			return doc, err
+		}
+
+		if value, err = ast.InterfaceToValue(results); err != nil {
+			return doc, err
+		}
+	}
+
+	if format == formatCompact {
+		value = stripLocations(value)
 	}
 
 	var buf bytes.Buffer
-	if err = encoding.OfValue().Encode(&buf, value); err == nil {
-		dst := new(bytes.Buffer)
-		if err = json.Indent(dst, buf.Bytes(), "", "  "); err == nil {
-			_, err = dst.WriteTo(os.Stdout)
+	if err = encoding.OfValue().Encode(&buf, value); err != nil {
+		return doc, err
+	}
+
+	doc.json = buf.Bytes()
+
+	return doc, nil
+}
+
+// evalParseQuery evaluates the query with input, returning the bindings of each result, or the value of
+// the last expression for queries without bindings.
+func evalParseQuery(ctx context.Context, query *rego.PreparedEvalQuery, input ast.Value) ([]any, error) {
+	rs, err := query.Eval(ctx, rego.EvalParsedInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate query: %w", err)
+	}
+
+	results := make([]any, 0, len(rs))
+
+	for _, r := range rs {
+		if len(r.Bindings) > 0 {
+			results = append(results, r.Bindings)
+		} else {
+			results = append(results, r.Expressions[len(r.Expressions)-1].Value)
 		}
 	}
 
+	return results, nil
+}
+
+func writeParseDocument(w io.Writer, doc parsedDocument, compact bool) error {
+	if doc.module != nil {
+		ast.Pretty(w, doc.module)
+
+		return nil
+	}
+
+	var buf bytes.Buffer
+	if compact {
+		buf.Write(doc.json)
+	} else if err := json.Indent(&buf, doc.json, "", "  "); err != nil {
+		return err
+	}
+
+	buf.WriteByte('\n')
+
+	_, err := buf.WriteTo(w)
+
 	return err
 }
+
+// stripLocations returns a copy of the RoAST value with all location attributes removed.
+func stripLocations(value ast.Value) ast.Value {
+	switch v := value.(type) {
+	case ast.Object:
+		stripped := ast.NewObject()
+
+		v.Foreach(func(key, val *ast.Term) {
+			if key.Value.Compare(ast.InternedTerm("location").Value) != 0 {
+				stripped.Insert(key, ast.NewTerm(stripLocations(val.Value)))
+			}
+		})
+
+		return stripped
+	case *ast.Array:
+		terms := make([]*ast.Term, 0, v.Len())
+		for i := range v.Len() {
+			terms = append(terms, ast.NewTerm(stripLocations(v.Elem(i).Value)))
+		}
+
+		return ast.NewArray(terms...)
+	default:
+		return value
+	}
+}
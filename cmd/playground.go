@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-policy-agent/regal/internal/lsp/log"
+	"github.com/open-policy-agent/regal/internal/util"
+	"github.com/open-policy-agent/regal/internal/web/playground"
+)
+
+type playgroundParams struct {
+	addr    string
+	verbose bool
+}
+
+func init() {
+	params := &playgroundParams{}
+
+	playgroundCommand := &cobra.Command{
+		Use:   "playground",
+		Short: "Run a local, browser based Rego playground",
+		Long: `Start a local web server providing a Rego playground in the browser.
+
+The playground offers an editor with lint diagnostics and formatting provided by a language server session, panels
+for evaluating queries against input and data, and for exploring the output of each compiler stage. Sessions may be
+downloaded as bundles to share with others, who can then open them in their own playground. Everything runs locally,
+and no network access is required.
+
+Unless --addr is provided, the playground listens on the first free port of 5052-5054 on localhost. Requests are
+only accepted when made to that address, or to localhost, from pages of the playground itself. Policies evaluated in
+the playground can't call http.send, opa.runtime or the net.* built-in functions.`,
+
+		RunE: wrapProfiling(func([]string) error {
+			if err := runPlayground(params); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return exit(1)
+			}
+
+			return nil
+		}),
+	}
+
+	playgroundCommand.Flags().StringVarP(&params.addr, "addr", "a", "", "address to listen on (default localhost:5052)")
+	playgroundCommand.Flags().BoolVarP(&params.verbose, "verbose", "v", params.verbose, "Enable verbose logging")
+
+	addPprofFlag(playgroundCommand.Flags())
+
+	RootCommand.AddCommand(playgroundCommand)
+}
+
+func runPlayground(params *playgroundParams) error {
+	addr := params.addr
+	if addr == "" {
+		port, err := util.FreePort(5052, 5053, 5054)
+		if err != nil {
+			port = 0
+		}
+
+		addr = fmt.Sprintf("localhost:%d", port)
+	}
+
+	logLevel := log.LevelMessage
+	if params.verbose {
+		logLevel = log.LevelDebug
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	var lc net.ListenConfig
+
+	listener, err := lc.Listen(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	// the playground may be reached at the address it listens on, and at localhost when listening there
+	hosts := []string{addr, listener.Addr().String()}
+	if _, port, err := net.SplitHostPort(listener.Addr().String()); err == nil {
+		hosts = append(hosts, net.JoinHostPort("localhost", port))
+	}
+
+	server := &http.Server{
+		Handler:           playground.NewHandler(log.NewLogger(logLevel, os.Stderr), hosts...),
+		ReadHeaderTimeout: 10 * time.Second,
+		// sessions started from requests end when the server shuts down
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		fmt.Fprintln(os.Stderr, "Shutting down")
+
+		_ = server.Close()
+	})
+	defer stop()
+
+	fmt.Fprintf(os.Stderr, "Regal playground running at http://%s\n", listener.Addr())
+
+	if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("failed to serve playground: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/open-policy-agent/regal/pkg/roast/encoding"
+)
+
+func init() {
+	unparseCommand := &cobra.Command{
+		Use:   "unparse <path>",
+		Short: "Convert RoAST JSON back to Rego source",
+		Long: `This command is the reverse of ` + "`regal parse`" + `, and prints the Rego module of the provided RoAST JSON, formatted.
+
+Provide a file containing RoAST, or - to read it from stdin. Comments are restored from the source lines found in
+the regal.file.lines attribute, when present. Without those, as in the compact output of regal parse, comments are
+lost, but annotations are still written as METADATA comments.`,
+
+		Example: `  regal parse policy.rego > policy.json
+  regal unparse policy.json
+  regal parse policy.rego | regal unparse -`,
+
+		Args: cobra.ExactArgs(1),
+
+		RunE: wrapProfiling(func(args []string) error {
+			if err := unparse(os.Stdout, args[0]); err != nil {
+				fmt.Fprintln(os.Stderr, err)
+
+				return exit(1)
+			}
+
+			return nil
+		}),
+	}
+
+	addPprofFlag(unparseCommand.Flags())
+
+	RootCommand.AddCommand(unparseCommand)
+}
+
+func unparse(w io.Writer, path string) error {
+	var (
+		bs  []byte
+		err error
+	)
+
+	if path == "-" {
+		bs, err = io.ReadAll(os.Stdin)
+	} else {
+		bs, err = os.ReadFile(path)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(bs) == 0 {
+		return errors.New("no RoAST provided")
+	}
+
+	source, err := encoding.Unparse(bs)
+	if err != nil {
+		return err
+	}
+
+	_, err = w.Write(source)
+
+	return err
+}
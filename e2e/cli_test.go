@@ -3,8 +3,10 @@
 package e2e
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"slices"
@@ -632,6 +634,209 @@ project:
 		verify(t)
 }
 
+func TestFixDiff(t *testing.T) {
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		"p/p.rego":           "package p\n\nallow = true\n\n#no space\n",
+	}
+	td := testutil.TempDirectoryOf(t, initialState)
+
+	regal("fix", "--dry-run", "--diff", join(td, "p")).
+		expectStdout(
+			contains("opa-fmt: %s\n", join(td, "p/p.rego")),
+			contains("-allow = true\n+allow := true\n"),
+			contains("no-whitespace-comment: %s\n", join(td, "p/p.rego")),
+			contains("-#no space\n+# no space\n"),
+			contains("2 fixes to apply:"),
+		).
+		expectFiles(contentMatchesMap(td, initialState)).
+		verify(t)
+}
+
+func TestFixOnlyAndSkip(t *testing.T) {
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		"p/p.rego":           "package p\n\nallow = true\n\n#no space\n",
+	}
+	td := testutil.TempDirectoryOf(t, initialState)
+
+	r := regal("fix", "--dry-run", "--only", "no-whitespace-comment", join(td, "p")).
+		expectStdout(contains("1 fix to apply:"), contains("- no-whitespace-comment"), notContains("use-assignment")).
+		verify(t)
+
+	r.regal("fix", "--dry-run", "--skip", "opa-fmt", "--skip", "no-whitespace-comment", join(td, "p")).
+		expectStdout(contains("1 fix to apply:"), contains("- use-assignment-operator"), notContains("whitespace")).
+		verify(t)
+
+	r.regal("fix", "--only", "unknown-rule", join(td, "p")).
+		expectExitCode(1).
+		expectStdout(equals("")).
+		expectStderr(contains("no fix available for rule unknown-rule")).
+		verify(t)
+}
+
+func TestFixInteractive(t *testing.T) {
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		"p/p.rego":           "package p\n\nallow = true\n\n#no space\n",
+	}
+	td := testutil.TempDirectoryOf(t, initialState)
+
+	expectedState := maps.Clone(initialState)
+	expectedState["p/p.rego"] = "package p\n\nallow = true\n\n# no space\n"
+
+	// skip opa-fmt and use-assignment-operator, apply no-whitespace-comment
+	regal("fix", "--interactive", join(td, "p")).
+		stdinFrom(strings.NewReader("n\n?\nn\ny\n")).
+		expectStdout(
+			contains("Apply fix for opa-fmt [y,n,a,q,?]? "),
+			contains("a - apply this fix, and all other fixes for the same rule"),
+			contains("1 fix applied:"),
+			contains("- no-whitespace-comment"),
+		).
+		expectFiles(contentMatchesMap(td, expectedState)).
+		verify(t)
+}
+
+func TestFixMaxPasses(t *testing.T) {
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		// the opa-fmt fix re-indents the redundant existence check, which is left to be removed in a second pass
+		"p/p.rego": "package p\n\nallow if {\n  input.x\n  input.x == \"y\"\n}\n",
+	}
+	td := testutil.TempDirectoryOf(t, initialState)
+
+	r := regal("fix", "--dry-run", "--max-passes", "1", join(td, "p")).
+		expectStdout(
+			contains("1 fix to apply:"),
+			contains("- opa-fmt"),
+			contains("Fixing stopped at the maximum number of passes (1)"),
+		).
+		verify(t)
+
+	r.regal("fix", "--dry-run", join(td, "p")).
+		expectStdout(contains("- redundant-existence-check"), notContains("Fixing stopped")).
+		verify(t)
+
+	r.regal("fix", "--max-passes", "0", join(td, "p")).
+		expectExitCode(1).
+		expectStderr(contains("invalid number of max passes")).
+		verify(t)
+}
+
+func TestFixCodemod(t *testing.T) {
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		"codemod.rego": `package codemod.has_key
+
+import data.regal.ast
+
+edits contains {"location": expr.location, "text": text} if {
+	walk(input.rules, [_, expr])
+	ast.ref_to_string(expr.terms[0].value) == "data.lib.util.has_key"
+
+	text := sprintf("%s in object.keys(%s)", [expr.terms[2].value, ast.ref_to_string(expr.terms[1].value)])
+}
+`,
+		"p/p.rego": "package p\n\nallow if data.lib.util.has_key(input, k)\n",
+	}
+	td := testutil.TempDirectoryOf(t, initialState)
+
+	r := regal("fix", "--codemod", join(td, "codemod.rego"), "--dry-run", join(td, "p")).
+		expectStdout(
+			contains("-allow if data.lib.util.has_key(input, k)\n+allow if k in object.keys(input)\n"),
+			contains("1 fix to apply:"),
+			contains("- codemod.has_key"),
+		).
+		expectFiles(contentMatchesMap(td, initialState)).
+		verify(t)
+
+	expectedState := maps.Clone(initialState)
+	expectedState["p/p.rego"] = "package p\n\nallow if k in object.keys(input)\n"
+
+	r.regal("fix", "--codemod", join(td, "codemod.rego"), join(td, "p")).
+		expectStdout(contains("1 fix applied:"), notContains("@@")).
+		expectFiles(contentMatchesMap(td, expectedState)).
+		verify(t)
+}
+
+func TestFixGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	initialState := map[string]string{
+		".regal/config.yaml": "project:\n  rego-version: 1\n",
+		"p/p.rego":           "package p\n\n#no space\nallow := true\n",
+		"foo/q.rego":         "package q\n",
+	}
+	td := must.Return(filepath.EvalSymlinks(testutil.TempDirectoryOf(t, initialState)))(t)
+
+	gitIn(t, td, "init", "--quiet")
+	gitIn(t, td, "config", "user.name", "Regal")
+	gitIn(t, td, "config", "user.email", "regal@example.com")
+	gitIn(t, td, "add", ".")
+	gitIn(t, td, "commit", "--quiet", "--message", "Initial commit")
+
+	uncommittedState := maps.Clone(initialState)
+	uncommittedState["p/p.rego"] += "\n# uncommitted\n"
+
+	must.WriteFile(t, join(td, "p/p.rego"), []byte(uncommittedState["p/p.rego"]))
+
+	r := regal("fix", "--git=check", td).
+		expectExitCode(1).
+		expectStderr(contains("refusing to fix files with uncommitted changes"), contains("- p/p.rego")).
+		expectFiles(contentMatchesMap(td, uncommittedState)).
+		verify(t)
+
+	// with --interactive, files with uncommitted changes are refused before prompting for any fix
+	r.regal("fix", "--git=check", "--interactive", td).
+		stdinFrom(strings.NewReader("")).
+		expectExitCode(1).
+		expectStdout(notContains("Apply fix")).
+		expectStderr(contains("refusing to fix files with uncommitted changes"), contains("- p/p.rego")).
+		expectFiles(contentMatchesMap(td, uncommittedState)).
+		verify(t)
+
+	r.regal("fix", "--git=stage", "--dry-run", td).
+		expectExitCode(1).
+		expectStderr(contains("--git=stage can't be used with --dry-run")).
+		verify(t)
+
+	gitIn(t, td, "checkout", "--quiet", "--", ".")
+
+	r.regal("fix", "--git=commit", td).
+		expectStdout(contains("- no-whitespace-comment"), contains("foo/q.rego -> q/q.rego:")).
+		verify(t)
+
+	assert.Equal(t, "", gitIn(t, td, "status", "--short"))
+	assert.Equal(t, "Initial commit\n"+
+		"Fix no-whitespace-comment violations\n"+
+		"Fix directory-package-mismatch violations", gitIn(t, td, "log", "--format=%s", "--reverse"),
+	)
+	assert.StringContains(t, gitIn(t, td, "show", "--name-status", "--format=", "HEAD"), "R100\tfoo/q.rego\tq/q.rego")
+
+	must.WriteFile(t, join(td, "p/p.rego"), []byte("package p\n\n#no space\n"))
+	gitIn(t, td, "commit", "--quiet", "--all", "--message", "Add violation")
+
+	r.regal("fix", "--git=stage", td).
+		expectStdout(contains("1 fix applied:")).
+		verify(t)
+
+	assert.Equal(t, "M  p/p.rego", gitIn(t, td, "status", "--short"))
+
+	nested := join(td, "nested")
+
+	must.MkdirAll(t, nested)
+	must.WriteFile(t, join(nested, "n.rego"), []byte("package nested\n"))
+	gitIn(t, nested, "init", "--quiet")
+
+	r.regal("fix", "--git=check", td).
+		expectExitCode(1).
+		expectStderr(contains("files belong to more than one git repository")).
+		verify(t)
+}
+
 // verify fix for https://github.com/open-policy-agent/regal/issues/1082
 func TestLintAnnotationCustomAttributeMultipleItems(t *testing.T) {
 	regal("lint", "--config-file", cwd("e2e_conf.yaml"), "--disable=directory-package-mismatch",
@@ -640,6 +845,109 @@ func TestLintAnnotationCustomAttributeMultipleItems(t *testing.T) {
 		verify(t)
 }
 
+func TestExploreDiffAndPlan(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "p.rego")
+	must.WriteFile(t, policy, []byte("package p\n\n# METADATA\n# entrypoint: true\nallow if {\n\tsome x in input.xs\n\tx == 1\n}\n"))
+
+	regal("explore", "--diff", "--plan", policy).
+		expectStdout(
+			contains("# ResolveRefs (unchanged)"),
+			contains("--- SetRuleTree\n+++ RewriteLocalVars\n"),
+			contains("-\tsome x in input.xs\n-\tx == 1\n+\t__local"),
+			contains("# Plan\n\n*ir.Policy"),
+		).
+		verify(t)
+}
+
+func TestExploreCompileFailureJSON(t *testing.T) {
+	policy := filepath.Join(t.TempDir(), "p.rego")
+	must.WriteFile(t, policy, []byte("package p\n\nx := y\n"))
+
+	var output struct {
+		Stages []struct {
+			Name  string `json:"name"`
+			Error string `json:"error"`
+		} `json:"stages"`
+	}
+
+	regal("explore", "--format", "json", policy).
+		expectExitCode(1).
+		expectStdout(unmarshalsTo(&output)).
+		verify(t)
+
+	last := output.Stages[len(output.Stages)-1]
+	if last.Name != "CheckSafetyRuleHeads: Failure" || !strings.Contains(last.Error, "var y is unsafe") {
+		t.Errorf("expected last stage to report unsafe var, got %+v", last)
+	}
+}
+
+func TestParseCombinedWithQuery(t *testing.T) {
+	dir := t.TempDir()
+	must.WriteFile(t, filepath.Join(dir, "a.rego"), []byte("package a\n\nx := 1\n"))
+	must.WriteFile(t, filepath.Join(dir, "b.rego"), []byte("package b\n\ny if input.z\n"))
+	must.WriteFile(t, filepath.Join(dir, "c.txt"), []byte("not rego"))
+
+	var output map[string][]string
+
+	regal("parse", "--combined", "--query", "input.rules[_].head.ref[0].value", dir).
+		expectStdout(unmarshalsTo(&output)).
+		verify(t)
+
+	expected := map[string][]string{
+		filepath.Join(dir, "a.rego"): {"x"},
+		filepath.Join(dir, "b.rego"): {"y"},
+	}
+
+	if !maps.EqualFunc(output, expected, slices.Equal) {
+		t.Errorf("expected %v, got %v", expected, output)
+	}
+}
+
+func TestParseStdinCompact(t *testing.T) {
+	regal("parse", "--format", "compact", "-").
+		stdinFrom(strings.NewReader("package p\n\nx := 1\n")).
+		expectStdout(
+			contains(`{"package":{"path":[{"type":"var","value":"data"},{"type":"string","value":"p"}]},`),
+			contains(`"name":"stdin"`),
+			notContains(`"location"`),
+		).
+		verify(t)
+}
+
+func TestParseFailureContinues(t *testing.T) {
+	dir := t.TempDir()
+	must.WriteFile(t, filepath.Join(dir, "a.rego"), []byte("package a\n"))
+	must.WriteFile(t, filepath.Join(dir, "b.rego"), []byte("package"))
+
+	regal("parse", "--format", "compact", dir).
+		expectExitCode(1).
+		expectStdout(contains(`"value":"a"`)).
+		expectStderr(contains("rego_parse_error"), contains("1 of 2 files failed to parse")).
+		verify(t)
+}
+
+func TestUnparseRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	policy := "package p\n\n# comments are kept\nallow if {\n\tinput.x == 1\n}\n"
+
+	must.WriteFile(t, filepath.Join(dir, "p.rego"), []byte(policy))
+
+	var roast json.RawMessage
+
+	r := regal("parse", filepath.Join(dir, "p.rego")).expectStdout(unmarshalsTo(&roast)).verify(t)
+
+	must.WriteFile(t, filepath.Join(dir, "p.json"), roast)
+
+	r.regal("unparse", filepath.Join(dir, "p.json")).
+		expectStdout(equals("package p\n\n# comments are kept\nallow if {\n\tinput.x == 1\n}\n")).
+		verify(t)
+
+	r.regal("unparse", "-").
+		stdinFrom(strings.NewReader(`{"package":{"path":[{"type":"var","value":"data"},{"type":"string","value":"p"}]}}`)).
+		expectStdout(equals("package p\n")).
+		verify(t)
+}
+
 func join(root, rel string) string {
 	return filepath.Join(root, filepath.FromSlash(rel))
 }
@@ -648,6 +956,20 @@ func cwd(rel string) string {
 	return join(_cwd, rel)
 }
 
+func gitIn(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s failed: %v: %s", args[0], err, out)
+	}
+
+	return strings.TrimSpace(string(out))
+}
+
 func readProvidedConfig(t *testing.T) config.Config {
 	t.Helper()
 	return must.Return(config.FromPath(cwd("../bundle/regal/config/provided/data.yaml")))(t)
@@ -0,0 +1,185 @@
+//go:build e2e
+
+package e2e
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/open-policy-agent/regal/pkg/lsp"
+)
+
+func TestLanguageServerListenTCP(t *testing.T) {
+	t.Parallel()
+
+	// without a host, the server only listens on localhost
+	testLanguageServerListen(t, "tcp://:0", func(ctx context.Context, addr string) (jsonrpc2.ObjectStream, error) {
+		host, _, err := net.SplitHostPort(strings.TrimPrefix(addr, "tcp://"))
+		if ip := net.ParseIP(host); err != nil || ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("expected server to listen on loopback interface, got %s", addr)
+		}
+
+		var d net.Dialer
+
+		conn, err := d.DialContext(ctx, "tcp", strings.TrimPrefix(addr, "tcp://"))
+		if err != nil {
+			return nil, err
+		}
+
+		return jsonrpc2.NewBufferedStream(conn, jsonrpc2.VSCodeObjectCodec{}), nil
+	})
+}
+
+func TestLanguageServerListenWebSocket(t *testing.T) {
+	t.Parallel()
+
+	testLanguageServerListen(t, "ws://127.0.0.1:0/lsp", func(ctx context.Context, addr string) (jsonrpc2.ObjectStream, error) {
+		ws, _, err := websocket.DefaultDialer.DialContext(ctx, addr, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		return lsp.NewObjectStream(ws), nil
+	})
+}
+
+func TestLanguageServerListenInvalidAddress(t *testing.T) {
+	regal("language-server", "--listen", "udp://127.0.0.1:0").
+		expectExitCode(1).
+		expectStderr(contains("unsupported listen address scheme \"udp\"")).
+		verify(t)
+}
+
+// testLanguageServerListen starts a language server listening on addr, and connects two clients with separate
+// workspaces, verifying that each client only gets diagnostics for its own workspace, and that the server exits
+// once both clients have disconnected.
+func testLanguageServerListen(
+	t *testing.T,
+	addr string,
+	dial func(context.Context, string) (jsonrpc2.ObjectStream, error),
+) {
+	t.Helper()
+
+	ctx, cancel := context.WithTimeout(t.Context(), 30*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, regal().binary(), "language-server", "--listen", addr)
+
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	listening := make(chan string, 1)
+
+	go func() {
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			if after, ok := strings.CutPrefix(scanner.Text(), "Listening on "); ok {
+				listening <- after
+			}
+		}
+	}()
+
+	var serverAddr string
+
+	select {
+	case serverAddr = <-listening:
+	case <-ctx.Done():
+		t.Fatal("timed out waiting for language server to listen")
+	}
+
+	clients := make([]*jsonrpc2.Conn, 2)
+	diagnostics := make([]chan string, 2)
+
+	for i := range clients {
+		stream, err := dial(ctx, serverAddr)
+		if err != nil {
+			t.Fatalf("failed to connect client %d: %v", i, err)
+		}
+
+		dir := t.TempDir()
+		file := filepath.Join(dir, fmt.Sprintf("p%d.rego", i))
+
+		if err := os.WriteFile(file, []byte(fmt.Sprintf("package p%d\n\ncamelCase := true\n", i)), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		diagnostics[i] = make(chan string, 10)
+		clients[i] = jsonrpc2.NewConn(ctx, stream, diagnosticsHandler(diagnostics[i]))
+
+		var response struct {
+			Capabilities map[string]any `json:"capabilities"`
+		}
+
+		params := map[string]any{"rootUri": "file://" + filepath.ToSlash(dir), "clientInfo": map[string]any{"name": "e2e"}}
+		if err := clients[i].Call(ctx, "initialize", params, &response); err != nil {
+			t.Fatalf("client %d failed to initialize: %v", i, err)
+		}
+
+		if len(response.Capabilities) == 0 {
+			t.Fatalf("client %d got no capabilities in initialize response", i)
+		}
+
+		if err := clients[i].Notify(ctx, "initialized", struct{}{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range clients {
+		select {
+		case uri := <-diagnostics[i]:
+			if !strings.HasSuffix(uri, fmt.Sprintf("/p%d.rego", i)) {
+				t.Errorf("client %d got diagnostics for file outside its workspace: %s", i, uri)
+			}
+		case <-ctx.Done():
+			t.Fatalf("timed out waiting for diagnostics for client %d", i)
+		}
+	}
+
+	for _, client := range clients {
+		_ = client.Close()
+	}
+
+	if err := cmd.Wait(); err != nil {
+		t.Fatalf("expected language server to exit cleanly after last client disconnected: %v", err)
+	}
+}
+
+func diagnosticsHandler(uris chan<- string) jsonrpc2.Handler {
+	var once sync.Once
+
+	return jsonrpc2.HandlerWithError(func(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		if req.Method != "textDocument/publishDiagnostics" || req.Params == nil {
+			return struct{}{}, nil
+		}
+
+		var params struct {
+			URI         string `json:"uri"`
+			Diagnostics []any  `json:"diagnostics"`
+		}
+
+		if err := json.Unmarshal(*req.Params, &params); err == nil && len(params.Diagnostics) > 0 {
+			once.Do(func() { uris <- params.URI })
+		}
+
+		return struct{}{}, nil
+	})
+}
@@ -0,0 +1,5 @@
+# METADATA
+# description: declared as Rego v0 in .manifest, but uses v1 syntax
+package authz.legacy
+
+allow if input.legacy
@@ -0,0 +1,5 @@
+# METADATA
+# description: package outside of the roots declared in .manifest
+package outside
+
+allow := true
@@ -0,0 +1,5 @@
+# METADATA
+# description: policy in bundle declaring overlapping roots
+package authz
+
+allow := true
@@ -0,0 +1,5 @@
+# METADATA
+# description: project root without .manifest, loaded with other bundles
+package users
+
+allow := true
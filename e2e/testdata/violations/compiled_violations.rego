@@ -0,0 +1,31 @@
+# METADATA
+# description: |
+#   This file is used for e2e tests of linter rules that depend on the module being compiled
+#   by OPA, which is not possible for most_violations.rego, as it contains compilation errors.
+package compiled_violations
+
+# rule-not-indexed
+allow if input.method == upper(data.config.method)
+
+allow if input.method == "GET"
+
+impossible_comparison if {
+	n := count(input.users)
+	n == "many"
+}
+
+non_collection_iteration if {
+	names := concat(",", input.names)
+	some name in names
+	name == "admin"
+}
+
+non_string_argument if {
+	n := count(input.users)
+	startswith(n, "1")
+}
+
+count_on_scalar if {
+	n := count(input.users)
+	count(n) > 1
+}
@@ -43,6 +43,10 @@ import data.after.rule
 
 ### Bugs ###
 
+conflicting_output := 1 if input.a
+
+conflicting_output := 2 if input.b
+
 constant_condition if {
 	1 == 1
 }
@@ -284,6 +288,19 @@ todo_test_bad if {
 	input.bad
 }
 
+test_without_assertion if {
+	some x in input.xs
+	y := x
+}
+
+test_duplicate_test_input if {
+	pointless_reassignment with input as {"duplicate": true}
+	pointless_reassignment with input as {"duplicate": true}
+	pointless_reassignment with input as {"duplicate": true}
+}
+
+test_unused_mock if pointless_reassignment with comprehension_term_assignment as []
+
 print_or_trace_call if {
 	print("forbidden!")
 }
@@ -334,3 +351,32 @@ non_loop_expression if {
 	endswith(user.email, "example.com")
 	role == "admin"
 }
+
+input_traversal_in_loop contains value if {
+	some name in input.names
+	walk(input, [_, value])
+	value.name == name
+}
+
+### Security ###
+
+default allow := true
+
+# default-allow
+allow := false if "banned" in input.user.roles
+
+# hardcoded-secret
+api_key := "b3f1c2d4e5a6"
+
+http_send_error_handling := http.send({"method": "GET", "url": "https://users.example.com/admins"})
+
+http_send_insecure_tls := http.send({
+	"method": "GET",
+	"url": "https://users.example.com",
+	"tls_insecure_skip_verify": true,
+	"raise_error": false,
+}).body
+
+jwt_decode_without_verify := io.jwt.decode(input.token)[1]
+
+opa_runtime_env := opa.runtime().env.API_KEY
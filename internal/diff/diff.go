@@ -0,0 +1,229 @@
+// Package diff provides line based diffs of text, in the unified format, or as the changes between texts.
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// contextLines is the number of unchanged lines shown around changes, as is the default for diff -u.
+const contextLines = 3
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+	// index of the line in the old (for equal and delete) and new (for equal and insert) text
+	oldIndex, newIndex int
+}
+
+// Unified returns the unified diff between old and new, with oldName and newName in the header, or an empty
+// string if both are equal.
+func Unified(oldName, newName, old, new string) string {
+	if old == new {
+		return ""
+	}
+
+	ops := lineOps(splitLines(old), splitLines(new))
+
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	for _, hunk := range hunks(ops) {
+		writeHunk(&sb, ops[hunk[0]:hunk[1]])
+	}
+
+	return sb.String()
+}
+
+// Change replaces the text between the Start and End byte offsets of the old text with Text.
+type Change struct {
+	Text       string
+	Start, End int
+}
+
+// Changes returns the changes turning old into new, with one change for each run of consecutive lines
+// that differ between the two.
+func Changes(old, new string) []Change {
+	a, b := splitLines(old), splitLines(new)
+
+	// lines common to the start or end of both are skipped, as the lines in between are usually few
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	offset := 0
+	for _, line := range a[:prefix] {
+		offset += len(line)
+	}
+
+	var (
+		changes []Change
+		current *Change
+	)
+
+	for _, o := range lineOps(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix]) {
+		if o.kind == opEqual {
+			if current != nil {
+				changes = append(changes, *current)
+				current = nil
+			}
+
+			offset += len(o.line)
+
+			continue
+		}
+
+		if current == nil {
+			current = &Change{Start: offset, End: offset}
+		}
+
+		if o.kind == opDelete {
+			offset += len(o.line)
+			current.End = offset
+		} else {
+			current.Text += o.line
+		}
+	}
+
+	if current != nil {
+		changes = append(changes, *current)
+	}
+
+	return changes
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// lineOps computes the edit operations turning a into b from their longest common subsequence of lines.
+func lineOps(a, b []string) []op {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, max(len(a), len(b)))
+	i, j := 0, 0
+
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			ops = append(ops, op{kind: opEqual, line: a[i], oldIndex: i, newIndex: j})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, op{kind: opDelete, line: a[i], oldIndex: i, newIndex: j})
+			i++
+		default:
+			ops = append(ops, op{kind: opInsert, line: b[j], oldIndex: i, newIndex: j})
+			j++
+		}
+	}
+
+	return ops
+}
+
+// hunks returns the start and end index of each hunk in ops, where changes closer than twice the number
+// of context lines are grouped into the same hunk.
+func hunks(ops []op) [][2]int {
+	var result [][2]int
+
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+
+		start := max(0, i-contextLines)
+		end := i
+
+		// extend the hunk for as long as the next change is within reach of the context lines
+		for j := i; j < len(ops); j++ {
+			if ops[j].kind != opEqual {
+				end = j
+			} else if j-end > 2*contextLines {
+				break
+			}
+		}
+
+		end = min(len(ops), end+contextLines+1)
+		result = append(result, [2]int{start, end})
+		i = end - 1
+	}
+
+	return result
+}
+
+func writeHunk(sb *strings.Builder, ops []op) {
+	oldStart, newStart := ops[0].oldIndex, ops[0].newIndex
+	oldCount, newCount := 0, 0
+
+	for _, o := range ops {
+		if o.kind != opInsert {
+			oldCount++
+		}
+
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+
+	for _, o := range ops {
+		sb.WriteByte(byte(o.kind))
+		sb.WriteString(o.line)
+
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats the range of a hunk, where lines are numbered from 1, and where an empty range
+// refers to the line before it.
+func hunkRange(start, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", start)
+	case 1:
+		return fmt.Sprintf("%d", start+1)
+	default:
+		return fmt.Sprintf("%d,%d", start+1, count)
+	}
+}
@@ -0,0 +1,123 @@
+package diff
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nx\nc\n",
+			expected: "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:     "from empty",
+			old:      "",
+			new:      "a\n",
+			expected: "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "missing newline",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: "--- old\n+++ new\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+		{
+			name: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "x\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ny\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+x\n 2\n 3\n 4\n" +
+				"@@ -9,4 +9,4 @@\n 9\n 10\n 11\n-12\n+y\n",
+		},
+		{
+			name: "nearby changes in same hunk",
+			old:  "1\n2\n3\n4\n5\n6\n",
+			new:  "x\n2\n3\n4\n5\ny\n",
+			expected: "--- old\n+++ new\n" +
+				"@@ -1,6 +1,6 @@\n-1\n+x\n 2\n 3\n 4\n 5\n-6\n+y\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := Unified("old", "new", tc.old, tc.new); actual != tc.expected {
+				t.Errorf("expected:\n%q\ngot:\n%q", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestChanges(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		old      string
+		new      string
+		expected []Change
+	}{
+		{
+			name: "equal",
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+		},
+		{
+			name:     "changed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nx\nc\n",
+			expected: []Change{{Start: 2, End: 4, Text: "x\n"}},
+		},
+		{
+			name:     "removed line",
+			old:      "a\nb\nc\n",
+			new:      "a\nc\n",
+			expected: []Change{{Start: 2, End: 4}},
+		},
+		{
+			name:     "added lines",
+			old:      "a\n",
+			new:      "a\nb\nc\n",
+			expected: []Change{{Start: 2, End: 2, Text: "b\nc\n"}},
+		},
+		{
+			name:     "missing newline",
+			old:      "a\nb",
+			new:      "a\nb\n",
+			expected: []Change{{Start: 2, End: 3, Text: "b\n"}},
+		},
+		{
+			name:     "separate changes",
+			old:      "1\n2\n3\n4\n",
+			new:      "x\n2\n3\ny\nz\n",
+			expected: []Change{{Start: 0, End: 2, Text: "x\n"}, {Start: 6, End: 8, Text: "y\nz\n"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if actual := Changes(tc.old, tc.new); !slices.Equal(actual, tc.expected) {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
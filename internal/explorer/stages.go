@@ -12,6 +12,7 @@ import (
 	"github.com/open-policy-agent/opa/v1/util"
 
 	regal_compile "github.com/open-policy-agent/regal/internal/compile"
+	"github.com/open-policy-agent/regal/internal/lsp/types"
 	"github.com/open-policy-agent/regal/internal/parse"
 	"github.com/open-policy-agent/regal/pkg/roast/encoding"
 )
@@ -74,6 +75,44 @@ func CompilerStages(path, rego string, useStrict, useAnno, usePrint bool) []Comp
 	return result
 }
 
+// Explore returns the output of each compiler stage for the provided policy, formatted if requested, and the plan
+// compiled from the policy unless any stage failed.
+func Explore(ctx context.Context, path, rego string, useStrict, useAnno, usePrint, useFormat bool) types.ExplorerResult {
+	compileResults := CompilerStages(path, rego, useStrict, useAnno, usePrint)
+	stages := make([]types.ExplorerStageResult, 0, len(compileResults))
+	hasErrors := false
+
+	for _, cs := range compileResults {
+		stage := types.ExplorerStageResult{
+			Name:  string(cs.Stage),
+			Error: cs.Error != "",
+		}
+
+		if cs.Error != "" {
+			hasErrors = true
+			stage.Output = cs.Error
+		} else {
+			if useFormat {
+				stage.Output = cs.FormattedResult()
+			} else if cs.Result != nil {
+				stage.Output = cs.Result.String()
+			}
+		}
+
+		stages = append(stages, stage)
+	}
+
+	result := types.ExplorerResult{Stages: stages}
+
+	if !hasErrors {
+		if plan, err := Plan(ctx, path, rego, usePrint); err == nil {
+			result.Plan = plan
+		}
+	}
+
+	return result
+}
+
 func getOne(mods map[string]*ast.Module) *ast.Module {
 	for _, m := range mods {
 		return m.Copy()
@@ -0,0 +1,166 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// config holds the values of git config files, keyed by the lowercase section name, the subsection if any,
+// and the lowercase variable name, like "user.name" or "remote.origin.url". Values of later files override
+// those of earlier ones, and include directives are not followed.
+type config map[string]string
+
+// readConfig reads the global config files of the user, followed by the config of the repository.
+func readConfig(commonDir string) (config, error) {
+	var paths []string
+
+	if global := os.Getenv("GIT_CONFIG_GLOBAL"); global != "" {
+		paths = append(paths, global)
+	} else {
+		home, _ := os.UserHomeDir()
+
+		xdg := os.Getenv("XDG_CONFIG_HOME")
+		if xdg == "" && home != "" {
+			xdg = filepath.Join(home, ".config")
+		}
+
+		if xdg != "" {
+			paths = append(paths, filepath.Join(xdg, "git", "config"))
+		}
+
+		if home != "" {
+			paths = append(paths, filepath.Join(home, ".gitconfig"))
+		}
+	}
+
+	cfg := make(config)
+
+	for _, path := range append(paths, filepath.Join(commonDir, "config")) {
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to read git config: %w", err)
+		}
+
+		if err := cfg.parse(string(data)); err != nil {
+			return nil, fmt.Errorf("failed to parse git config %s: %w", path, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+func (c config) parse(data string) error {
+	section := ""
+
+	for n, line := range strings.Split(data, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			header, rest, ok := strings.Cut(line[1:], "]")
+			if !ok || !isComment(rest) {
+				return fmt.Errorf("invalid section header on line %d", n+1)
+			}
+
+			name, sub, hasSub := strings.Cut(header, " ")
+			section = strings.ToLower(name)
+
+			if hasSub {
+				sub, err := unquote(strings.TrimSpace(sub))
+				if err != nil {
+					return fmt.Errorf("invalid section header on line %d: %w", n+1, err)
+				}
+
+				section += "." + sub
+			}
+
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(line, "=")
+		key := section + "." + strings.ToLower(strings.TrimSpace(name))
+
+		if !hasValue {
+			// a variable without a value is a boolean set to true
+			c[key] = "true"
+
+			continue
+		}
+
+		value, err := unquote(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid value on line %d: %w", n+1, err)
+		}
+
+		c[key] = value
+	}
+
+	return nil
+}
+
+// bool returns the value of a boolean variable, or defaultValue if not set.
+func (c config) bool(key string, defaultValue bool) bool {
+	switch strings.ToLower(c[key]) {
+	case "true", "yes", "on", "1":
+		return true
+	case "false", "no", "off", "0":
+		return false
+	default:
+		return defaultValue
+	}
+}
+
+// unquote returns the value with quotes removed, escape sequences resolved, and any trailing comment
+// outside of quotes removed.
+func unquote(value string) (string, error) {
+	var sb strings.Builder
+
+	quoted := false
+
+	for i := 0; i < len(value); i++ {
+		switch c := value[i]; {
+		case c == '"':
+			quoted = !quoted
+		case c == '\\':
+			if i++; i == len(value) {
+				return "", errors.New("unterminated escape sequence")
+			}
+
+			switch value[i] {
+			case 'n':
+				sb.WriteByte('\n')
+			case 't':
+				sb.WriteByte('\t')
+			case 'b':
+				sb.WriteByte('\b')
+			case '"', '\\':
+				sb.WriteByte(value[i])
+			default:
+				return "", fmt.Errorf("invalid escape sequence \\%c", value[i])
+			}
+		case !quoted && (c == '#' || c == ';'):
+			return strings.TrimSpace(sb.String()), nil
+		default:
+			sb.WriteByte(c)
+		}
+	}
+
+	if quoted {
+		return "", errors.New("unterminated quote")
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+func isComment(s string) bool {
+	s = strings.TrimSpace(s)
+
+	return s == "" || s[0] == '#' || s[0] == ';'
+}
@@ -0,0 +1,451 @@
+// Package git provides the few operations on a local git repository needed by regal fix, by reading and
+// writing the files of its .git directory, so that neither the git command nor any remotes are involved.
+// Filters and attributes set for files in git are not applied, except for line ending conversion set by
+// core.autocrlf, and repositories using SHA-256 object ids, reftables, or split or sparse indexes are not
+// supported.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Repository is a git repository on disk, identified by the root of its working tree.
+type Repository struct {
+	config config
+	root   string
+	// the git directory of the working tree, holding HEAD and the index, and the directory shared by all
+	// working trees of the repository, holding objects and refs, which is the same unless a worktree
+	gitDir    string
+	commonDir string
+	packs     []*pack
+}
+
+// Open returns the repository that dir belongs to, or an error if dir is not in a git repository.
+func Open(dir string) (*Repository, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository for %s: %w", dir, err)
+	}
+
+	for root := abs; ; {
+		repo, err := openAt(root)
+		if err != nil || repo != nil {
+			return repo, err
+		}
+
+		parent := filepath.Dir(root)
+		if parent == root {
+			return nil, fmt.Errorf("failed to find git repository for %s: not in a git repository", dir)
+		}
+
+		root = parent
+	}
+}
+
+// openAt returns the repository with its working tree at root, or nil if there is none.
+func openAt(root string) (*Repository, error) {
+	dotGit := filepath.Join(root, ".git")
+
+	info, err := os.Stat(dotGit)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dotGit, err)
+	}
+
+	gitDir := dotGit
+
+	// worktrees and submodules have a .git file pointing to their git directory
+	if !info.IsDir() {
+		data, err := os.ReadFile(dotGit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", dotGit, err)
+		}
+
+		target, ok := strings.CutPrefix(strings.TrimSpace(string(data)), "gitdir: ")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s file, expected gitdir", dotGit)
+		}
+
+		gitDir = resolvePath(root, target)
+	}
+
+	if _, err := os.Stat(filepath.Join(gitDir, "HEAD")); err != nil {
+		return nil, nil //nolint:nilerr
+	}
+
+	commonDir := gitDir
+
+	if data, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		commonDir = resolvePath(gitDir, strings.TrimSpace(string(data)))
+	}
+
+	cfg, err := readConfig(commonDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if format := cfg["extensions.objectformat"]; format != "" && format != "sha1" {
+		return nil, fmt.Errorf("unsupported git repository %s, with object format %s", root, format)
+	}
+
+	if storage := cfg["extensions.refstorage"]; storage != "" && storage != "files" {
+		return nil, fmt.Errorf("unsupported git repository %s, with ref storage %s", root, storage)
+	}
+
+	return &Repository{config: cfg, root: root, gitDir: gitDir, commonDir: commonDir}, nil
+}
+
+// OpenFiles returns the one repository that all of files belong to, or an error if any of them is not in
+// a git repository, or if they belong to more than one, like when a nested repository or submodule is
+// included, as staging and committing may then only be done separately for each.
+func OpenFiles(files ...string) (*Repository, error) {
+	var repo *Repository
+
+	opened := make(map[string]bool, 1)
+
+	for _, file := range files {
+		dir := filepath.Dir(file)
+		if opened[dir] {
+			continue
+		}
+
+		opened[dir] = true
+
+		other, err := Open(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		if repo == nil {
+			repo = other
+		} else if other.root != repo.root {
+			return nil, fmt.Errorf("files belong to more than one git repository: %s and %s", repo.root, other.root)
+		}
+	}
+
+	if repo == nil {
+		return nil, errors.New("no files to find git repository for")
+	}
+
+	return repo, nil
+}
+
+// Root returns the absolute path of the root of the working tree.
+func (r *Repository) Root() string {
+	return r.root
+}
+
+// Uncommitted returns the absolute paths of the files among paths which have changes not yet committed,
+// whether staged or not, including files untracked by git, whether ignored or not.
+func (r *Repository) Uncommitted(paths ...string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	idx, err := r.readIndex()
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := r.headTree()
+	if err != nil {
+		return nil, err
+	}
+
+	var uncommitted []string
+
+	for _, path := range paths {
+		changed, err := r.changed(idx, tree, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if changed {
+			uncommitted = append(uncommitted, path)
+		}
+	}
+
+	return uncommitted, nil
+}
+
+// changed reports whether the file at path differs between the working tree, the index, and the tree of
+// the last commit, where a file missing from all three is unchanged.
+func (r *Repository) changed(idx *index, tree objectID, path string) (bool, error) {
+	rel, err := r.rel(path)
+	if err != nil {
+		return false, err
+	}
+
+	committed, inCommit := treeEntry{}, false
+
+	if !tree.isZero() {
+		if committed, inCommit, err = r.lookupPath(tree, rel); err != nil {
+			return false, err
+		}
+	}
+
+	staged := idx.find(rel, 0)
+	if staged == nil {
+		// a file with conflicts is changed, but has no entry at stage 0
+		if idx.find(rel, 1) != nil || idx.find(rel, 2) != nil || idx.find(rel, 3) != nil {
+			return true, nil
+		}
+
+		_, err := os.Lstat(path)
+		if errors.Is(err, fs.ErrNotExist) {
+			return inCommit, nil
+		}
+
+		return true, err
+	}
+
+	if !inCommit || committed.id != staged.id || committed.mode != staged.mode() {
+		return true, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return true, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	return hashObject("blob", r.clean(data)) != staged.id, nil
+}
+
+// Stage adds the current state of paths to the index. Paths of deleted files stage their removal, which
+// together with the path of a new file with similar contents is how git records a rename.
+func (r *Repository) Stage(paths ...string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	lock, err := lockFile(filepath.Join(r.gitDir, "index"))
+	if err != nil {
+		return err
+	}
+
+	idx, err := r.readIndex()
+	if err != nil {
+		return lock.rollback(err)
+	}
+
+	for _, path := range paths {
+		if err := r.stage(idx, path); err != nil {
+			return lock.rollback(err)
+		}
+	}
+
+	return lock.commit(idx.encode())
+}
+
+func (r *Repository) stage(idx *index, path string) error {
+	rel, err := r.rel(path)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		idx.set(rel, nil)
+
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("failed to stage %s: only regular files can be staged", path)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to stage %s: %w", path, err)
+	}
+
+	id, err := r.writeObject("blob", r.clean(data))
+	if err != nil {
+		return err
+	}
+
+	// without core.filemode, as is the default on Windows, the executable bit is kept as it was in git
+	mode := uint32(modeFile)
+	if existing := idx.find(rel, 0); existing != nil && !r.config.bool("core.filemode", true) {
+		mode = existing.mode()
+	} else if info.Mode()&0o111 != 0 {
+		mode = modeExecutable
+	}
+
+	// only the modification time and size is stored, which is enough for git to tell whether to check the
+	// contents of the file for changes
+	mtime := info.ModTime()
+	sec, nsec := uint32(mtime.Unix()), uint32(mtime.Nanosecond()) //nolint:gosec
+
+	idx.set(rel, &indexEntry{
+		path: rel,
+		stat: [10]uint32{sec, nsec, sec, nsec, 0, 0, mode, 0, 0, uint32(info.Size())}, //nolint:gosec
+		id:   id,
+	})
+
+	return nil
+}
+
+// Commit commits the current state of paths with message, leaving any other changes in the index for
+// later commits. The author and committer are read from the user.name and user.email config, unless
+// provided by the GIT_AUTHOR_NAME, GIT_AUTHOR_EMAIL, GIT_COMMITTER_NAME and GIT_COMMITTER_EMAIL
+// environment variables. Hooks are not run, and commits are not signed.
+func (r *Repository) Commit(message string, paths ...string) error {
+	if len(paths) == 0 {
+		return errors.New("no files to commit")
+	}
+
+	if _, err := os.Stat(filepath.Join(r.gitDir, "MERGE_HEAD")); err == nil {
+		return errors.New("can't commit while a merge is in progress")
+	}
+
+	now := time.Now()
+
+	author, err := r.identity("AUTHOR", now)
+	if err != nil {
+		return err
+	}
+
+	committer, err := r.identity("COMMITTER", now)
+	if err != nil {
+		return err
+	}
+
+	if err = r.Stage(paths...); err != nil {
+		return err
+	}
+
+	idx, err := r.readIndex()
+	if err != nil {
+		return err
+	}
+
+	branch, parent, err := r.head()
+	if err != nil {
+		return err
+	}
+
+	var base objectID
+
+	if !parent.isZero() {
+		if base, err = r.commitTree(parent); err != nil {
+			return err
+		}
+	}
+
+	changes := make(map[string]*treeEntry, len(paths))
+
+	for _, path := range paths {
+		rel, err := r.rel(path)
+		if err != nil {
+			return err
+		}
+
+		changes[rel] = nil
+
+		if staged := idx.find(rel, 0); staged != nil {
+			changes[rel] = &treeEntry{mode: staged.mode(), id: staged.id}
+		}
+	}
+
+	tree, _, err := r.writeTree(base, changes)
+	if err != nil {
+		return err
+	}
+
+	message = strings.TrimRight(message, "\n") + "\n"
+
+	var buf bytes.Buffer
+
+	fmt.Fprintf(&buf, "tree %s\n", tree)
+
+	if !parent.isZero() {
+		fmt.Fprintf(&buf, "parent %s\n", parent)
+	}
+
+	fmt.Fprintf(&buf, "author %s\ncommitter %s\n\n%s", author, committer, message)
+
+	id, err := r.writeObject("commit", buf.Bytes())
+	if err != nil {
+		return err
+	}
+
+	subject, _, _ := strings.Cut(message, "\n")
+
+	reflogMessage := "commit: " + subject
+	if parent.isZero() {
+		reflogMessage = "commit (initial): " + subject
+	}
+
+	return r.updateRef(branch, parent, id, committer, reflogMessage)
+}
+
+// headTree returns the id of the tree of the commit of HEAD, or zero if there are no commits yet.
+func (r *Repository) headTree() (objectID, error) {
+	_, id, err := r.head()
+	if err != nil || id.isZero() {
+		return objectID{}, err
+	}
+
+	return r.commitTree(id)
+}
+
+// identity returns the name, email and time of the author or committer, in the format used in commits.
+func (r *Repository) identity(role string, now time.Time) (string, error) {
+	name, email := os.Getenv("GIT_"+role+"_NAME"), os.Getenv("GIT_"+role+"_EMAIL")
+
+	if name == "" {
+		name = r.config["user.name"]
+	}
+
+	if email == "" {
+		email = r.config["user.email"]
+	}
+
+	if name == "" || email == "" {
+		return "", errors.New("user.name and user.email must be set in the git config to commit")
+	}
+
+	return fmt.Sprintf("%s <%s> %d %s", name, email, now.Unix(), now.Format("-0700")), nil
+}
+
+// clean returns the contents of a file as they are to be stored in git, which only differs from the file
+// when line endings are converted by core.autocrlf.
+func (r *Repository) clean(data []byte) []byte {
+	if strings.EqualFold(r.config["core.autocrlf"], "input") || r.config.bool("core.autocrlf", false) {
+		return bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+
+	return data
+}
+
+// rel returns the slash separated path of path relative to the root of the working tree, as used by git.
+func (r *Repository) rel(path string) (string, error) {
+	rel, err := filepath.Rel(r.root, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is not in the git repository at %s", path, r.root)
+	}
+
+	return filepath.ToSlash(rel), nil
+}
+
+// resolvePath returns path if absolute, or else path relative to dir.
+func resolvePath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	return filepath.Join(dir, path)
+}
@@ -0,0 +1,174 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/regal/internal/test/assert"
+	"github.com/open-policy-agent/regal/internal/test/must"
+	"github.com/open-policy-agent/regal/internal/testutil"
+)
+
+func TestRepository(t *testing.T) {
+	t.Parallel()
+
+	td := newRepository(t, map[string]string{
+		"a.rego":         "package a\n",
+		"b.rego":         "package b\n",
+		"foo/c.rego":     "package c\n",
+		"unrelated.rego": "package unrelated\n",
+	})
+
+	repo := must.Return(Open(filepath.Join(td, "foo")))(t)
+
+	assert.Equal(t, td, repo.Root())
+
+	must.Equal(t, nil, os.WriteFile(filepath.Join(td, "a.rego"), []byte("package a\n\nx := 1\n"), 0o600))
+	must.Equal(t, nil, os.Rename(filepath.Join(td, "foo", "c.rego"), filepath.Join(td, "c.rego")))
+	must.Equal(t, nil, os.WriteFile(filepath.Join(td, "unrelated.rego"), []byte("package changed\n"), 0o600))
+
+	paths := []string{
+		filepath.Join(td, "a.rego"),
+		filepath.Join(td, "b.rego"),
+		filepath.Join(td, "foo", "c.rego"),
+		filepath.Join(td, "c.rego"),
+	}
+
+	uncommitted := must.Return(repo.Uncommitted(paths...))(t)
+
+	assert.SlicesEqual(t, []string{paths[0], paths[2], paths[3]}, uncommitted)
+
+	must.Equal(t, nil, repo.Stage(paths...))
+
+	// the rename is staged just like it would be by git mv
+	assert.Equal(t, "M  a.rego\nR  foo/c.rego -> c.rego\n M unrelated.rego", git(t, td, "status", "--short"))
+
+	must.Equal(t, nil, repo.Commit("Fix files", paths...))
+
+	assert.Equal(t, "Fix files", git(t, td, "log", "-1", "--format=%s"))
+	assert.Equal(t, " M unrelated.rego", git(t, td, "status", "--short"))
+	assert.Equal(t, 0, len(must.Return(repo.Uncommitted(paths...))(t)), "uncommitted files")
+}
+
+func TestRepositoryPacked(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{"a.rego": "package a\n\nx := 1\n", "foo/b.rego": "package b\n"}
+	for i := range 30 {
+		files[fmt.Sprintf("removed%d.rego", i)] = "package removed\n"
+	}
+
+	td := newRepository(t, files)
+
+	// with most files removed, the smaller tree of the second commit is stored as a delta of the first
+	git(t, td, "rm", "--quiet", "removed*.rego")
+	git(t, td, "commit", "--quiet", "--message", "Second commit")
+
+	// objects and refs are then read from packs, and index entries from the version 4 format
+	git(t, td, "gc", "--quiet", "--aggressive")
+	git(t, td, "update-index", "--index-version", "4")
+
+	repo := must.Return(Open(td))(t)
+	paths := []string{filepath.Join(td, "a.rego"), filepath.Join(td, "foo", "b.rego")}
+
+	assert.Equal(t, 0, len(must.Return(repo.Uncommitted(paths...))(t)), "uncommitted files")
+
+	must.Equal(t, nil, os.WriteFile(paths[1], []byte("package b\n\nz := 3\n"), 0o600))
+
+	assert.SlicesEqual(t, []string{paths[1]}, must.Return(repo.Uncommitted(paths...))(t))
+
+	must.Equal(t, nil, repo.Commit("Third commit", paths...))
+
+	assert.Equal(t, "Third commit", git(t, td, "log", "-1", "--format=%s"))
+	assert.Equal(t, "", git(t, td, "status", "--short"))
+	assert.Equal(t, "foo/b.rego", git(t, td, "show", "--format=", "--name-only", "HEAD"))
+
+	git(t, td, "fsck", "--strict", "--no-progress")
+}
+
+func TestRepositoryWorktree(t *testing.T) {
+	t.Parallel()
+
+	td := newRepository(t, map[string]string{"a.rego": "package a\n"})
+	worktree := filepath.Join(td, "worktree")
+
+	git(t, td, "worktree", "add", "--quiet", "-b", "other", worktree)
+
+	repo := must.Return(Open(worktree))(t)
+
+	assert.Equal(t, worktree, repo.Root())
+
+	path := filepath.Join(worktree, "a.rego")
+
+	must.Equal(t, nil, os.WriteFile(path, []byte("package a\n\nx := 1\n"), 0o600))
+	must.Equal(t, nil, repo.Commit("Fix a", path))
+
+	assert.Equal(t, "Fix a", git(t, td, "log", "-1", "--format=%s", "other"))
+	assert.Equal(t, "Initial commit", git(t, td, "log", "-1", "--format=%s"))
+	assert.Equal(t, "", git(t, worktree, "status", "--short"))
+}
+
+func TestOpenOutsideRepository(t *testing.T) {
+	t.Parallel()
+
+	if _, err := Open(t.TempDir()); err == nil {
+		t.Fatal("expected error opening directory outside of git repository")
+	}
+}
+
+func TestOpenFiles(t *testing.T) {
+	t.Parallel()
+
+	td := newRepository(t, map[string]string{"a.rego": "package a\n", "foo/b.rego": "package b\n"})
+
+	repo := must.Return(OpenFiles(filepath.Join(td, "a.rego"), filepath.Join(td, "foo", "b.rego")))(t)
+
+	assert.Equal(t, td, repo.Root())
+
+	nested := filepath.Join(td, "nested")
+
+	must.MkdirAll(t, nested)
+	git(t, nested, "init", "--quiet")
+
+	_, err := OpenFiles(filepath.Join(td, "a.rego"), filepath.Join(nested, "c.rego"))
+	if err == nil || !strings.Contains(err.Error(), "more than one git repository") {
+		t.Fatalf("expected error for files in more than one repository, got %v", err)
+	}
+}
+
+func newRepository(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	td := must.Return(filepath.EvalSymlinks(testutil.TempDirectoryOf(t, files)))(t)
+
+	git(t, td, "init", "--quiet")
+	git(t, td, "config", "user.name", "Regal")
+	git(t, td, "config", "user.email", "regal@example.com")
+	git(t, td, "add", ".")
+	git(t, td, "commit", "--quiet", "--message", "Initial commit")
+
+	return td
+}
+
+// git runs the git command, which is only used to create and inspect the repositories of the tests.
+func git(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, out)
+	}
+
+	return strings.TrimSuffix(strings.ReplaceAll(string(out), "\r\n", "\n"), "\n")
+}
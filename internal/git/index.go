@@ -0,0 +1,234 @@
+package git
+
+import (
+	"bytes"
+	"cmp"
+	//nolint:gosec
+	"crypto/sha1"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+const (
+	indexEntryExtended  = 0x4000
+	indexEntryStageMask = 0x3000
+	indexEntryNameMask  = 0xfff
+)
+
+// indexEntry is an entry of the index, which is the state of a file staged for the next commit.
+type indexEntry struct {
+	path string
+	// ctime, ctime nanoseconds, mtime, mtime nanoseconds, dev, ino, mode, uid, gid and size, as stored
+	// in the index to tell whether files have changed without reading them
+	stat     [10]uint32
+	id       objectID
+	flags    uint16
+	extFlags uint16
+}
+
+func (e *indexEntry) mode() uint32 {
+	return e.stat[6]
+}
+
+func (e *indexEntry) stage() uint16 {
+	return (e.flags & indexEntryStageMask) >> 12
+}
+
+type index struct {
+	entries []indexEntry
+}
+
+// readIndex reads the index of the repository, or returns an empty index if there is none yet. Versions 2
+// to 4 of the index format are supported, along with any of the optional extensions, which are however
+// dropped when the index is written.
+func (r *Repository) readIndex() (*index, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "index"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return &index{}, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read index: %w", err)
+	}
+
+	if len(data) < 12+sha1.Size || string(data[:4]) != "DIRC" {
+		return nil, errors.New("invalid index")
+	}
+
+	body := data[:len(data)-sha1.Size]
+	if sum := sha1.Sum(body); !bytes.Equal(sum[:], data[len(body):]) { //nolint:gosec
+		return nil, errors.New("invalid index, checksum does not match")
+	}
+
+	version := binary.BigEndian.Uint32(data[4:])
+	if version < 2 || version > 4 {
+		return nil, fmt.Errorf("unsupported index version %d", version)
+	}
+
+	count := binary.BigEndian.Uint32(data[8:])
+	idx := &index{entries: make([]indexEntry, 0, count)}
+	pos := 12
+	previous := ""
+
+	for range count {
+		start := pos
+
+		if pos+62 > len(body) {
+			return nil, errors.New("invalid index, entries are truncated")
+		}
+
+		var entry indexEntry
+
+		for i := range entry.stat {
+			entry.stat[i] = binary.BigEndian.Uint32(body[pos+4*i:])
+		}
+
+		copy(entry.id[:], body[pos+40:])
+		entry.flags = binary.BigEndian.Uint16(body[pos+60:])
+		pos += 62
+
+		if entry.flags&indexEntryExtended != 0 {
+			if version < 3 || pos+2 > len(body) {
+				return nil, errors.New("invalid index, unexpected extended flags")
+			}
+
+			entry.extFlags = binary.BigEndian.Uint16(body[pos:])
+			pos += 2
+		}
+
+		// version 4 stores paths as the number of bytes to remove from the end of the previous path,
+		// followed by the bytes to append to it, while earlier versions pad each entry with NUL bytes
+		var strip, n int
+		if version == 4 {
+			if strip, n = varint(body[pos:]); n == 0 || strip > len(previous) {
+				return nil, errors.New("invalid index, bad path prefix")
+			}
+		}
+
+		nul := bytes.IndexByte(body[pos+n:], 0)
+		if nul == -1 {
+			return nil, errors.New("invalid index, path is not terminated")
+		}
+
+		if version == 4 {
+			entry.path = previous[:len(previous)-strip] + string(body[pos+n:pos+n+nul])
+			pos += n + nul + 1
+		} else {
+			entry.path = string(body[pos : pos+nul])
+			pos = start + (pos-start+nul+8)&^7
+		}
+
+		previous = entry.path
+		idx.entries = append(idx.entries, entry)
+	}
+
+	for pos+8 <= len(body) {
+		signature, size := string(body[pos:pos+4]), int(binary.BigEndian.Uint32(body[pos+4:]))
+
+		// extensions with a signature starting with an uppercase letter are optional, and may be ignored,
+		// while others, like those of split and sparse indexes, change how the entries are to be read
+		if signature[0] < 'A' || signature[0] > 'Z' {
+			return nil, fmt.Errorf("unsupported index extension %q", signature)
+		}
+
+		pos += 8 + size
+	}
+
+	if pos != len(body) {
+		return nil, errors.New("invalid index, bad extension")
+	}
+
+	return idx, nil
+}
+
+// encode returns the index in the version 2 format, or in version 3 if any entry has extended flags.
+func (idx *index) encode() []byte {
+	version := uint32(2)
+	if slices.ContainsFunc(idx.entries, func(e indexEntry) bool { return e.extFlags != 0 }) {
+		version = 3
+	}
+
+	buf := bytes.NewBufferString("DIRC")
+	buf.Write(binary.BigEndian.AppendUint32(nil, version))
+	buf.Write(binary.BigEndian.AppendUint32(nil, uint32(len(idx.entries)))) //nolint:gosec
+
+	for _, entry := range idx.entries {
+		start := buf.Len()
+
+		for _, v := range entry.stat {
+			buf.Write(binary.BigEndian.AppendUint32(nil, v))
+		}
+
+		flags := entry.flags&^(indexEntryNameMask|indexEntryExtended) | uint16(min(len(entry.path), indexEntryNameMask))
+		if entry.extFlags != 0 {
+			flags |= indexEntryExtended
+		}
+
+		buf.Write(entry.id[:])
+		buf.Write(binary.BigEndian.AppendUint16(nil, flags))
+
+		if entry.extFlags != 0 {
+			buf.Write(binary.BigEndian.AppendUint16(nil, entry.extFlags))
+		}
+
+		buf.WriteString(entry.path)
+
+		// entries are padded with 1 to 8 NUL bytes to a multiple of 8 bytes
+		length := buf.Len() - start
+		buf.Write(make([]byte, (length+8)&^7-length))
+	}
+
+	sum := sha1.Sum(buf.Bytes()) //nolint:gosec
+	buf.Write(sum[:])
+
+	return buf.Bytes()
+}
+
+// find returns the entry for path at stage, which is 0 for files not in conflict.
+func (idx *index) find(path string, stage uint16) *indexEntry {
+	for i := range idx.entries {
+		if idx.entries[i].path == path && idx.entries[i].stage() == stage {
+			return &idx.entries[i]
+		}
+	}
+
+	return nil
+}
+
+// set replaces all entries for path, including those of any conflict, with entry, or removes them if entry
+// is nil.
+func (idx *index) set(path string, entry *indexEntry) {
+	idx.entries = slices.DeleteFunc(idx.entries, func(e indexEntry) bool { return e.path == path })
+
+	if entry != nil {
+		idx.entries = append(idx.entries, *entry)
+	}
+
+	slices.SortFunc(idx.entries, func(a, b indexEntry) int {
+		return cmp.Or(cmp.Compare(a.path, b.path), cmp.Compare(a.stage(), b.stage()))
+	})
+}
+
+// varint decodes the variable length integers used for paths in version 4 indexes, where each byte holds
+// 7 bits, and a set high bit means another byte follows, with the value so far incremented by one. The
+// number of bytes read is returned along with the value, or 0 if the input ended first.
+func varint(data []byte) (int, int) {
+	value := 0
+
+	for i, c := range data {
+		if i > 0 {
+			value = (value + 1) << 7
+		}
+
+		value |= int(c & 0x7f)
+
+		if c&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+
+	return 0, 0
+}
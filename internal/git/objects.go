@@ -0,0 +1,668 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"compress/zlib"
+	//nolint:gosec
+	"crypto/sha1"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	modeTree       = 0o40000
+	modeFile       = 0o100644
+	modeExecutable = 0o100755
+)
+
+var errInvalidDelta = errors.New("invalid delta in pack")
+
+// packTypes are the types of objects stored in packs, by the number used for them in the pack format.
+// Numbers 6 and 7 are used for objects stored as deltas against other objects.
+var packTypes = map[byte]string{1: "commit", 2: "tree", 3: "blob", 4: "tag"}
+
+// objectID is the SHA-1 hash identifying an object in the object database.
+type objectID [sha1.Size]byte
+
+func (id objectID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+func (id objectID) isZero() bool {
+	return id == objectID{}
+}
+
+func parseObjectID(s string) (objectID, error) {
+	var id objectID
+
+	if len(s) != hex.EncodedLen(len(id)) {
+		return id, fmt.Errorf("invalid object id %q", s)
+	}
+
+	if _, err := hex.Decode(id[:], []byte(s)); err != nil {
+		return id, fmt.Errorf("invalid object id %q: %w", s, err)
+	}
+
+	return id, nil
+}
+
+func hashObject(kind string, data []byte) objectID {
+	hash := sha1.New() //nolint:gosec
+
+	fmt.Fprintf(hash, "%s %d\x00", kind, len(data))
+	hash.Write(data)
+
+	return objectID(hash.Sum(nil))
+}
+
+type treeEntry struct {
+	name string
+	mode uint32
+	id   objectID
+}
+
+// pack is a packfile of objects, along with the contents of its index, which maps the id of each object in
+// the pack to its offset in the packfile.
+type pack struct {
+	path    string
+	ids     []byte
+	offsets []byte
+	large   []byte
+	fanout  [256]uint32
+}
+
+// readObject returns the type and contents of the object with id, whether stored as a loose object or in a
+// pack, in the object directory of the repository or any of its alternates.
+func (r *Repository) readObject(id objectID) (string, []byte, error) {
+	dirs, err := r.objectDirs()
+	if err != nil {
+		return "", nil, err
+	}
+
+	hexID := id.String()
+
+	for _, dir := range dirs {
+		data, err := os.ReadFile(filepath.Join(dir, hexID[:2], hexID[2:]))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return "", nil, fmt.Errorf("failed to read object %s: %w", hexID, err)
+		}
+
+		return parseLooseObject(hexID, data)
+	}
+
+	packs, err := r.loadPacks()
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, p := range packs {
+		if offset, ok := p.offset(id); ok {
+			return r.readPacked(p, offset)
+		}
+	}
+
+	return "", nil, fmt.Errorf("object %s not found", hexID)
+}
+
+func parseLooseObject(hexID string, data []byte) (string, []byte, error) {
+	zr, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read object %s: %w", hexID, err)
+	}
+
+	defer zr.Close()
+
+	contents, err := io.ReadAll(zr)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read object %s: %w", hexID, err)
+	}
+
+	header, body, ok := bytes.Cut(contents, []byte{0})
+	kind, size, ok2 := strings.Cut(string(header), " ")
+
+	if !ok || !ok2 || size != strconv.Itoa(len(body)) {
+		return "", nil, fmt.Errorf("invalid object %s", hexID)
+	}
+
+	return kind, body, nil
+}
+
+// readTyped reads the object with id, and returns an error if it's not of the expected type.
+func (r *Repository) readTyped(id objectID, expected string) ([]byte, error) {
+	kind, data, err := r.readObject(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if kind != expected {
+		return nil, fmt.Errorf("object %s is a %s, expected a %s", id, kind, expected)
+	}
+
+	return data, nil
+}
+
+// writeObject writes the object as a loose object, unless it already exists, and returns its id.
+func (r *Repository) writeObject(kind string, data []byte) (objectID, error) {
+	id := hashObject(kind, data)
+	hexID := id.String()
+	dir := filepath.Join(r.commonDir, "objects", hexID[:2])
+	path := filepath.Join(dir, hexID[2:])
+
+	if _, err := os.Stat(path); err == nil {
+		return id, nil
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return id, fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(dir, "tmp_obj_")
+	if err != nil {
+		return id, fmt.Errorf("failed to write object %s: %w", hexID, err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	zw := zlib.NewWriter(tmp)
+	fmt.Fprintf(zw, "%s %d\x00", kind, len(data))
+	_, err = zw.Write(data)
+
+	if err = errors.Join(err, zw.Close(), tmp.Close(), os.Chmod(tmp.Name(), 0o444)); err != nil {
+		return id, fmt.Errorf("failed to write object %s: %w", hexID, err)
+	}
+
+	// another process writing the same object at the same time is fine, as the contents are the same
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		if _, statErr := os.Stat(path); statErr != nil {
+			return id, fmt.Errorf("failed to write object %s: %w", hexID, err)
+		}
+	}
+
+	return id, nil
+}
+
+// commitTree returns the id of the tree of the commit with id.
+func (r *Repository) commitTree(id objectID) (objectID, error) {
+	data, err := r.readTyped(id, "commit")
+	if err != nil {
+		return objectID{}, err
+	}
+
+	line, _, _ := bytes.Cut(data, []byte{'\n'})
+
+	tree, ok := strings.CutPrefix(string(line), "tree ")
+	if !ok {
+		return objectID{}, fmt.Errorf("invalid commit %s", id)
+	}
+
+	return parseObjectID(tree)
+}
+
+func (r *Repository) readTree(id objectID) ([]treeEntry, error) {
+	data, err := r.readTyped(id, "tree")
+	if err != nil {
+		return nil, err
+	}
+
+	var entries []treeEntry
+
+	// each entry is "<octal mode> <name>\0<binary id>"
+	for len(data) > 0 {
+		sp, nul := bytes.IndexByte(data, ' '), bytes.IndexByte(data, 0)
+		if sp < 0 || nul < sp || len(data) < nul+1+len(objectID{}) {
+			return nil, fmt.Errorf("invalid tree %s", id)
+		}
+
+		mode, err := strconv.ParseUint(string(data[:sp]), 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tree %s: %w", id, err)
+		}
+
+		entry := treeEntry{name: string(data[sp+1 : nul]), mode: uint32(mode)}
+		copy(entry.id[:], data[nul+1:])
+
+		entries = append(entries, entry)
+		data = data[nul+1+len(entry.id):]
+	}
+
+	return entries, nil
+}
+
+// lookupPath returns the entry at the slash separated path in the tree with id.
+func (r *Repository) lookupPath(id objectID, path string) (treeEntry, bool, error) {
+	entry := treeEntry{mode: modeTree, id: id}
+
+	for name := range strings.SplitSeq(path, "/") {
+		if entry.mode != modeTree {
+			return treeEntry{}, false, nil
+		}
+
+		entries, err := r.readTree(entry.id)
+		if err != nil {
+			return treeEntry{}, false, err
+		}
+
+		i := slices.IndexFunc(entries, func(e treeEntry) bool { return e.name == name })
+		if i == -1 {
+			return treeEntry{}, false, nil
+		}
+
+		entry = entries[i]
+	}
+
+	return entry, true, nil
+}
+
+// writeTree writes the tree resulting from applying changes to the tree with id base, which is zero when
+// there is no tree to start from. Changes are keyed by slash separated paths relative to the tree, where
+// a nil entry removes the path. Trees left empty are removed from their parent, and the second return
+// value reports whether the tree written has any entries.
+func (r *Repository) writeTree(base objectID, changes map[string]*treeEntry) (objectID, bool, error) {
+	entries := make(map[string]treeEntry)
+
+	if !base.isZero() {
+		existing, err := r.readTree(base)
+		if err != nil {
+			return objectID{}, false, err
+		}
+
+		for _, entry := range existing {
+			entries[entry.name] = entry
+		}
+	}
+
+	nested := make(map[string]map[string]*treeEntry)
+
+	for path, change := range changes {
+		name, rest, isNested := strings.Cut(path, "/")
+
+		switch {
+		case isNested && nested[name] == nil:
+			nested[name] = map[string]*treeEntry{rest: change}
+		case isNested:
+			nested[name][rest] = change
+		case change == nil:
+			delete(entries, name)
+		default:
+			entries[name] = treeEntry{name: name, mode: change.mode, id: change.id}
+		}
+	}
+
+	for name, subChanges := range nested {
+		var subBase objectID
+		if entry, ok := entries[name]; ok && entry.mode == modeTree {
+			subBase = entry.id
+		}
+
+		id, nonEmpty, err := r.writeTree(subBase, subChanges)
+		if err != nil {
+			return objectID{}, false, err
+		}
+
+		if nonEmpty {
+			entries[name] = treeEntry{name: name, mode: modeTree, id: id}
+		} else {
+			delete(entries, name)
+		}
+	}
+
+	// entries are sorted by name, where the names of trees are compared as if ending with a slash
+	sorted := slices.SortedFunc(func(yield func(treeEntry) bool) {
+		for _, entry := range entries {
+			if !yield(entry) {
+				return
+			}
+		}
+	}, func(a, b treeEntry) int {
+		return cmp.Compare(a.sortName(), b.sortName())
+	})
+
+	var buf bytes.Buffer
+
+	for _, entry := range sorted {
+		fmt.Fprintf(&buf, "%o %s\x00", entry.mode, entry.name)
+		buf.Write(entry.id[:])
+	}
+
+	id, err := r.writeObject("tree", buf.Bytes())
+
+	return id, len(sorted) > 0, err
+}
+
+func (e treeEntry) sortName() string {
+	if e.mode == modeTree {
+		return e.name + "/"
+	}
+
+	return e.name
+}
+
+// objectDirs returns the object directory of the repository, followed by those of its alternates.
+func (r *Repository) objectDirs() ([]string, error) {
+	dir := filepath.Join(r.commonDir, "objects")
+	dirs := []string{dir}
+
+	data, err := os.ReadFile(filepath.Join(dir, "info", "alternates"))
+	if errors.Is(err, os.ErrNotExist) {
+		return dirs, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read alternates: %w", err)
+	}
+
+	for line := range strings.Lines(string(data)) {
+		if line = strings.TrimSpace(line); line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if !filepath.IsAbs(line) {
+			line = filepath.Join(dir, line)
+		}
+
+		dirs = append(dirs, line)
+	}
+
+	return dirs, nil
+}
+
+func (r *Repository) loadPacks() ([]*pack, error) {
+	if r.packs != nil {
+		return r.packs, nil
+	}
+
+	dirs, err := r.objectDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	packs := make([]*pack, 0)
+
+	for _, dir := range dirs {
+		indexes, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to list packs: %w", err)
+		}
+
+		for _, path := range indexes {
+			p, err := readPackIndex(path)
+			if err != nil {
+				return nil, err
+			}
+
+			packs = append(packs, p)
+		}
+	}
+
+	r.packs = packs
+
+	return packs, nil
+}
+
+// readPackIndex reads a version 2 pack index, which has a table of the number of objects with ids starting
+// with each byte or less, followed by tables of the ids, their checksums, and their offsets in the pack,
+// where offsets too large for 31 bits are stored in a final table of 64 bit offsets.
+func readPackIndex(path string) (*pack, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pack index: %w", err)
+	}
+
+	const headerSize = 8 + 256*4
+
+	if len(data) < headerSize+2*sha1.Size || !bytes.Equal(data[:4], []byte("\xfftOc")) ||
+		binary.BigEndian.Uint32(data[4:]) != 2 {
+		return nil, fmt.Errorf("unsupported pack index %s, only version 2 is supported", path)
+	}
+
+	p := &pack{path: strings.TrimSuffix(path, ".idx") + ".pack"}
+
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(data[8+4*i:])
+	}
+
+	n := int(p.fanout[255])
+	end := len(data) - 2*sha1.Size
+
+	if headerSize+n*(sha1.Size+8) > end {
+		return nil, fmt.Errorf("invalid pack index %s", path)
+	}
+
+	pos := headerSize
+	p.ids, pos = data[pos:pos+n*sha1.Size], pos+n*sha1.Size
+	p.offsets, pos = data[pos+n*4:pos+n*8], pos+n*8
+	p.large = data[pos:end]
+
+	return p, nil
+}
+
+func (p *pack) offset(id objectID) (int64, bool) {
+	lo, hi := 0, int(p.fanout[id[0]])
+	if id[0] > 0 {
+		lo = int(p.fanout[id[0]-1])
+	}
+
+	i, found := sort.Find(hi-lo, func(i int) int {
+		return bytes.Compare(id[:], p.ids[(lo+i)*sha1.Size:(lo+i+1)*sha1.Size])
+	})
+	if !found {
+		return 0, false
+	}
+
+	offset := binary.BigEndian.Uint32(p.offsets[(lo+i)*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+
+	large := int(offset&0x7fffffff) * 8
+	if large+8 > len(p.large) {
+		return 0, false
+	}
+
+	return int64(binary.BigEndian.Uint64(p.large[large:])), true //nolint:gosec
+}
+
+func (r *Repository) readPacked(p *pack, offset int64) (string, []byte, error) {
+	f, err := os.Open(p.path)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to open pack: %w", err)
+	}
+
+	defer f.Close()
+
+	kind, data, err := r.readPackedAt(f, offset)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read object from %s: %w", p.path, err)
+	}
+
+	return kind, data, nil
+}
+
+// readPackedAt reads the object at offset in the pack, where each object starts with its type and size,
+// followed by the zlib compressed contents, or for deltas, by a reference to the base object followed by
+// the compressed delta to apply to it.
+func (r *Repository) readPackedAt(f *os.File, offset int64) (string, []byte, error) {
+	br := bufio.NewReader(io.NewSectionReader(f, offset, math.MaxInt64-offset))
+
+	c, err := br.ReadByte()
+	if err != nil {
+		return "", nil, err
+	}
+
+	kind, size := (c>>4)&7, uint64(c&15)
+
+	for shift := 4; c&0x80 != 0; shift += 7 {
+		if c, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+
+		size |= uint64(c&0x7f) << shift
+	}
+
+	var baseKind string
+	var base []byte
+
+	switch kind {
+	case 6: // delta against the object at a relative offset before this one
+		if c, err = br.ReadByte(); err != nil {
+			return "", nil, err
+		}
+
+		relative := int64(c & 0x7f)
+
+		for c&0x80 != 0 {
+			if c, err = br.ReadByte(); err != nil {
+				return "", nil, err
+			}
+
+			relative = (relative+1)<<7 | int64(c&0x7f)
+		}
+
+		if relative <= 0 || relative > offset {
+			return "", nil, errInvalidDelta
+		}
+
+		baseKind, base, err = r.readPackedAt(f, offset-relative)
+	case 7: // delta against the object with id
+		var baseID objectID
+		if _, err = io.ReadFull(br, baseID[:]); err != nil {
+			return "", nil, err
+		}
+
+		baseKind, base, err = r.readObject(baseID)
+	default:
+		if packTypes[kind] == "" {
+			return "", nil, fmt.Errorf("invalid object type %d in pack", kind)
+		}
+
+		data, err := inflate(br, size)
+
+		return packTypes[kind], data, err
+	}
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	delta, err := inflate(br, size)
+	if err != nil {
+		return "", nil, err
+	}
+
+	data, err := applyDelta(base, delta)
+
+	return baseKind, data, err
+}
+
+func inflate(r io.Reader, size uint64) ([]byte, error) {
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	defer zr.Close()
+
+	data, err := io.ReadAll(io.LimitReader(zr, int64(min(size+1, math.MaxInt64)))) //nolint:gosec
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(len(data)) != size {
+		return nil, errors.New("object size in pack does not match its contents")
+	}
+
+	return data, nil
+}
+
+// applyDelta applies a delta to base, where the delta starts with the sizes of the base and the result,
+// followed by instructions to either copy a range of bytes from the base, or to insert new bytes.
+func applyDelta(base, delta []byte) ([]byte, error) {
+	baseSize, delta := deltaSize(delta)
+	resultSize, delta := deltaSize(delta)
+
+	if baseSize != uint64(len(base)) {
+		return nil, errInvalidDelta
+	}
+
+	result := make([]byte, 0, min(resultSize, uint64(len(base)+len(delta))*2))
+
+	for len(delta) > 0 {
+		op := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case op&0x80 != 0:
+			// the lower 4 bits tell which bytes of the offset follow, and the next 3 bits those of the size
+			var offset, size uint64
+
+			for i := range 7 {
+				if op&(1<<i) == 0 {
+					continue
+				}
+
+				if len(delta) == 0 {
+					return nil, errInvalidDelta
+				}
+
+				if i < 4 {
+					offset |= uint64(delta[0]) << (8 * i)
+				} else {
+					size |= uint64(delta[0]) << (8 * (i - 4))
+				}
+
+				delta = delta[1:]
+			}
+
+			if size == 0 {
+				size = 0x10000
+			}
+
+			if offset+size > uint64(len(base)) {
+				return nil, errInvalidDelta
+			}
+
+			result = append(result, base[offset:offset+size]...)
+		case op != 0:
+			if int(op) > len(delta) {
+				return nil, errInvalidDelta
+			}
+
+			result = append(result, delta[:op]...)
+			delta = delta[op:]
+		default:
+			return nil, errInvalidDelta
+		}
+	}
+
+	if uint64(len(result)) != resultSize {
+		return nil, errInvalidDelta
+	}
+
+	return result, nil
+}
+
+func deltaSize(delta []byte) (uint64, []byte) {
+	var size uint64
+
+	for i, c := range delta {
+		size |= uint64(c&0x7f) << (7 * i)
+
+		if c&0x80 == 0 {
+			return size, delta[i+1:]
+		}
+	}
+
+	return math.MaxUint64, nil
+}
@@ -0,0 +1,206 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxSymrefDepth is the number of symbolic refs followed before giving up, as git does.
+const maxSymrefDepth = 5
+
+// head returns the name of the branch that HEAD points to, or an empty string if HEAD is detached, along
+// with the id of the commit of HEAD, which is zero for a branch without commits yet.
+func (r *Repository) head() (string, objectID, error) {
+	data, err := os.ReadFile(filepath.Join(r.gitDir, "HEAD"))
+	if err != nil {
+		return "", objectID{}, fmt.Errorf("failed to read HEAD: %w", err)
+	}
+
+	value := strings.TrimSpace(string(data))
+
+	if ref, ok := strings.CutPrefix(value, "ref: "); ok {
+		id, err := r.resolveRef(ref)
+
+		return ref, id, err
+	}
+
+	id, err := parseObjectID(value)
+
+	return "", id, err
+}
+
+// resolveRef returns the id that the named ref points to, following symbolic refs, or a zero id if the ref
+// doesn't exist. Refs are read from their own file if there is one, or else from the packed refs.
+func (r *Repository) resolveRef(name string) (objectID, error) {
+	for range maxSymrefDepth {
+		data, err := os.ReadFile(r.refPath(name))
+		if errors.Is(err, fs.ErrNotExist) {
+			return r.packedRef(name)
+		} else if err != nil {
+			return objectID{}, fmt.Errorf("failed to read ref %s: %w", name, err)
+		}
+
+		value := strings.TrimSpace(string(data))
+
+		target, ok := strings.CutPrefix(value, "ref: ")
+		if !ok {
+			return parseObjectID(value)
+		}
+
+		name = target
+	}
+
+	return objectID{}, fmt.Errorf("too many levels of symbolic refs for %s", name)
+}
+
+func (r *Repository) packedRef(name string) (objectID, error) {
+	data, err := os.ReadFile(filepath.Join(r.commonDir, "packed-refs"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return objectID{}, nil
+	} else if err != nil {
+		return objectID{}, fmt.Errorf("failed to read packed refs: %w", err)
+	}
+
+	// lines are "<id> <name>", except for comments, and peeled tags starting with ^
+	for line := range strings.Lines(string(data)) {
+		id, ref, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if ok && ref == name && !strings.HasPrefix(id, "#") && !strings.HasPrefix(id, "^") {
+			return parseObjectID(id)
+		}
+	}
+
+	return objectID{}, nil
+}
+
+// refPath returns the path of the file of the named ref, where refs under refs/ are shared by all worktrees
+// of a repository, while others, like HEAD, belong to each worktree.
+func (r *Repository) refPath(name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return filepath.Join(r.commonDir, filepath.FromSlash(name))
+	}
+
+	return filepath.Join(r.gitDir, filepath.FromSlash(name))
+}
+
+// logPath returns the path of the reflog of the named ref, which is kept where the ref itself is.
+func (r *Repository) logPath(name string) string {
+	if strings.HasPrefix(name, "refs/") {
+		return filepath.Join(r.commonDir, "logs", filepath.FromSlash(name))
+	}
+
+	return filepath.Join(r.gitDir, "logs", filepath.FromSlash(name))
+}
+
+// updateRef points the named ref at id, provided that it still points at old, and records the update in
+// the reflogs of the ref and HEAD. An empty name updates a detached HEAD.
+func (r *Repository) updateRef(name string, old, id objectID, identity, message string) error {
+	if name == "" {
+		name = "HEAD"
+	}
+
+	path := r.refPath(name)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create directory for ref %s: %w", name, err)
+	}
+
+	lock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+
+	current, err := r.resolveRef(name)
+	if err != nil {
+		return lock.rollback(err)
+	}
+
+	if current != old {
+		return lock.rollback(fmt.Errorf("ref %s was updated by another process", name))
+	}
+
+	if err := lock.commit([]byte(id.String() + "\n")); err != nil {
+		return err
+	}
+
+	if !r.config.bool("core.logallrefupdates", true) {
+		return nil
+	}
+
+	entry := fmt.Sprintf("%s %s %s\t%s\n", old, id, identity, message)
+
+	// the ref updated is always the one HEAD points to, so the update is recorded for HEAD as well
+	logs := []string{name}
+	if name != "HEAD" {
+		logs = append(logs, "HEAD")
+	}
+
+	for _, log := range logs {
+		if err := appendLog(r.logPath(log), entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appendLog(path, entry string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create reflog directory: %w", err)
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open reflog: %w", err)
+	}
+
+	_, err = f.WriteString(entry)
+
+	if err = errors.Join(err, f.Close()); err != nil {
+		return fmt.Errorf("failed to write reflog: %w", err)
+	}
+
+	return nil
+}
+
+// lock is a lock on a file in the git directory, held by creating the file with a .lock suffix, which is
+// then renamed to replace the file once written, as git itself does.
+type lock struct {
+	file *os.File
+	path string
+}
+
+func lockFile(path string) (*lock, error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o644)
+	if errors.Is(err, fs.ErrExist) {
+		return nil, fmt.Errorf("%s.lock exists, as another git process is running, or one crashed and left it "+
+			"behind, in which case it must be removed", path)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+	}
+
+	return &lock{file: f, path: path}, nil
+}
+
+func (l *lock) commit(data []byte) error {
+	_, err := l.file.Write(data)
+	if err = errors.Join(err, l.file.Close()); err == nil {
+		err = os.Rename(l.file.Name(), l.path)
+	}
+
+	if err != nil {
+		_ = os.Remove(l.file.Name())
+
+		return fmt.Errorf("failed to write %s: %w", l.path, err)
+	}
+
+	return nil
+}
+
+// rollback releases the lock without changing the file, and returns err for convenience.
+func (l *lock) rollback(err error) error {
+	return errors.Join(err, l.file.Close(), os.Remove(l.file.Name()))
+}
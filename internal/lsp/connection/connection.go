@@ -72,7 +72,17 @@ func New(ctx context.Context, rwc io.ReadWriteCloser, handler HandlerFunc) *json
 }
 
 func NewWithOptions(ctx context.Context, rwc io.ReadWriteCloser, handler HandlerFunc, opts *Options) *jsonrpc2.Conn {
-	stream := jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{})
+	return NewStreamWithOptions(ctx, jsonrpc2.NewBufferedStream(rwc, jsonrpc2.VSCodeObjectCodec{}), handler, opts)
+}
+
+// NewStreamWithOptions is like NewWithOptions, but for an already established object stream, like
+// one reading and writing messages over a websocket connection.
+func NewStreamWithOptions(
+	ctx context.Context,
+	stream jsonrpc2.ObjectStream,
+	handler HandlerFunc,
+	opts *Options,
+) *jsonrpc2.Conn {
 	asynch := jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(handler))
 
 	return jsonrpc2.NewConn(ctx, stream, asynch, logMessages(opts.LoggingConfig))
@@ -575,9 +575,11 @@ func (l *LanguageServer) StartWorkspaceStateWorker(ctx context.Context) {
 	})
 }
 
-// StartWebServer starts the web server that serves explorer.
+// StartWebServer starts the web server that serves explorer, which runs until ctx is done.
 func (l *LanguageServer) StartWebServer(ctx context.Context) {
-	l.webServer.Start(ctx)
+	l.workersWg.Go(func() {
+		l.webServer.Start(ctx)
+	})
 }
 
 // StartTemplateWorker runs the process of the server that templates newly
@@ -358,46 +358,9 @@ func (l *LanguageServer) handleExplorerCommand(ctx context.Context, params types
 
 	path := workspace.RelativePath(args.Target)
 
-	compileResults := explorer.CompilerStages(
-		path,
-		contents,
-		args.Strict,
-		args.Annotations,
-		args.Print,
-	)
-
 	// For VSCode, use the notification approach
 	if l.Workspace().Client().Identifier == clients.IdentifierVSCode {
-		stages := make([]types.ExplorerStageResult, 0, len(compileResults))
-		hasErrors := false
-
-		for _, cs := range compileResults {
-			stage := types.ExplorerStageResult{
-				Name:  string(cs.Stage),
-				Error: cs.Error != "",
-			}
-
-			if cs.Error != "" {
-				hasErrors = true
-				stage.Output = cs.Error
-			} else {
-				if args.Format {
-					stage.Output = cs.FormattedResult()
-				} else if cs.Result != nil {
-					stage.Output = cs.Result.String()
-				}
-			}
-
-			stages = append(stages, stage)
-		}
-
-		responseParams := types.ExplorerResult{Stages: stages}
-
-		if !hasErrors {
-			if plan, err := explorer.Plan(ctx, path, contents, args.Print); err == nil {
-				responseParams.Plan = plan
-			}
-		}
+		responseParams := explorer.Explore(ctx, path, contents, args.Strict, args.Annotations, args.Print, args.Format)
 
 		if err := l.conn.Notify(ctx, "regal/showExplorerResult", responseParams); err != nil {
 			return fmt.Errorf("regal/showExplorerResult notification failed: %w", err)
@@ -406,6 +369,8 @@ func (l *LanguageServer) handleExplorerCommand(ctx context.Context, params types
 		return nil
 	}
 
+	compileResults := explorer.CompilerStages(path, contents, args.Strict, args.Annotations, args.Print)
+
 	// For other LSP clients, write stages to temp files and use window/showDocument
 	tmpDir, err := os.MkdirTemp("", "regal-explorer-*")
 	if err != nil {
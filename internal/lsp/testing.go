@@ -32,7 +32,11 @@ var (
 func (l *LanguageServer) StartTestLocationsWorker(ctx context.Context) {
 	l.workersWg.Go(func() {
 		// Wait for initialization to complete before starting to check worker is needed
-		<-l.initializationGate
+		select {
+		case <-ctx.Done():
+			return
+		case <-l.initializationGate:
+		}
 
 		if !l.Workspace().Client().InitOptions.EnableServerTesting {
 			l.log.Debug("Test locations worker exiting - client does not support opaTestProvider")
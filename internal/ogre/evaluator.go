@@ -24,6 +24,7 @@ type Evaluator struct {
 	input         ast.Value
 	outputHandler func(result topdown.QueryResult) error
 	profiler      *profiler.Profiler
+	tracer        topdown.QueryTracer
 	txn           storage.Transaction
 }
 
@@ -47,6 +48,14 @@ func (e *Evaluator) WithProfiler(p *profiler.Profiler) *Evaluator {
 	return e
 }
 
+// WithQueryTracer sets a tracer to receive the events of the evaluation, in addition to the
+// profiler, if any.
+func (e *Evaluator) WithQueryTracer(t topdown.QueryTracer) *Evaluator {
+	e.tracer = t
+
+	return e
+}
+
 func (e *Evaluator) WithTransaction(txn storage.Transaction) *Evaluator {
 	e.txn = txn
 
@@ -109,6 +118,10 @@ func (e *Evaluator) Eval(ctx context.Context) (err error) {
 		WithQueryTracer(qcWrapper{Profiler: e.profiler}).
 		WithInput(inputTerm)
 
+	if e.tracer != nil {
+		q = q.WithQueryTracer(e.tracer)
+	}
+
 	err = q.Iter(ctx, e.outputHandler)
 
 	inputTerm.Value = nil
@@ -0,0 +1,388 @@
+// Package playground provides a browser based Rego playground, where policies are edited with diagnostics
+// and formatting provided by a language server session, and where policies may be evaluated, explored, and
+// shared as bundles, all without requiring anything but a local Regal binary.
+package playground
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/websocket"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/bundle"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/storage/inmem"
+	"github.com/open-policy-agent/opa/v1/topdown/print"
+
+	"github.com/open-policy-agent/regal/internal/explorer"
+	"github.com/open-policy-agent/regal/internal/lsp/log"
+	"github.com/open-policy-agent/regal/internal/parse"
+	"github.com/open-policy-agent/regal/pkg/config"
+	"github.com/open-policy-agent/regal/pkg/lsp"
+
+	_ "github.com/open-policy-agent/regal/pkg/builtins"
+)
+
+// PolicyPath is the path of the policy edited in the playground, as seen by the language server,
+// the compiler and in shared bundles.
+const PolicyPath = "policy.rego"
+
+// maxBodySize limits the size of requests, which only ever contain a single policy and its input and data.
+const maxBodySize = 10 << 20
+
+//go:embed index.html playground.js playground.css
+var assets embed.FS
+
+// Session is the state of a playground session, which is what gets shared as a bundle.
+type Session struct {
+	Policy string `json:"policy"`
+	Query  string `json:"query,omitempty"`
+	Input  string `json:"input,omitempty"`
+	Data   string `json:"data,omitempty"`
+}
+
+// EvalResult is the result of evaluating a query in the playground.
+type EvalResult struct {
+	Result    any      `json:"result,omitempty"`
+	Undefined bool     `json:"undefined,omitempty"`
+	Output    []string `json:"output,omitempty"`
+	Error     string   `json:"error,omitempty"`
+}
+
+type exploreRequest struct {
+	Policy      string `json:"policy"`
+	Strict      bool   `json:"strict"`
+	Annotations bool   `json:"annotations"`
+	Print       bool   `json:"print"`
+	Format      bool   `json:"format"`
+}
+
+type printHook struct {
+	output []string
+	mu     sync.Mutex
+}
+
+// metadataKey is the key under which the query and input of a session are stored in the metadata of
+// the manifest of shared bundles, as bundles have no place for input otherwise.
+const metadataKey = "regal_playground"
+
+// config used for language server sessions, where rules that assume a project on disk are disabled.
+var sessionConfig = config.Config{
+	Rules: map[string]config.Category{
+		"idiomatic": {
+			"directory-package-mismatch": config.Rule{Level: "ignore"},
+		},
+		"imports": {
+			"unresolved-import":    config.Rule{Level: "ignore"},
+			"unresolved-reference": config.Rule{Level: "ignore"},
+		},
+		"testing": {
+			"test-outside-test-package": config.Rule{Level: "ignore"},
+			"file-missing-test-suffix":  config.Rule{Level: "ignore"},
+		},
+	},
+}
+
+// unsafeBuiltins can't be called by policies evaluated in the playground, as they could be used to reach
+// the network, or to read the environment of the playground process, on behalf of whoever wrote the policy.
+var unsafeBuiltins = func() map[string]struct{} {
+	unsafe := map[string]struct{}{ast.HTTPSend.Name: {}, ast.OPARuntime.Name: {}}
+
+	for _, builtin := range ast.Builtins {
+		if strings.HasPrefix(builtin.Name, "net.") {
+			unsafe[builtin.Name] = struct{}{}
+		}
+	}
+
+	return unsafe
+}()
+
+// NewHandler returns a handler serving the playground, with the following routes:
+//
+//	GET  /                 the playground page and its assets
+//	GET  /lsp              a language server session over a websocket connection
+//	POST /api/explore      the output of each compiler stage for a policy
+//	POST /api/eval         the result of evaluating a query against a policy, input and data
+//	POST /api/bundle       a session packaged as a bundle, for download
+//	POST /api/bundle/open  the session stored in an uploaded bundle
+//
+// Requests are only served for the hosts (as host:port) that the playground is served at, and only from
+// pages of the playground itself, so that other web pages can't use the playground, whether by making
+// cross-origin requests, or by having their own host name resolve to the address of the playground.
+func NewHandler(logger *log.Logger, hosts ...string) http.Handler {
+	sameOrigin := func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+
+		return slices.Contains(hosts, r.Host) && (origin == "" || origin == "http://"+r.Host)
+	}
+
+	upgrader := websocket.Upgrader{CheckOrigin: sameOrigin}
+
+	mux := http.NewServeMux()
+	mux.Handle("GET /", http.FileServerFS(assets))
+	mux.HandleFunc("GET /lsp", func(w http.ResponseWriter, r *http.Request) {
+		ws, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logger.Message("failed to upgrade playground connection: %v", err)
+
+			return
+		}
+
+		cfg := sessionConfig
+
+		// the session takes ownership of ws
+		session, err := lsp.New(r.Context(), ws, &cfg)
+		if err != nil {
+			logger.Message("failed to start playground session: %v", err)
+
+			return
+		}
+
+		defer session.Close()
+
+		if err := session.Wait(r.Context()); err != nil && !errors.Is(err, context.Canceled) {
+			logger.Message("playground session failed: %v", err)
+		}
+	})
+	mux.HandleFunc("POST /api/explore", jsonHandler(func(r *http.Request, req exploreRequest) (any, error) {
+		return explorer.Explore(
+			r.Context(), PolicyPath, req.Policy, req.Strict, req.Annotations, req.Print, req.Format,
+		), nil
+	}))
+	mux.HandleFunc("POST /api/eval", jsonHandler(func(r *http.Request, session Session) (any, error) {
+		return Eval(r.Context(), session), nil
+	}))
+	mux.HandleFunc("POST /api/bundle", func(w http.ResponseWriter, r *http.Request) {
+		if !hasContentType(w, r, "application/json") {
+			return
+		}
+
+		var session Session
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&session); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := WriteBundle(&buf, session); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/gzip")
+		w.Header().Set("Content-Disposition", `attachment; filename="playground.tar.gz"`)
+		_, _ = w.Write(buf.Bytes())
+	})
+	mux.HandleFunc("POST /api/bundle/open", func(w http.ResponseWriter, r *http.Request) {
+		if !hasContentType(w, r, "application/gzip") {
+			return
+		}
+
+		session, err := ReadBundle(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		writeJSON(w, session)
+	})
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !sameOrigin(r) {
+			http.Error(w, "requests are only accepted from the playground itself", http.StatusForbidden)
+
+			return
+		}
+
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// Eval evaluates the query of the session against its policy, input and data. Any errors, like those from
+// parsing or compiling the policy, are reported in the result rather than returned. Built-in functions
+// reaching outside of the evaluation, like http.send, are not available to the policy.
+func Eval(ctx context.Context, session Session) EvalResult {
+	query := strings.TrimSpace(session.Query)
+	if query == "" {
+		query = "data"
+	}
+
+	hook := &printHook{}
+	args := []func(*rego.Rego){
+		rego.Query("result := " + query),
+		rego.Module(PolicyPath, session.Policy),
+		rego.SetRegoVersion(ast.RegoV1),
+		rego.EnablePrintStatements(true),
+		rego.PrintHook(hook),
+		rego.UnsafeBuiltins(unsafeBuiltins),
+	}
+
+	if strings.TrimSpace(session.Data) != "" {
+		var data map[string]any
+		if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+			return EvalResult{Error: "invalid data, expected JSON object: " + err.Error()}
+		}
+
+		args = append(args, rego.Store(inmem.NewFromObject(data)))
+	}
+
+	if strings.TrimSpace(session.Input) != "" {
+		input, err := ast.ParseTerm(session.Input)
+		if err != nil {
+			return EvalResult{Error: "invalid input: " + err.Error()}
+		}
+
+		args = append(args, rego.ParsedInput(input.Value))
+	}
+
+	rs, err := rego.New(args...).Eval(ctx)
+	if err != nil {
+		return EvalResult{Error: err.Error(), Output: hook.output}
+	}
+
+	if len(rs) == 0 {
+		return EvalResult{Undefined: true, Output: hook.output}
+	}
+
+	return EvalResult{Result: rs[0].Bindings["result"], Output: hook.output}
+}
+
+// WriteBundle writes the session as a gzipped bundle to w, with the policy as its only module and the data
+// as its data document. The query and input are stored in the metadata of the manifest.
+func WriteBundle(w io.Writer, session Session) error {
+	module, err := parse.ModuleWithOpts(PolicyPath, session.Policy, parse.ParserOptions())
+	if err != nil {
+		return fmt.Errorf("failed to parse policy: %w", err)
+	}
+
+	data := map[string]any{}
+	if strings.TrimSpace(session.Data) != "" {
+		if err := json.Unmarshal([]byte(session.Data), &data); err != nil {
+			return fmt.Errorf("invalid data, expected JSON object: %w", err)
+		}
+	}
+
+	b := bundle.Bundle{
+		Manifest: bundle.Manifest{Metadata: map[string]any{metadataKey: map[string]any{
+			"query": session.Query,
+			"input": session.Input,
+		}}},
+		Data: data,
+		Modules: []bundle.ModuleFile{{
+			URL:    "/" + PolicyPath,
+			Path:   "/" + PolicyPath,
+			Raw:    []byte(session.Policy),
+			Parsed: module,
+		}},
+	}
+
+	b.Manifest.Init()
+
+	if err := bundle.NewWriter(w).Write(b); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+
+	return nil
+}
+
+// ReadBundle reads a session from a gzipped bundle, like one written by WriteBundle. Bundles not created
+// by the playground may be opened too, as long as they contain a single module.
+func ReadBundle(r io.Reader) (Session, error) {
+	b, err := bundle.NewCustomReader(bundle.NewTarballLoader(r)).
+		WithSkipBundleVerification(true).
+		WithRegoVersion(ast.RegoV1).
+		Read()
+	if err != nil {
+		return Session{}, fmt.Errorf("failed to read bundle: %w", err)
+	}
+
+	if len(b.Modules) != 1 {
+		return Session{}, fmt.Errorf("expected bundle with a single module, found %d", len(b.Modules))
+	}
+
+	session := Session{Policy: string(b.Modules[0].Raw)}
+
+	if len(b.Data) > 0 {
+		data, err := json.MarshalIndent(b.Data, "", "  ")
+		if err != nil {
+			return Session{}, fmt.Errorf("failed to encode data: %w", err)
+		}
+
+		session.Data = string(data)
+	}
+
+	if meta, ok := b.Manifest.Metadata[metadataKey].(map[string]any); ok {
+		session.Query, _ = meta["query"].(string)
+		session.Input, _ = meta["input"].(string)
+	}
+
+	return session, nil
+}
+
+func (h *printHook) Print(_ print.Context, msg string) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.output = append(h.output, msg)
+
+	return nil
+}
+
+func jsonHandler[T any](f func(*http.Request, T) (any, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !hasContentType(w, r, "application/json") {
+			return
+		}
+
+		var req T
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBodySize)).Decode(&req); err != nil {
+			http.Error(w, "invalid request: "+err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		resp, err := f(r, req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+
+			return
+		}
+
+		writeJSON(w, resp)
+	}
+}
+
+// hasContentType reports whether the request body is of the media type, or otherwise responds with an
+// error. Requiring a type that can't be sent by HTML forms means browsers won't send it cross-origin
+// without asking the playground first.
+func hasContentType(w http.ResponseWriter, r *http.Request, mediaType string) bool {
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != mediaType {
+		http.Error(w, "expected content type "+mediaType, http.StatusUnsupportedMediaType)
+
+		return false
+	}
+
+	return true
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,366 @@
+"use strict";
+
+const policyURI = "file:///playground/policy.rego";
+const storageKey = "regal-playground-session";
+
+const initialSession = {
+  policy: `package policy
+
+default allow := false
+
+allow if {
+    some role in input.user.roles
+    role in data.admin_roles
+}
+`,
+  query: "data.policy.allow",
+  input: JSON.stringify({ user: { roles: ["admin"] } }, null, 2),
+  data: JSON.stringify({ admin_roles: ["admin"] }, null, 2),
+};
+
+const editors = {
+  policy: document.getElementById("policy"),
+  input: document.getElementById("input"),
+  data: document.getElementById("data"),
+  query: document.getElementById("query"),
+};
+
+const status = document.getElementById("status");
+
+// LanguageServer is a minimal client for the language server session served over a websocket, where each
+// websocket message is a single JSON-RPC message.
+class LanguageServer {
+  constructor(url, onNotification) {
+    this.nextID = 1;
+    this.pending = new Map();
+    this.onNotification = onNotification;
+    this.ws = new WebSocket(url);
+    this.ws.addEventListener("message", (event) => this.receive(JSON.parse(event.data)));
+    this.opened = new Promise((resolve, reject) => {
+      this.ws.addEventListener("open", resolve);
+      this.ws.addEventListener("error", reject);
+    });
+  }
+
+  receive(message) {
+    if (message.id !== undefined && message.method === undefined) {
+      const pending = this.pending.get(message.id);
+      this.pending.delete(message.id);
+      if (pending && message.error) {
+        pending.reject(new Error(message.error.message));
+      } else if (pending) {
+        pending.resolve(message.result);
+      }
+    } else if (message.id !== undefined) {
+      // requests from the server, like workspace/configuration, need a response, but nothing more
+      this.send({ jsonrpc: "2.0", id: message.id, result: null });
+    } else {
+      this.onNotification(message.method, message.params);
+    }
+  }
+
+  send(message) {
+    this.ws.send(JSON.stringify(message));
+  }
+
+  request(method, params) {
+    const id = this.nextID++;
+    return new Promise((resolve, reject) => {
+      this.pending.set(id, { resolve, reject });
+      this.send({ jsonrpc: "2.0", id, method, params });
+    });
+  }
+
+  notify(method, params) {
+    this.send({ jsonrpc: "2.0", method, params });
+  }
+}
+
+let server;
+let version = 1;
+
+async function connect() {
+  const scheme = location.protocol === "https:" ? "wss" : "ws";
+
+  server = new LanguageServer(`${scheme}://${location.host}/lsp`, (method, params) => {
+    if (method === "textDocument/publishDiagnostics" && params.uri === policyURI) {
+      renderDiagnostics(params.diagnostics);
+    }
+  });
+
+  server.ws.addEventListener("close", () => {
+    status.textContent = "disconnected";
+    status.className = "status disconnected";
+  });
+
+  await server.opened;
+  await server.request("initialize", {
+    processId: null,
+    rootUri: "file:///playground",
+    clientInfo: { name: "regal-playground" },
+    capabilities: { textDocument: { publishDiagnostics: {}, formatting: {} } },
+  });
+
+  server.notify("initialized", {});
+  server.notify("textDocument/didOpen", {
+    textDocument: { uri: policyURI, languageId: "rego", version, text: editors.policy.value },
+  });
+
+  status.textContent = "connected";
+  status.className = "status connected";
+}
+
+function policyChanged() {
+  version++;
+  server?.notify("textDocument/didChange", {
+    textDocument: { uri: policyURI, version },
+    contentChanges: [{ text: editors.policy.value }],
+  });
+}
+
+function renderDiagnostics(diagnostics) {
+  const list = document.getElementById("diagnostics");
+  list.replaceChildren();
+
+  document.getElementById("lint-count").textContent = diagnostics.length > 0 ? `(${diagnostics.length})` : "";
+
+  if (diagnostics.length === 0) {
+    const item = document.createElement("li");
+    item.textContent = "No violations found";
+    list.appendChild(item);
+    return;
+  }
+
+  for (const diagnostic of diagnostics) {
+    const { line, character } = diagnostic.range.start;
+    const item = document.createElement("li");
+    item.className = `severity-${diagnostic.severity}`;
+
+    const location = document.createElement("span");
+    location.className = "location";
+    location.textContent = `${line + 1}:${character + 1}`;
+    item.appendChild(location);
+
+    item.appendChild(document.createTextNode(`${diagnostic.message} `));
+
+    if (diagnostic.codeDescription?.href) {
+      const link = document.createElement("a");
+      link.href = diagnostic.codeDescription.href;
+      link.target = "_blank";
+      link.textContent = diagnostic.code;
+      item.appendChild(link);
+    }
+
+    item.addEventListener("click", () => selectRange(diagnostic.range));
+    list.appendChild(item);
+  }
+}
+
+function offsetAt(text, { line, character }) {
+  let offset = 0;
+  for (let i = 0; i < line; i++) {
+    const next = text.indexOf("\n", offset);
+    if (next === -1) return text.length;
+    offset = next + 1;
+  }
+  return Math.min(offset + character, text.length);
+}
+
+function selectRange(range) {
+  const text = editors.policy.value;
+  editors.policy.focus();
+  editors.policy.setSelectionRange(offsetAt(text, range.start), offsetAt(text, range.end));
+}
+
+async function format() {
+  const edits = await server.request("textDocument/formatting", {
+    textDocument: { uri: policyURI },
+    options: { tabSize: 4, insertSpaces: false },
+  });
+
+  if (!edits || edits.length === 0) return;
+
+  // apply edits from the end of the document, so that earlier offsets remain valid
+  let text = editors.policy.value;
+  const sorted = [...edits].sort((a, b) => offsetAt(text, b.range.start) - offsetAt(text, a.range.start));
+  for (const edit of sorted) {
+    text = text.slice(0, offsetAt(text, edit.range.start)) + edit.newText + text.slice(offsetAt(text, edit.range.end));
+  }
+
+  editors.policy.value = text;
+  policyChanged();
+  save();
+}
+
+function session() {
+  return {
+    policy: editors.policy.value,
+    query: editors.query.value,
+    input: editors.input.value,
+    data: editors.data.value,
+  };
+}
+
+function load(s) {
+  editors.policy.value = s.policy ?? "";
+  editors.query.value = s.query ?? "";
+  editors.input.value = s.input ?? "";
+  editors.data.value = s.data ?? "";
+}
+
+function save() {
+  localStorage.setItem(storageKey, JSON.stringify(session()));
+}
+
+async function post(path, body) {
+  const response = await fetch(path, {
+    method: "POST",
+    headers: { "Content-Type": "application/json" },
+    body: JSON.stringify(body),
+  });
+
+  if (!response.ok) {
+    throw new Error(await response.text());
+  }
+
+  return response;
+}
+
+async function evaluate(event) {
+  event.preventDefault();
+
+  const output = document.getElementById("eval-output");
+  const result = await (await post("/api/eval", session())).json();
+  const lines = [];
+
+  if (result.output?.length > 0) {
+    lines.push(...result.output, "");
+  }
+
+  if (result.error) {
+    lines.push(result.error);
+  } else if (result.undefined) {
+    lines.push("undefined");
+  } else {
+    lines.push(JSON.stringify(result.result, null, 2));
+  }
+
+  output.className = result.error ? "error" : "";
+  output.textContent = lines.join("\n");
+}
+
+async function explore(event) {
+  event.preventDefault();
+
+  const result = await (await post("/api/explore", {
+    policy: editors.policy.value,
+    strict: document.getElementById("strict").checked,
+    annotations: document.getElementById("annotations").checked,
+    print: document.getElementById("print").checked,
+    format: document.getElementById("formatted").checked,
+  })).json();
+
+  const container = document.getElementById("stages");
+  container.replaceChildren();
+
+  let previous = "";
+  const stages = [...result.stages];
+  if (result.plan) {
+    stages.push({ name: "Plan", output: result.plan });
+  }
+
+  for (const stage of stages) {
+    const details = document.createElement("details");
+    const summary = document.createElement("summary");
+    const pre = document.createElement("pre");
+
+    // only expand the stages that changed the module, as most don't
+    details.open = stage.error || stage.output !== previous;
+    summary.textContent = stage.output === previous ? `${stage.name} (unchanged)` : stage.name;
+    pre.textContent = stage.output;
+    pre.className = stage.error ? "error" : "";
+    previous = stage.output;
+
+    details.append(summary, pre);
+    container.appendChild(details);
+  }
+}
+
+async function share() {
+  try {
+    const blob = await (await post("/api/bundle", session())).blob();
+    const link = document.createElement("a");
+    link.href = URL.createObjectURL(blob);
+    link.download = "playground.tar.gz";
+    link.click();
+    URL.revokeObjectURL(link.href);
+  } catch (error) {
+    alert(`Failed to create bundle: ${error.message}`);
+  }
+}
+
+async function open(event) {
+  const [file] = event.target.files;
+  if (!file) return;
+
+  const response = await fetch("/api/bundle/open", {
+    method: "POST",
+    headers: { "Content-Type": "application/gzip" },
+    body: file,
+  });
+  if (!response.ok) {
+    alert(`Failed to open bundle: ${await response.text()}`);
+    return;
+  }
+
+  load(await response.json());
+  policyChanged();
+  save();
+  event.target.value = "";
+}
+
+function selectTab(tab) {
+  for (const other of document.querySelectorAll("[role=tab]")) {
+    const selected = other === tab;
+    other.setAttribute("aria-selected", selected);
+    document.getElementById(other.getAttribute("aria-controls")).hidden = !selected;
+  }
+}
+
+let debounce;
+
+editors.policy.addEventListener("input", () => {
+  clearTimeout(debounce);
+  debounce = setTimeout(() => {
+    policyChanged();
+    save();
+  }, 300);
+});
+
+for (const editor of [editors.input, editors.data, editors.query]) {
+  editor.addEventListener("input", save);
+}
+
+// insert tabs rather than moving focus, as expected in a code editor
+editors.policy.addEventListener("keydown", (event) => {
+  if (event.key === "Tab" && !event.shiftKey) {
+    event.preventDefault();
+    document.execCommand("insertText", false, "\t");
+  }
+});
+
+for (const tab of document.querySelectorAll("[role=tab]")) {
+  tab.addEventListener("click", () => selectTab(tab));
+}
+
+document.getElementById("format").addEventListener("click", format);
+document.getElementById("share").addEventListener("click", share);
+document.getElementById("open").addEventListener("change", open);
+document.getElementById("eval-form").addEventListener("submit", evaluate);
+document.getElementById("explore-form").addEventListener("submit", explore);
+
+load(JSON.parse(localStorage.getItem(storageKey)) ?? initialSession);
+connect().catch((error) => {
+  status.textContent = `failed to connect: ${error.message ?? "connection error"}`;
+  status.className = "status disconnected";
+});
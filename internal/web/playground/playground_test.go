@@ -0,0 +1,310 @@
+package playground
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/sourcegraph/jsonrpc2"
+
+	"github.com/open-policy-agent/regal/internal/lsp/log"
+	"github.com/open-policy-agent/regal/internal/lsp/types"
+	"github.com/open-policy-agent/regal/pkg/lsp"
+)
+
+const policy = `package policy
+
+allow if {
+	print("roles", input.roles)
+	"admin" in input.roles
+	data.enabled
+}
+`
+
+func TestEval(t *testing.T) {
+	t.Parallel()
+
+	cases := []struct {
+		name     string
+		session  Session
+		expected EvalResult
+	}{
+		{
+			name:     "allowed",
+			session:  Session{Policy: policy, Query: "data.policy.allow", Input: `{"roles": ["admin"]}`, Data: `{"enabled": true}`},
+			expected: EvalResult{Result: true, Output: []string{`roles ["admin"]`}},
+		},
+		{
+			name:     "undefined",
+			session:  Session{Policy: policy, Query: "data.policy.allow", Input: `{"roles": ["user"]}`, Data: `{"enabled": true}`},
+			expected: EvalResult{Undefined: true, Output: []string{`roles ["user"]`}},
+		},
+		{
+			name:     "invalid data",
+			session:  Session{Policy: policy, Data: `[1, 2, 3]`},
+			expected: EvalResult{Error: "invalid data, expected JSON object: json: cannot unmarshal array into Go value of type map[string]interface {}"},
+		},
+		{
+			name:     "unsafe builtin",
+			session:  Session{Policy: "package policy\n\nresp := http.send({\"method\": \"GET\", \"url\": \"http://localhost\"})\n"},
+			expected: EvalResult{Error: "bundle activation failed: 1 error occurred: policy.rego:3: rego_type_error: unsafe built-in function calls in expression: http.send"},
+		},
+		{
+			name:     "parse error",
+			session:  Session{Policy: "package policy\n\nallow if {"},
+			expected: EvalResult{Error: "1 error occurred: policy.rego:3: rego_parse_error: unexpected eof token\n\tallow if {\n\t         ^"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := Eval(t.Context(), tc.session)
+
+			expected, _ := json.Marshal(tc.expected)
+			actual, _ := json.Marshal(result)
+
+			if !bytes.Equal(expected, actual) {
+				t.Errorf("expected %s, got %s", expected, actual)
+			}
+		})
+	}
+}
+
+func TestBundleRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	session := Session{Policy: policy, Query: "data.policy.allow", Input: `{"roles": ["admin"]}`, Data: "{\n  \"enabled\": true\n}"}
+
+	var buf bytes.Buffer
+	if err := WriteBundle(&buf, session); err != nil {
+		t.Fatal(err)
+	}
+
+	read, err := ReadBundle(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if read != session {
+		t.Errorf("expected %+v, got %+v", session, read)
+	}
+}
+
+func TestWriteBundleInvalidPolicy(t *testing.T) {
+	t.Parallel()
+
+	if err := WriteBundle(io.Discard, Session{Policy: "package"}); err == nil {
+		t.Fatal("expected error for invalid policy")
+	}
+}
+
+func TestHandlerExplore(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	body := `{"policy": "package policy\n\n# METADATA\n# entrypoint: true\nallow := true\n", "format": true}`
+
+	resp, err := http.Post(server.URL+"/api/explore", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", resp.StatusCode)
+	}
+
+	var result types.ExplorerResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(result.Stages) == 0 || result.Stages[0].Name != "ParseModule" {
+		t.Errorf("expected stages starting with ParseModule, got %+v", result.Stages)
+	}
+
+	if result.Plan == "" {
+		t.Error("expected plan in result")
+	}
+}
+
+func TestHandlerServesAssets(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	for _, path := range []string{"/", "/playground.js", "/playground.css"} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_ = resp.Body.Close()
+
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200 for %s, got %d", path, resp.StatusCode)
+		}
+	}
+}
+
+func TestHandlerLanguageServerSession(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Second)
+	defer cancel()
+
+	ws, _, err := websocket.DefaultDialer.DialContext(ctx, "ws"+strings.TrimPrefix(server.URL, "http")+"/lsp", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diagnostics := make(chan types.FileDiagnostics, 10)
+	handler := jsonrpc2.HandlerWithError(func(_ context.Context, _ *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		if req.Method == "textDocument/publishDiagnostics" {
+			var params types.FileDiagnostics
+			if err := json.Unmarshal(*req.Params, &params); err != nil {
+				return nil, err
+			}
+
+			diagnostics <- params
+		}
+
+		return struct{}{}, nil
+	})
+
+	conn := jsonrpc2.NewConn(ctx, lsp.NewObjectStream(ws), handler)
+	defer conn.Close()
+
+	var response map[string]any
+	if err := conn.Call(ctx, "initialize", map[string]any{
+		"rootUri":    "file:///playground",
+		"clientInfo": map[string]any{"name": "regal-playground"},
+	}, &response); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Notify(ctx, "initialized", struct{}{}); err != nil {
+		t.Fatal(err)
+	}
+
+	uri := "file:///playground/" + PolicyPath
+	if err := conn.Notify(ctx, "textDocument/didOpen", map[string]any{
+		"textDocument": map[string]any{"uri": uri, "languageId": "rego", "version": 1, "text": "package policy\n\ncamelCase := true\n"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		select {
+		case d := <-diagnostics:
+			if d.URI != uri {
+				continue
+			}
+
+			for _, diag := range d.Items {
+				if diag.Code == "prefer-snake-case" {
+					return
+				}
+			}
+		case <-ctx.Done():
+			t.Fatal("timed out waiting for prefer-snake-case diagnostic")
+		}
+	}
+}
+
+func TestHandlerRejectsOtherOrigins(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+	body := `{"policy": "package policy\n\nallow := true\n", "query": "data.policy.allow"}`
+
+	cases := []struct {
+		name        string
+		host        string
+		origin      string
+		contentType string
+		status      int
+	}{
+		{name: "same origin", origin: server.URL, contentType: "application/json", status: http.StatusOK},
+		{name: "no origin", contentType: "application/json; charset=utf-8", status: http.StatusOK},
+		{name: "cross origin", origin: "http://example.com", contentType: "application/json", status: http.StatusForbidden},
+		{name: "other host", host: "example.com", contentType: "application/json", status: http.StatusForbidden},
+		{name: "text/plain", origin: server.URL, contentType: "text/plain", status: http.StatusUnsupportedMediaType},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			req, err := http.NewRequestWithContext(t.Context(), http.MethodPost, server.URL+"/api/eval", strings.NewReader(body))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			req.Header.Set("Content-Type", tc.contentType)
+
+			if tc.origin != "" {
+				req.Header.Set("Origin", tc.origin)
+			}
+
+			if tc.host != "" {
+				req.Host = tc.host
+			}
+
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_ = resp.Body.Close()
+
+			if resp.StatusCode != tc.status {
+				t.Errorf("expected status %d, got %d", tc.status, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestHandlerRejectsCrossOriginLanguageServerSession(t *testing.T) {
+	t.Parallel()
+
+	server := newTestServer(t)
+
+	_, resp, err := websocket.DefaultDialer.DialContext(
+		t.Context(), "ws"+strings.TrimPrefix(server.URL, "http")+"/lsp", http.Header{"Origin": {"http://example.com"}},
+	)
+	if err == nil {
+		t.Fatal("expected cross-origin connection to be rejected")
+	}
+
+	if resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected status 403, got %v", resp)
+	}
+
+	_ = resp.Body.Close()
+}
+
+// newTestServer starts a server for the playground, accepting requests for its own address only.
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewUnstartedServer(nil)
+	server.Config.Handler = NewHandler(log.NewLogger(log.LevelOff, io.Discard), server.Listener.Addr().String())
+	server.Start()
+
+	t.Cleanup(server.Close)
+
+	return server
+}
@@ -3,7 +3,9 @@ package web
 import (
 	"context"
 	"embed"
+	"errors"
 	"fmt"
+	"html/template"
 	"net"
 	"net/http"
 	"net/http/pprof"
@@ -18,6 +20,9 @@ import (
 //go:embed index.html style.css
 var content embed.FS
 
+//go:embed report.html.tmpl
+var reportTemplate string
+
 type Server struct {
 	log     *log.Logger
 	baseURL string
@@ -25,11 +30,27 @@ type Server struct {
 
 var pprofEndpoints = os.Getenv("REGAL_DEBUG") != "" || os.Getenv("REGAL_DEBUG_PPROF") != ""
 
+// ReportTemplate parses the template used for self-contained HTML lint reports. The stylesheet
+// served by the web server is made available to the template via the stylesheet function, so
+// that it may be inlined in the report.
+func ReportTemplate() (*template.Template, error) {
+	style, err := content.ReadFile("style.css")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stylesheet: %w", err)
+	}
+
+	stylesheet := func() template.CSS {
+		return template.CSS(style) //nolint:gosec // embedded, trusted content
+	}
+
+	return template.New("report").Funcs(template.FuncMap{"stylesheet": stylesheet}).Parse(reportTemplate)
+}
+
 func NewServer(logger *log.Logger) *Server {
 	return &Server{log: logger}
 }
 
-func (s *Server) Start(context.Context) {
+func (s *Server) Start(ctx context.Context) {
 	mux := http.NewServeMux()
 	if err := statsviz.Register(mux); err != nil {
 		s.log.Message("failed to register statsviz handler: %v", err)
@@ -72,7 +93,13 @@ func (s *Server) Start(context.Context) {
 	s.log.Message("starting web server for docs on %s", s.baseURL)
 
 	//nolint:gosec // this is a local server, no timeouts needed
-	if err = http.Serve(listener, mux); err != nil {
+	server := &http.Server{Handler: mux}
+
+	// stop serving when ctx is done, as the process may keep running after that
+	stop := context.AfterFunc(ctx, func() { _ = server.Close() })
+	defer stop()
+
+	if err = server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		s.log.Message("failed to serve web server: %v", err)
 	}
 }
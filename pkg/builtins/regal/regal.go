@@ -3,6 +3,8 @@ package regal
 import (
 	"bytes"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"sync"
 
@@ -68,16 +70,54 @@ var (
 		),
 		CanSkipBctx: true,
 	}
+
+	// RuleIndex metadata for regal.rule_index.
+	RuleIndex = &ast.Builtin{
+		Name: "regal.rule_index",
+		Description: "Compiles a module and reports which of its rules and comprehensions " +
+			"can be indexed by OPA's evaluator.",
+		Decl: types.NewFunction(
+			types.Args(
+				types.Named("input", types.S).
+					Description("Rego module"),
+				types.Named("options", types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))).
+					Description("parser options"),
+			),
+			types.Named("output", types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+		),
+		CanSkipBctx: true,
+	}
+
+	// InferredTypes metadata for regal.inferred_types.
+	InferredTypes = &ast.Builtin{
+		Name:        "regal.inferred_types",
+		Description: "Type checks the rules of a module and returns the types inferred for terms in their bodies.",
+		Decl: types.NewFunction(
+			types.Args(
+				types.Named("input", types.S).
+					Description("Rego module"),
+				types.Named("options", types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))).
+					Description("parser options"),
+			),
+			types.Named("output", types.NewObject(nil, types.NewDynamicProperty(types.S, types.A))),
+		),
+		// the context carries the schemas provided for resolving schema refs
+		CanSkipBctx: false,
+	}
 )
 
 func init() {
 	ast.RegisterBuiltin(ParseModule)
 	ast.RegisterBuiltin(Last)
 	ast.RegisterBuiltin(IsFormatted)
+	ast.RegisterBuiltin(RuleIndex)
+	ast.RegisterBuiltin(InferredTypes)
 
 	topdown.RegisterBuiltinFunc(ParseModule.Name, RegalParseModule)
 	topdown.RegisterBuiltinFunc(Last.Name, RegalLast)
 	topdown.RegisterBuiltinFunc(IsFormatted.Name, RegalIsFormatted)
+	topdown.RegisterBuiltinFunc(RuleIndex.Name, RegalRuleIndex)
+	topdown.RegisterBuiltinFunc(InferredTypes.Name, RegalInferredTypes)
 }
 
 // RegalParseModule regal.parse_module, like rego.parse_module but with location data included in AST.
@@ -142,13 +182,7 @@ func RegalIsFormatted(_ rego.BuiltinContext, operands []*ast.Term, iter func(*as
 		return err
 	}
 
-	regoVersion := ast.RegoV1
-
-	if versionTerm := optionsObj.Get(ast.InternedTerm("rego_version")); versionTerm != nil {
-		if v, ok := versionTerm.Value.(ast.String); ok && v == "v0" {
-			regoVersion = ast.RegoV0
-		}
-	}
+	regoVersion := regoVersionOption(optionsObj)
 
 	// We don't need to process annotations for formatting.
 	popts := ast.ParserOptions{ProcessAnnotation: false, RegoVersion: regoVersion, Capabilities: capabilities()}
@@ -162,6 +196,191 @@ func RegalIsFormatted(_ rego.BuiltinContext, operands []*ast.Term, iter func(*as
 	return iter(ast.InternedTerm(bytes.Equal(source, result)))
 }
 
+// RegalRuleIndex regal.rule_index compiles the provided module, and returns an object with two attributes:
+//   - rules: keyed by the "row:col" location of each rule sharing its path with other rules, and with
+//     the number of definitions for that path, how many of them are indexed, and whether the rule index
+//     can exclude the rule from evaluation, i.e. whether it is "indexed"
+//   - comprehensions: keyed by the "row:col" location of each comprehension referencing variables
+//     bound inside of an iteration in the enclosing body, and whether OPA was able to build an index
+//     for the comprehension
+//
+// Type checking is skipped, and calls to functions declared in other modules are allowed, as neither
+// affects indexing. Modules that still fail to compile, e.g. because of unsafe variables, result in an
+// empty object for both attributes, as no conclusions can be drawn about them.
+func RegalRuleIndex(_ rego.BuiltinContext, operands []*ast.Term, iter func(*ast.Term) error) error {
+	inputStr, err := builtins.StringOperand(operands[0].Value, 1)
+	if err != nil {
+		return err
+	}
+
+	optionsObj, err := builtins.ObjectOperand(operands[1].Value, 2)
+	if err != nil {
+		return err
+	}
+
+	popts := ast.ParserOptions{RegoVersion: regoVersionOption(optionsObj), Capabilities: capabilities()}
+
+	module, err := ast.ParseModuleWithOpts("", string(inputStr), popts)
+	if err != nil {
+		return err
+	}
+
+	rules, comprehensions := ast.NewObject(), ast.NewObject()
+
+	compiler := ast.NewCompiler().
+		WithCapabilities(capabilities()).
+		WithAllowUndefinedFunctionCalls(true).
+		WithSkipStages(ast.StageCheckTypes)
+
+	if compiler.Compile(map[string]*ast.Module{"": module}); !compiler.Failed() {
+		indexedRules(compiler, rules)
+		indexedComprehensions(compiler, compiler.Modules[""], comprehensions)
+	}
+
+	return iter(ast.ObjectTerm(
+		ast.Item(ast.InternedTerm("rules"), ast.NewTerm(rules)),
+		ast.Item(ast.InternedTerm("comprehensions"), ast.NewTerm(comprehensions)),
+	))
+}
+
+// unmatchedResolver resolves every reference to a value no rule is expected to compare against,
+// which has the rule index return only the rules that it can't exclude from evaluation.
+type unmatchedResolver struct{}
+
+func (unmatchedResolver) Resolve(ast.Ref) (ast.Value, error) {
+	return ast.String("\x00regal.rule_index"), nil
+}
+
+func indexedRules(compiler *ast.Compiler, rules ast.Object) {
+	compiler.RuleTree.DepthFirst(func(node *ast.TreeNode) bool {
+		if node.Index == nil {
+			return false
+		}
+
+		all, err := node.Index.AllRules(unmatchedResolver{})
+		if err != nil || len(all.Rules) < 2 {
+			return false
+		}
+
+		unindexed, err := node.Index.Lookup(unmatchedResolver{})
+		if err != nil {
+			return false
+		}
+
+		definitions := ast.InternedTerm(len(all.Rules))
+		indexedDefinitions := ast.InternedTerm(len(all.Rules) - len(unindexed.Rules))
+
+		for _, rule := range all.Rules {
+			rules.Insert(locationKey(rule.Location), ast.ObjectTerm(
+				ast.Item(ast.InternedTerm("definitions"), definitions),
+				ast.Item(ast.InternedTerm("indexed_definitions"), indexedDefinitions),
+				ast.Item(ast.InternedTerm("indexed"), ast.InternedTerm(!slices.Contains(unindexed.Rules, rule))),
+			))
+		}
+
+		return false
+	})
+}
+
+func indexedComprehensions(compiler *ast.Compiler, module *ast.Module, comprehensions ast.Object) {
+	ast.WalkRules(module, func(rule *ast.Rule) bool {
+		bound := ast.NewVarSet()
+
+		for _, arg := range rule.Head.Args {
+			bound.Update(arg.Vars())
+		}
+
+		indexedComprehensionsInBody(compiler, rule.Body, bound, ast.NewVarSet(), comprehensions)
+
+		return false
+	})
+}
+
+// indexedComprehensionsInBody checks comprehensions in body referencing any vars bound following an
+// iteration, as only comprehensions evaluated repeatedly benefit from being indexed. Comprehensions
+// nested in other comprehensions are checked with the vars of the enclosing bodies in scope.
+func indexedComprehensionsInBody(
+	compiler *ast.Compiler,
+	body ast.Body,
+	bound, iterated ast.VarSet,
+	comprehensions ast.Object,
+) {
+	bound, iterated = bound.Copy(), iterated.Copy()
+
+	for _, expr := range body {
+		ast.WalkTerms(expr, func(term *ast.Term) bool {
+			var nested ast.Body
+
+			switch v := term.Value.(type) {
+			case *ast.ArrayComprehension:
+				nested = v.Body
+			case *ast.SetComprehension:
+				nested = v.Body
+			case *ast.ObjectComprehension:
+				nested = v.Body
+			default:
+				return false
+			}
+
+			// comprehensions rewritten by the compiler may lack location
+			if term.Location != nil && len(term.Vars().Intersect(iterated)) > 0 {
+				comprehensions.Insert(locationKey(term.Location), ast.ObjectTerm(
+					ast.Item(ast.InternedTerm("indexed"), ast.InternedTerm(compiler.ComprehensionIndex(term) != nil)),
+				))
+			}
+
+			indexedComprehensionsInBody(compiler, nested, bound, iterated, comprehensions)
+
+			return true
+		})
+
+		vars := expr.Vars(ast.VarVisitorParams{SkipClosures: true}).Diff(bound)
+
+		// once iteration has started, any var bound may take on a new value for each iteration
+		if len(iterated) > 0 || iterates(expr, bound) {
+			iterated.Update(vars)
+		}
+
+		bound.Update(vars)
+	}
+}
+
+// iterates reports whether expr iterates over a collection, i.e. whether it references a collection
+// using a var not already bound, or calls walk.
+func iterates(expr *ast.Expr, bound ast.VarSet) bool {
+	if expr.IsCall() && expr.Operator().Equal(ast.WalkBuiltin.Ref()) {
+		return true
+	}
+
+	found := false
+
+	ast.WalkRefs(expr, func(ref ast.Ref) bool {
+		for _, term := range ref[1:] {
+			if v, ok := term.Value.(ast.Var); ok && !bound.Contains(v) {
+				found = true
+			}
+		}
+
+		return found
+	})
+
+	return found
+}
+
+func locationKey(location *ast.Location) *ast.Term {
+	return ast.StringTerm(strconv.Itoa(location.Row) + ":" + strconv.Itoa(location.Col))
+}
+
+func regoVersionOption(options ast.Object) ast.RegoVersion {
+	if versionTerm := options.Get(ast.InternedTerm("rego_version")); versionTerm != nil {
+		if v, ok := versionTerm.Value.(ast.String); ok && v == "v0" {
+			return ast.RegoV0
+		}
+	}
+
+	return ast.RegoV1
+}
+
 func formatRego(source []byte, opts format.Opts) (result []byte, err error) {
 	defer func() {
 		if r := recover(); r != nil {
@@ -45,3 +45,131 @@ func TestRegalParseModuleWithTemplateString(t *testing.T) {
 
 	testutil.NoErr(regal.RegalParseModule(bctx, ops, eqIter))(t)
 }
+
+func TestRegalRuleIndex(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+allow if input.method == "GET"
+
+allow if input.method == upper(input.verb)
+
+deny if upper(1) == lib.name(input)
+
+names contains name if {
+	some user in input.users
+	name := count([role | some role in input.roles; startswith(role, user)])
+}`
+
+	ops := []*ast.Term{ast.StringTerm(policy), ast.ObjectTerm()}
+
+	var result *ast.Term
+
+	testutil.NoErr(regal.RegalRuleIndex(rego.BuiltinContext{}, ops, func(term *ast.Term) error {
+		result = term
+
+		return nil
+	}))(t)
+
+	expected := ast.MustParseTerm(`{
+		"rules": {
+			"3:1": {"definitions": 2, "indexed_definitions": 1, "indexed": true},
+			"5:1": {"definitions": 2, "indexed_definitions": 1, "indexed": false},
+		},
+		"comprehensions": {
+			"11:16": {"indexed": false},
+		},
+	}`)
+
+	if !result.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestRegalInferredTypes(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+# METADATA
+# schemas:
+#   - input:
+#       type: object
+#       properties:
+#         age:
+#           type: number
+allow if {
+	x := concat(".", ["a", "b"])
+	input.age == x
+}`
+
+	ops := []*ast.Term{ast.StringTerm(policy), ast.ObjectTerm()}
+
+	var result *ast.Term
+
+	testutil.NoErr(regal.RegalInferredTypes(rego.BuiltinContext{}, ops, func(term *ast.Term) error {
+		result = term
+
+		return nil
+	}))(t)
+
+	expected := ast.MustParseTerm(`{
+		"11:2": {"type": "string", "kinds": ["string"]},
+		"11:7": {"type": "string", "kinds": ["string"]},
+		"12:2": {"type": "number", "kinds": ["number"]},
+		"12:15": {"type": "string", "kinds": ["string"]},
+	}`)
+
+	if !result.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
+
+func TestRegalInferredTypesWithSchemaRef(t *testing.T) {
+	t.Parallel()
+
+	policy := `package p
+
+# METADATA
+# schemas:
+#   - input: schema.person
+allow if {
+	input.age == "old"
+}`
+
+	schemas := ast.NewSchemaSet()
+	schemas.Put(ast.MustParseRef("schema.person"), map[string]any{
+		"type":       "object",
+		"properties": map[string]any{"age": map[string]any{"type": "number"}},
+	})
+
+	ops := []*ast.Term{ast.StringTerm(policy), ast.ObjectTerm()}
+
+	var result *ast.Term
+
+	bctx := rego.BuiltinContext{Context: regal.WithSchemas(t.Context(), schemas)}
+
+	testutil.NoErr(regal.RegalInferredTypes(bctx, ops, func(term *ast.Term) error {
+		result = term
+
+		return nil
+	}))(t)
+
+	expected := ast.MustParseTerm(`{"7:2": {"type": "number", "kinds": ["number"]}}`)
+
+	if !result.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+
+	// without the schema provided, the reference can't be resolved and nothing is known about input.age
+	testutil.NoErr(regal.RegalInferredTypes(rego.BuiltinContext{}, ops, func(term *ast.Term) error {
+		result = term
+
+		return nil
+	}))(t)
+
+	if expected := ast.MustParseTerm(`{"7:2": {"type": "any", "kinds": []}}`); !result.Equal(expected) {
+		t.Errorf("expected %v, got %v", expected, result)
+	}
+}
@@ -0,0 +1,306 @@
+package regal
+
+import (
+	"context"
+	"slices"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+	"github.com/open-policy-agent/opa/v1/topdown/builtins"
+	"github.com/open-policy-agent/opa/v1/types"
+)
+
+type schemasKey struct{}
+
+// WithSchemas returns a copy of ctx carrying the schemas that regal.inferred_types resolves schema refs in
+// metadata annotations from, like schema.k8s.pod, when evaluated with the returned context.
+func WithSchemas(ctx context.Context, schemas *ast.SchemaSet) context.Context {
+	return context.WithValue(ctx, schemasKey{}, schemas)
+}
+
+// argsRef is the (non-existent) document that function args are bound to when the body of a function
+// is type checked as a query, making them safe without affecting their type.
+var argsRef = ast.MustParseRef(`data["regal.args"]`)
+
+// RegalInferredTypes regal.inferred_types compiles the provided module, type checks the body of each
+// rule, and returns an object with the types inferred for the vars, refs and function calls of each
+// body, keyed by their "row:col" location. Each type is an object with two attributes:
+//   - type: the type as printed by OPA, e.g. "string", "array[any]" or "any<number, string>"
+//   - kinds: the kinds of values the type allows, e.g. ["number", "string"], or an empty array when
+//     the value could be of any kind
+//
+// Input schemas declared in the metadata annotations of a rule are used when type checking its body,
+// whether defined inline or referenced, like schema.k8s.pod, in which case the schema is looked up among
+// those provided using WithSchemas. Only terms in the top-level scope of each body are included, as types of vars local to comprehensions
+// aren't retained by the type checker. Modules that can't be compiled result in an empty object.
+func RegalInferredTypes(bctx rego.BuiltinContext, operands []*ast.Term, iter func(*ast.Term) error) error {
+	inputStr, err := builtins.StringOperand(operands[0].Value, 1)
+	if err != nil {
+		return err
+	}
+
+	optionsObj, err := builtins.ObjectOperand(operands[1].Value, 2)
+	if err != nil {
+		return err
+	}
+
+	popts := ast.ParserOptions{
+		ProcessAnnotation: true,
+		RegoVersion:       regoVersionOption(optionsObj),
+		Capabilities:      capabilities(),
+	}
+
+	module, err := ast.ParseModuleWithOpts("", string(inputStr), popts)
+	if err != nil {
+		return err
+	}
+
+	annotations, errs := ast.BuildAnnotationSet([]*ast.Module{module})
+	if len(errs) > 0 {
+		return iter(ast.NewTerm(ast.NewObject()))
+	}
+
+	var provided *ast.SchemaSet
+	if bctx.Context != nil {
+		provided, _ = bctx.Context.Value(schemasKey{}).(*ast.SchemaSet)
+	}
+
+	result := ast.NewObject()
+	compilers := map[*ast.SchemaAnnotation]*ast.Compiler{}
+
+	ast.WalkRules(module, func(rule *ast.Rule) bool {
+		schema := inputSchema(annotations, rule, provided)
+
+		compiler, ok := compilers[schema]
+		if !ok {
+			compiler = typeCheckedCompiler(module, annotations, schema, provided)
+			compilers[schema] = compiler
+		}
+
+		if compiler.TypeEnv != nil {
+			inferRuleTypes(compiler, module, rule, result)
+		}
+
+		return false
+	})
+
+	return iter(ast.NewTerm(result))
+}
+
+// inputSchema returns the schema annotation closest in scope to rule that declares a schema for the whole
+// input document, either inline or by a reference to one of the provided schemas, or nil if there is none.
+func inputSchema(annotations *ast.AnnotationSet, rule *ast.Rule, provided *ast.SchemaSet) *ast.SchemaAnnotation {
+	for _, annots := range annotationChain(annotations, rule) {
+		for _, schema := range annots.Schemas {
+			if schema.Path.Equal(ast.InputRootRef) && schemaDefinition(schema, provided) != nil {
+				return schema
+			}
+		}
+	}
+
+	return nil
+}
+
+func schemaDefinition(schema *ast.SchemaAnnotation, provided *ast.SchemaSet) any {
+	if schema.Definition != nil {
+		return *schema.Definition
+	}
+
+	if schema.Schema != nil {
+		return provided.Get(schema.Schema)
+	}
+
+	return nil
+}
+
+// annotationChain returns the annotations in scope of rule, closest first. This mirrors
+// AnnotationSet.Chain, which panics on rules whose ref contains composite values, like
+// `r[[1, 2]] := true`, in which case annotations of document scope are skipped.
+func annotationChain(annotations *ast.AnnotationSet, rule *ast.Rule) []*ast.Annotations {
+	if !slices.ContainsFunc(rule.Ref().GroundPrefix(), isCompositeTerm) {
+		chain := make([]*ast.Annotations, 0, 4)
+
+		for _, ref := range annotations.Chain(rule) {
+			if ref.Annotations != nil {
+				chain = append(chain, ref.Annotations)
+			}
+		}
+
+		return chain
+	}
+
+	chain := annotations.GetRuleScope(rule)
+
+	if pa := annotations.GetPackageScope(rule.Module.Package); pa != nil {
+		chain = append(chain, pa)
+	}
+
+	subpackages := slices.Clone(annotations.GetSubpackagesScope(rule.Module.Package.Path))
+	slices.Reverse(subpackages)
+
+	return append(chain, subpackages...)
+}
+
+func isCompositeTerm(term *ast.Term) bool {
+	switch term.Value.(type) {
+	case ast.Ref, *ast.Array, ast.Object, ast.Set:
+		return true
+	}
+
+	return false
+}
+
+// typeCheckedCompiler compiles a copy of the module, with the provided input schema if not nil. The
+// provided schemas referenced in annotations of the module are included too, as the type checker
+// otherwise fails to resolve them. Type errors in the module are expected, and don't prevent the type
+// environment from being populated.
+func typeCheckedCompiler(
+	module *ast.Module,
+	annotations *ast.AnnotationSet,
+	schema *ast.SchemaAnnotation,
+	provided *ast.SchemaSet,
+) *ast.Compiler {
+	compiler := ast.NewCompiler().
+		WithCapabilities(capabilities()).
+		WithAllowUndefinedFunctionCalls(true).
+		WithUseTypeCheckAnnotations(true)
+
+	schemas := ast.NewSchemaSet()
+
+	for _, ref := range annotations.Flatten() {
+		for _, referenced := range ref.Annotations.Schemas {
+			if definition := schemaDefinition(referenced, provided); referenced.Schema != nil && definition != nil {
+				schemas.Put(referenced.Schema, definition)
+			}
+		}
+	}
+
+	if schema != nil {
+		schemas.Put(ast.SchemaRootRef, schemaDefinition(schema, provided))
+	}
+
+	compiler.WithSchemas(schemas).Compile(map[string]*ast.Module{"": module.Copy()})
+
+	return compiler
+}
+
+func inferRuleTypes(compiler *ast.Compiler, module *ast.Module, rule *ast.Rule, result ast.Object) {
+	body := make(ast.Body, 0, len(rule.Head.Args)+len(rule.Body))
+
+	for i, arg := range rule.Head.Args {
+		ref := argsRef.Append(ast.InternedTerm(i))
+		body = append(body, ast.Equality.Expr(arg.Copy(), ast.NewTerm(ref)))
+	}
+
+	body = append(body, rule.Body.Copy()...)
+
+	var rewritten ast.Body
+
+	qc := compiler.QueryCompiler().
+		WithContext(ast.NewQueryContext().WithPackage(module.Package).WithImports(module.Imports)).
+		WithStageAfterID(ast.StageRewriteDynamicTerms, ast.QueryCompilerStageDefinition{
+			Name:       "RegalCaptureBody",
+			MetricName: "regal_capture_body",
+			Stage: func(_ ast.QueryCompiler, body ast.Body) (ast.Body, error) {
+				rewritten = body
+
+				return body, nil
+			},
+		})
+
+	// errors are expected, but as long as the body made it to the type checking stage,
+	// the type environment is available for whatever could be inferred
+	_, _ = qc.Compile(body)
+
+	env := qc.TypeEnv()
+	if env == nil || rewritten == nil {
+		return
+	}
+
+	for _, expr := range rewritten {
+		ast.WalkTerms(expr, func(term *ast.Term) bool {
+			if ast.IsComprehension(term.Value) {
+				return true
+			}
+
+			switch term.Value.(type) {
+			case ast.Var, ast.Ref:
+			default:
+				return false
+			}
+
+			if term.Location == nil {
+				return false
+			}
+
+			key := locationKey(term.Location)
+			if result.Get(key) != nil {
+				return false
+			}
+
+			// operators have function types, and share their location with the call's output var
+			if tpe := env.Get(term); tpe != nil && !isFunction(tpe) {
+				result.Insert(key, ast.ObjectTerm(
+					ast.Item(ast.InternedTerm("type"), ast.StringTerm(types.Sprint(tpe))),
+					ast.Item(ast.InternedTerm("kinds"), ast.ArrayTerm(typeKinds(tpe)...)),
+				))
+			}
+
+			return false
+		})
+	}
+}
+
+// typeKinds returns the kinds of values allowed by tpe, or an empty slice if any kind is allowed.
+func typeKinds(tpe types.Type) []*ast.Term {
+	var kinds []string
+
+	switch t := tpe.(type) {
+	case types.Any:
+		if len(t) == 0 {
+			return []*ast.Term{}
+		}
+
+		for _, member := range t {
+			memberKinds := typeKinds(member)
+			if len(memberKinds) == 0 {
+				return memberKinds
+			}
+
+			for _, kind := range memberKinds {
+				kinds = append(kinds, string(kind.Value.(ast.String)))
+			}
+		}
+	case types.Null:
+		kinds = []string{"null"}
+	case types.Boolean:
+		kinds = []string{"boolean"}
+	case types.Number:
+		kinds = []string{"number"}
+	case types.String:
+		kinds = []string{"string"}
+	case *types.Array:
+		kinds = []string{"array"}
+	case *types.Object:
+		kinds = []string{"object"}
+	case *types.Set:
+		kinds = []string{"set"}
+	default:
+		return []*ast.Term{}
+	}
+
+	slices.Sort(kinds)
+
+	terms := make([]*ast.Term, 0, len(kinds))
+	for _, kind := range slices.Compact(kinds) {
+		terms = append(terms, ast.StringTerm(kind))
+	}
+
+	return terms
+}
+
+func isFunction(tpe types.Type) bool {
+	_, ok := tpe.(*types.Function)
+
+	return ok
+}
@@ -4,17 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/maphash"
 	"path/filepath"
 	"slices"
 
 	"github.com/open-policy-agent/opa/v1/ast"
+	outil "github.com/open-policy-agent/opa/v1/util"
 
+	"github.com/open-policy-agent/regal/internal/parse"
 	"github.com/open-policy-agent/regal/internal/util"
 	"github.com/open-policy-agent/regal/pkg/config"
 	"github.com/open-policy-agent/regal/pkg/fixer/fileprovider"
 	"github.com/open-policy-agent/regal/pkg/fixer/fixes"
 	"github.com/open-policy-agent/regal/pkg/linter"
 	"github.com/open-policy-agent/regal/pkg/report"
+	"github.com/open-policy-agent/regal/pkg/rules"
 )
 
 type OnConflictOperation string
@@ -24,12 +28,44 @@ const (
 	OnConflictRename OnConflictOperation = "rename"
 )
 
+// DefaultMaxPasses is the default maximum number of passes made to fix linter violations.
+const DefaultMaxPasses = 10
+
+// ErrStopFixing may be returned by a ConfirmFunc to stop fixing, while keeping the fixes applied so far.
+var ErrStopFixing = errors.New("fixing stopped")
+
+// ConfirmFunc is called with the contents of file before a fix is applied to it, and the result of the fix.
+// The fix is skipped if false is returned.
+type ConfirmFunc func(file, contents string, result fixes.FixResult) (bool, error)
+
 // Fixer must be instantiated via NewFixer.
 type Fixer struct {
 	registeredFixes     []fixes.Fix
+	registeredCodemods  []*fixes.Codemod
 	onConflictOperation OnConflictOperation
 	registeredRoots     []string
 	versionsMap         map[string]ast.RegoVersion
+	confirm             ConfirmFunc
+	maxPasses           int
+}
+
+// violationKey identifies a violation across fixes, where other fixes may have moved it to another row.
+type violationKey struct {
+	file, title, text string
+	column            int
+}
+
+func keyForViolation(violation report.Violation) violationKey {
+	key := violationKey{
+		file:   violation.Location.File,
+		title:  violation.Title,
+		column: violation.Location.Column,
+	}
+	if violation.Location.Text != nil {
+		key.text = *violation.Location.Text
+	}
+
+	return key
 }
 
 // NewFixer instantiates a Fixer.
@@ -38,6 +74,7 @@ func NewFixer() *Fixer {
 		registeredFixes:     make([]fixes.Fix, 0),
 		registeredRoots:     make([]string, 0),
 		onConflictOperation: OnConflictError,
+		maxPasses:           DefaultMaxPasses,
 	}
 }
 
@@ -75,6 +112,30 @@ func (f *Fixer) RegisterRoots(roots ...string) *Fixer {
 	return f
 }
 
+// SetMaxPasses sets the maximum number of passes made to fix linter violations, where each pass lints all
+// files and applies the fixes found.
+func (f *Fixer) SetMaxPasses(maxPasses int) *Fixer {
+	f.maxPasses = maxPasses
+
+	return f
+}
+
+// SetConfirmFunc sets a function to call before each fix is applied, which decides whether to apply it.
+// Fixes declined for a violation are not offered again for the same violation.
+func (f *Fixer) SetConfirmFunc(confirm ConfirmFunc) *Fixer {
+	f.confirm = confirm
+
+	return f
+}
+
+// RegisterCodemods sets the codemods to apply to all files, in the order provided. Unlike the other
+// fixes, codemods are not triggered by linter violations.
+func (f *Fixer) RegisterCodemods(codemods ...*fixes.Codemod) *Fixer {
+	f.registeredCodemods = append(f.registeredCodemods, codemods...)
+
+	return f
+}
+
 func (f *Fixer) GetFixForName(name string) (fixes.Fix, bool) {
 	for _, fix := range f.registeredFixes {
 		if fix.Name() == name {
@@ -88,6 +149,14 @@ func (f *Fixer) GetFixForName(name string) (fixes.Fix, bool) {
 func (f *Fixer) Fix(ctx context.Context, l *linter.Linter, fp fileprovider.FileProvider) (*Report, error) {
 	fixReport := NewReport()
 
+	if err := f.applyCodemods(ctx, l, fp, fixReport); err != nil {
+		if errors.Is(err, ErrStopFixing) {
+			return fixReport, nil
+		}
+
+		return nil, err
+	}
+
 	// If there are no registered fixes that require a linter, return the report
 	if len(f.registeredFixes) == 0 {
 		return fixReport, nil
@@ -95,6 +164,10 @@ func (f *Fixer) Fix(ctx context.Context, l *linter.Linter, fp fileprovider.FileP
 
 	// Apply fixes that require linter violation triggers
 	if err := f.applyLinterFixes(ctx, l, fp, fixReport); err != nil {
+		if errors.Is(err, ErrStopFixing) {
+			return fixReport, nil
+		}
+
 		return nil, err
 	}
 
@@ -161,7 +234,92 @@ func (f *Fixer) FixViolations(
 	return fixReport, nil
 }
 
-// applyLinterFixes handles the application of fixes that require linter violation triggers.
+// applyCodemods applies each registered codemod to all files provided by fp. A codemod failing for a file,
+// or producing edits that conflict or don't parse, is rolled back for that file, and the other files are
+// fixed as usual.
+func (f *Fixer) applyCodemods(
+	ctx context.Context,
+	l *linter.Linter,
+	fp fileprovider.FileProvider,
+	fixReport *Report,
+) error {
+	if len(f.registeredCodemods) == 0 {
+		return nil
+	}
+
+	config, err := l.GetConfig()
+	if err != nil {
+		return fmt.Errorf("failed to get config: %w", err)
+	}
+
+	files, err := fp.List()
+	if err != nil {
+		return fmt.Errorf("failed to list files: %w", err)
+	}
+
+	for _, codemod := range f.registeredCodemods {
+		for _, file := range files {
+			fc, err := fp.Get(file)
+			if err != nil {
+				return fmt.Errorf("failed to get file %s: %w", file, err)
+			}
+
+			abs, err := filepath.Abs(file)
+			if err != nil {
+				return fmt.Errorf("failed to get absolute path for %s: %w", file, err)
+			}
+
+			root := util.FindClosestMatchingRoot(abs, f.registeredRoots)
+
+			fixResults, err := codemod.Fix(ctx, &fixes.FixCandidate{
+				Filename:    file,
+				Contents:    fc,
+				RegoVersion: rules.RegoVersionFromMap(f.versionsMap, file, ast.RegoUndefined),
+			}, &fixes.RuntimeOptions{
+				BaseDir: root,
+				Config:  config,
+			})
+			if err != nil {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+
+				fixReport.AddRolledBack(RolledBackFix{Err: err, File: file, Title: codemod.Name(), Root: root})
+
+				continue
+			}
+
+			if len(fixResults) == 0 {
+				continue
+			}
+
+			if f.confirm != nil {
+				ok, err := f.confirm(file, fc, fixResults[0])
+				if err != nil {
+					return err
+				}
+
+				if !ok {
+					continue
+				}
+			}
+
+			if err := fp.Put(file, fixResults[0].Contents); err != nil {
+				return fmt.Errorf("failed to write fixed content to file %s: %w", file, err)
+			}
+
+			fixReport.AddFileFix(file, fixResults[0])
+		}
+	}
+
+	return nil
+}
+
+// applyLinterFixes applies fixes for linter violations in passes, where each pass lints all files, and then
+// applies the fixes for all violations found, as long as their edits don't overlap. Fixes overlapping with
+// others are left for the next pass, where their violations are found again in the updated files. Passes
+// continue until there's nothing more to fix, the maximum number of passes is reached, or the fixes return
+// the files to the state of an earlier pass, and would keep doing so.
 func (f *Fixer) applyLinterFixes(
 	ctx context.Context,
 	l *linter.Linter,
@@ -186,18 +344,23 @@ func (f *Fixer) applyLinterFixes(
 		return fmt.Errorf("failed to list files: %w", err)
 	}
 
-	var versionsMap map[string]ast.RegoVersion
-	if f.versionsMap != nil {
-		versionsMap = f.versionsMap
-	}
-
 	li, err := l.WithDisableAll(true).WithEnabledRules(fixableEnabledRules...).Prepare(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to prepare linter for fixing: %w", err)
 	}
 
-	for {
-		in, err := fp.ToInput(versionsMap)
+	declined := make(map[violationKey]struct{})
+	seed := maphash.MakeSeed()
+
+	state, err := filesState(fp, seed)
+	if err != nil {
+		return err
+	}
+
+	seen := map[uint64]struct{}{state: {}}
+
+	for passes := 0; ; passes++ {
+		in, err := fp.ToInput(f.versionsMap)
 		if err != nil {
 			return fmt.Errorf("failed to create linter input: %w", err)
 		}
@@ -207,51 +370,123 @@ func (f *Fixer) applyLinterFixes(
 			return fmt.Errorf("failed to lint before fixing: %w", err)
 		}
 
-		if len(rep.Violations) == 0 {
-			break
+		if passes == f.maxPasses {
+			// without running the fixes, this can't tell whether they'd change anything, but it's likely
+			convergence := Converged
+			if slices.ContainsFunc(rep.Violations, func(v report.Violation) bool {
+				_, fixable := f.GetFixForName(v.Title)
+				_, isDeclined := declined[keyForViolation(v)]
+
+				return fixable && !isDeclined
+			}) {
+				convergence = MaxPassesReached
+			}
+
+			fixReport.SetConvergence(convergence, passes)
+
+			return nil
 		}
 
-		fixMade, err := f.applyOneFix(l, fp, fixReport, rep.Violations, startingFiles)
-		if err != nil {
+		pass, err := f.collectFixes(l, fp, fixReport, rep.Violations, declined)
+		stopped := errors.Is(err, ErrStopFixing)
+
+		if err != nil && !stopped {
 			return err
 		}
 
-		if !fixMade {
-			break
+		if len(pass.edits) == 0 && len(pass.renames) == 0 {
+			fixReport.SetConvergence(Converged, passes)
+
+			return err
+		}
+
+		if err := f.applyPass(fp, fixReport, pass, startingFiles); err != nil {
+			return err
+		}
+
+		if stopped {
+			fixReport.SetConvergence(Converged, passes+1)
+
+			return ErrStopFixing
 		}
+
+		if state, err = filesState(fp, seed); err != nil {
+			return err
+		}
+
+		if _, ok := seen[state]; ok {
+			fixReport.SetConvergence(Oscillated, passes+1)
+
+			return nil
+		}
+
+		seen[state] = struct{}{}
 	}
+}
 
-	return nil
+// fixPass holds the fixes to apply in a pass, where the edits of each file are made to its contents from
+// the start of the pass.
+type fixPass struct {
+	contents map[string]string
+	edits    map[string][]pendingFix
+	renames  []fixes.FixResult
+}
+
+type pendingFix struct {
+	result fixes.FixResult
+	edits  []fixes.TextEdit
 }
 
-func (f *Fixer) applyOneFix(
+// overlaps reports whether any of the edits of the pending fix overlaps with any of the edits given.
+func (p pendingFix) overlaps(edits []fixes.TextEdit) bool {
+	return slices.ContainsFunc(p.edits, func(e fixes.TextEdit) bool {
+		return slices.ContainsFunc(edits, e.Overlaps)
+	})
+}
+
+// collectFixes runs the registered fixes for the violations found in a pass, and returns those to apply.
+// Fixes producing Rego that fails to parse are rolled back, and neither they nor fixes declined by the
+// confirm function are tried again for the same violation. If the confirm function stops fixing, the fixes
+// collected so far are returned along with ErrStopFixing.
+func (f *Fixer) collectFixes(
 	l *linter.Linter,
 	fp fileprovider.FileProvider,
 	fixReport *Report,
 	violations []report.Violation,
-	startingFiles []string,
-) (bool, error) {
+	declined map[violationKey]struct{},
+) (*fixPass, error) {
+	pass := &fixPass{contents: make(map[string]string), edits: make(map[string][]pendingFix)}
+
+	config, err := l.GetConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get config: %w", err)
+	}
+
 	for _, fixInstance := range f.registeredFixes {
 		for i := range violations {
 			if violations[i].Title != fixInstance.Name() {
 				continue
 			}
 
-			config, err := l.GetConfig()
-			if err != nil {
-				return false, fmt.Errorf("failed to get config: %w", err)
+			key := keyForViolation(violations[i])
+			if _, ok := declined[key]; ok {
+				continue
 			}
 
 			file := violations[i].Location.File
 
 			abs, err := filepath.Abs(file)
 			if err != nil {
-				return false, fmt.Errorf("failed to get absolute path for %s: %w", file, err)
+				return nil, fmt.Errorf("failed to get absolute path for %s: %w", file, err)
 			}
 
-			fc, err := fp.Get(file)
-			if err != nil {
-				return false, fmt.Errorf("failed to get file %s: %w", file, err)
+			fc, ok := pass.contents[file]
+			if !ok {
+				if fc, err = fp.Get(file); err != nil {
+					return nil, fmt.Errorf("failed to get file %s: %w", file, err)
+				}
+
+				pass.contents[file] = fc
 			}
 
 			fixResults, err := fixInstance.Fix(&fixes.FixCandidate{Filename: file, Contents: fc}, &fixes.RuntimeOptions{
@@ -260,7 +495,7 @@ func (f *Fixer) applyOneFix(
 				Locations: []report.Location{violations[i].Location},
 			})
 			if err != nil {
-				return false, fmt.Errorf("failed to fix %s: %w", file, err)
+				return nil, fmt.Errorf("failed to fix %s: %w", file, err)
 			}
 
 			if len(fixResults) == 0 {
@@ -268,26 +503,124 @@ func (f *Fixer) applyOneFix(
 			}
 
 			fixResult := fixResults[0]
+			edits := fixes.EditsBetween(fc, fixResult.Contents)
 
 			if fixResult.Rename != nil {
-				if err := f.handleRename(fp, fixReport, startingFiles, fixResult); err != nil {
-					return false, err
+				if slices.ContainsFunc(pass.renames, func(r fixes.FixResult) bool { return r.Rename.FromPath == file }) {
+					continue
+				}
+			} else {
+				if len(edits) == 0 {
+					continue
 				}
 
-				return true, nil
+				// left for the next pass, where the violation will be found again if not fixed by the other fix
+				if slices.ContainsFunc(pass.edits[file], func(p pendingFix) bool { return p.overlaps(edits) }) {
+					continue
+				}
+
+				if err := f.parse(file, fixResult.Contents); err != nil {
+					fixReport.AddRolledBack(RolledBackFix{Err: err, File: file, Title: fixResult.Title, Root: fixResult.Root})
+					declined[key] = struct{}{}
+
+					continue
+				}
 			}
 
-			if err := fp.Put(file, fixResult.Contents); err != nil {
-				return false, fmt.Errorf("failed to write fixed content to file %s: %w", file, err)
+			if f.confirm != nil {
+				ok, err := f.confirm(file, fc, fixResult)
+				if err != nil {
+					return pass, err
+				}
+
+				if !ok {
+					declined[key] = struct{}{}
+
+					continue
+				}
+			}
+
+			if fixResult.Rename != nil {
+				pass.renames = append(pass.renames, fixResult)
+			} else {
+				pass.edits[file] = append(pass.edits[file], pendingFix{result: fixResult, edits: edits})
 			}
+		}
+	}
+
+	return pass, nil
+}
+
+// applyPass applies the edits collected for each file, followed by any renames. While each fix is known to
+// produce valid Rego on its own, that is not necessarily true for the combination of edits from several
+// fixes, in which case only the first fix is applied, and the others are left for the next pass.
+func (f *Fixer) applyPass(fp fileprovider.FileProvider, fixReport *Report, pass *fixPass, startingFiles []string) error {
+	for _, file := range outil.KeysSorted(pass.edits) {
+		pending := pass.edits[file]
 
-			fixReport.AddFileFix(file, fixResult)
+		edits := make([]fixes.TextEdit, 0, len(pending))
+		for _, p := range pending {
+			edits = append(edits, p.edits...)
+		}
+
+		contents, err := fixes.ApplyTextEdits(pass.contents[file], edits)
+		if err != nil || f.parse(file, contents) != nil {
+			contents, pending = pending[0].result.Contents, pending[:1]
+		}
+
+		if err := fp.Put(file, contents); err != nil {
+			return fmt.Errorf("failed to write fixed content to file %s: %w", file, err)
+		}
 
-			return true, nil
+		for _, p := range pending {
+			fixReport.AddFileFix(file, p.result)
 		}
 	}
 
-	return false, nil
+	for _, rename := range pass.renames {
+		if err := f.handleRename(fp, fixReport, startingFiles, rename); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// parse checks that contents of file, as updated by fixes, is still valid Rego.
+func (f *Fixer) parse(file, contents string) error {
+	opts := parse.ParserOptions()
+	opts.RegoVersion = rules.RegoVersionFromMap(f.versionsMap, file, ast.RegoUndefined)
+
+	_, err := parse.ModuleWithOpts(file, contents, opts)
+
+	return err
+}
+
+// filesState returns a hash of the names and contents of all files, for telling whether fixes have brought
+// the files back to an earlier state.
+func filesState(fp fileprovider.FileProvider, seed maphash.Seed) (uint64, error) {
+	files, err := fp.List()
+	if err != nil {
+		return 0, fmt.Errorf("failed to list files: %w", err)
+	}
+
+	var h maphash.Hash
+
+	h.SetSeed(seed)
+
+	for _, file := range util.Sorted(files) {
+		contents, err := fp.Get(file)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get file %s: %w", file, err)
+		}
+
+		h.WriteString(file)
+		h.WriteByte(0)
+		h.WriteString(contents)
+		h.WriteByte(0)
+	}
+
+	return h.Sum64(), nil
 }
 
 // handleRename processes the rename operation and resolves conflicts if necessary.
@@ -3,6 +3,7 @@ package fixer
 import (
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/open-policy-agent/opa/v1/ast"
@@ -242,6 +243,292 @@ func TestFixerWhereFixAltersFileLength(t *testing.T) {
 	}
 }
 
+func TestFixerCodemods(t *testing.T) {
+	t.Parallel()
+
+	rootPath := must.Return(filepath.Abs(filepath.FromSlash("/root")))(t)
+	changed, unchanged := filepath.Join(rootPath, "a.rego"), filepath.Join(rootPath, "b.rego")
+
+	memfp := fileprovider.NewInMemoryFileProvider(map[string]string{
+		changed:   "package a\n\nx := data.old.path\n",
+		unchanged: "package b\n\nx := data.new.path\n",
+	})
+
+	codemod := must.Return(fixes.NewCodemod(t.Context(), "rename.rego", `package rename
+
+edits contains {"location": ref.location, "text": "data.new.path"} if {
+	walk(input.rules, [_, ref])
+	ref.type == "ref"
+	ref.value[1].value == "old"
+}
+`))(t)
+
+	l := linter.NewLinter()
+
+	fixReport, err := NewFixer().RegisterCodemods(codemod).RegisterRoots(rootPath).Fix(t.Context(), &l, memfp)
+	if err != nil {
+		t.Fatalf("failed to fix: %v", err)
+	}
+
+	if got, exp := fixReport.TotalFixes(), uint(1); got != exp {
+		t.Fatalf("expected %d fixes, got %d", exp, got)
+	}
+
+	if content := must.Return(memfp.Get(changed))(t); content != "package a\n\nx := data.new.path\n" {
+		t.Fatalf("unexpected content:\n%s", content)
+	}
+
+	if fixed := memfp.ModifiedFiles(); !slices.Equal(fixed, []string{changed}) {
+		t.Fatalf("expected only %s to be modified, got %v", changed, fixed)
+	}
+}
+
+func TestFixerCodemodInvalidResultRolledBack(t *testing.T) {
+	t.Parallel()
+
+	rootPath := must.Return(filepath.Abs(filepath.FromSlash("/root")))(t)
+	valid, invalid := filepath.Join(rootPath, "a.rego"), filepath.Join(rootPath, "b.rego")
+
+	memfp := fileprovider.NewInMemoryFileProvider(map[string]string{
+		valid:   "package a\n\nx := data.old.path\n",
+		invalid: "package b\n\nx := data.old.broken\n",
+	})
+
+	// replacing data.old.broken leaves b.rego unparseable, which must not stop a.rego from being fixed
+	codemod := must.Return(fixes.NewCodemod(t.Context(), "rename.rego", `package rename
+
+edits contains {"location": ref.location, "text": text} if {
+	walk(input.rules, [_, ref])
+	ref.type == "ref"
+	ref.value[1].value == "old"
+
+	text := {"path": "data.new.path", "broken": "data.new.path +"}[ref.value[2].value]
+}
+`))(t)
+
+	l := linter.NewLinter()
+
+	fixReport, err := NewFixer().RegisterCodemods(codemod).RegisterRoots(rootPath).Fix(t.Context(), &l, memfp)
+	if err != nil {
+		t.Fatalf("failed to fix: %v", err)
+	}
+
+	if got, exp := fixReport.TotalFixes(), uint(1); got != exp {
+		t.Fatalf("expected %d fixes, got %d", exp, got)
+	}
+
+	rolledBack := fixReport.RolledBack()
+	if len(rolledBack) != 1 || rolledBack[0].File != invalid || rolledBack[0].Title != "rename" {
+		t.Fatalf("expected codemod rename to be rolled back for %s, got %v", invalid, rolledBack)
+	}
+
+	if !strings.Contains(rolledBack[0].Err.Error(), "produced invalid Rego") {
+		t.Fatalf("expected error for invalid Rego, got %v", rolledBack[0].Err)
+	}
+
+	if fixed := memfp.ModifiedFiles(); !slices.Equal(fixed, []string{valid}) {
+		t.Fatalf("expected only %s to be modified, got %v", valid, fixed)
+	}
+}
+
+func TestFixerConfirmFunc(t *testing.T) {
+	t.Parallel()
+
+	rootPath := must.Return(filepath.Abs(filepath.FromSlash("/root")))(t)
+	mainDir := filepath.Join(rootPath, "main")
+	mainRegoFile := filepath.Join(mainDir, "main.rego")
+	versionsMap := map[string]ast.RegoVersion{mainDir: ast.RegoV1}
+
+	testCases := map[string]struct {
+		confirm         func(title string) (bool, error)
+		expectedFixes   []string
+		expectedContent string
+	}{
+		"all confirmed": {
+			confirm:         func(string) (bool, error) { return true, nil },
+			expectedFixes:   []string{"use-assignment-operator", "no-whitespace-comment"},
+			expectedContent: "package main\n\nallow := true\n\n# no space\n",
+		},
+		"one declined": {
+			confirm:         func(title string) (bool, error) { return title != "use-assignment-operator", nil },
+			expectedFixes:   []string{"no-whitespace-comment"},
+			expectedContent: "package main\n\nallow = true\n\n# no space\n",
+		},
+		"stopped": {
+			confirm: func(title string) (bool, error) {
+				if title == "no-whitespace-comment" {
+					return false, ErrStopFixing
+				}
+
+				return true, nil
+			},
+			expectedFixes:   []string{"use-assignment-operator"},
+			expectedContent: "package main\n\nallow := true\n\n#no space\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			memfp := fileprovider.NewInMemoryFileProvider(map[string]string{
+				mainRegoFile: "package main\n\nallow = true\n\n#no space\n",
+			})
+
+			input := must.Return(memfp.ToInput(versionsMap))(t)
+			l := linter.NewLinter().WithEnableAll(true).WithInputModules(&input)
+
+			var offered []string
+
+			f := NewFixer().
+				RegisterFixes(&fixes.UseAssignmentOperator{}, &fixes.NoWhitespaceComment{}).
+				RegisterRoots(rootPath).
+				SetRegoVersionsMap(versionsMap).
+				SetConfirmFunc(func(_, _ string, result fixes.FixResult) (bool, error) {
+					offered = append(offered, result.Title)
+
+					return tc.confirm(result.Title)
+				})
+
+			fixReport := must.Return(f.Fix(t.Context(), &l, memfp))(t)
+
+			fixed := make([]string, 0)
+			for _, fx := range fixReport.FixesForFile(mainRegoFile) {
+				fixed = append(fixed, fx.Title)
+			}
+
+			if !slices.Equal(tc.expectedFixes, fixed) {
+				t.Errorf("expected fixes %v, got %v", tc.expectedFixes, fixed)
+			}
+
+			// declined fixes are not offered again
+			if len(offered) != len(slices.Compact(slices.Sorted(slices.Values(offered)))) {
+				t.Errorf("expected each fix to be offered once, got %v", offered)
+			}
+
+			if content := must.Return(memfp.Get(mainRegoFile))(t); content != tc.expectedContent {
+				t.Errorf("unexpected content:\n%s", content)
+			}
+		})
+	}
+}
+
+func TestFixerPasses(t *testing.T) {
+	t.Parallel()
+
+	rootPath := must.Return(filepath.Abs(filepath.FromSlash("/root")))(t)
+	mainDir := filepath.Join(rootPath, "main")
+	mainRegoFile := filepath.Join(mainDir, "main.rego")
+	versionsMap := map[string]ast.RegoVersion{mainDir: ast.RegoV1}
+
+	// fixes undoing the fix of the other, where the fix of one re-introduces the violation of the other
+	undoingFixes := []fixes.Fix{
+		&replacerFix{name: "use-assignment-operator", replacer: strings.NewReplacer("x = 1", "x := 1", "# c", "#c")},
+		&replacerFix{name: "no-whitespace-comment", replacer: strings.NewReplacer("#c", "# c", "x := 1", "x = 1")},
+	}
+
+	testCases := map[string]struct {
+		policy              string
+		fixes               []fixes.Fix
+		maxPasses           int
+		expectedConvergence Convergence
+		expectedPasses      int
+		expectedRolledBack  []string
+		expectedContent     string
+	}{
+		"converged": {
+			policy:              "package main\n\n#c\nx = 1\n",
+			fixes:               []fixes.Fix{&fixes.UseAssignmentOperator{}, &fixes.NoWhitespaceComment{}},
+			maxPasses:           DefaultMaxPasses,
+			expectedConvergence: Converged,
+			expectedPasses:      1,
+			expectedContent:     "package main\n\n# c\nx := 1\n",
+		},
+		"oscillated": {
+			policy:              "package main\n\n# c\nx = 1\n",
+			fixes:               undoingFixes,
+			maxPasses:           DefaultMaxPasses,
+			expectedConvergence: Oscillated,
+			expectedPasses:      2,
+			expectedContent:     "package main\n\n# c\nx = 1\n",
+		},
+		"max passes reached": {
+			policy:              "package main\n\n# c\nx = 1\n",
+			fixes:               undoingFixes,
+			maxPasses:           1,
+			expectedConvergence: MaxPassesReached,
+			expectedPasses:      1,
+			expectedContent:     "package main\n\n#c\nx := 1\n",
+		},
+		"invalid fix rolled back": {
+			policy: "package main\n\n#c\nx = 1\n",
+			fixes: []fixes.Fix{
+				&replacerFix{name: "use-assignment-operator", replacer: strings.NewReplacer("x = 1", "x := := 1")},
+				&fixes.NoWhitespaceComment{},
+			},
+			maxPasses:           DefaultMaxPasses,
+			expectedConvergence: Converged,
+			expectedPasses:      1,
+			expectedRolledBack:  []string{"use-assignment-operator"},
+			expectedContent:     "package main\n\n# c\nx = 1\n",
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			memfp := fileprovider.NewInMemoryFileProvider(map[string]string{mainRegoFile: tc.policy})
+			input := must.Return(memfp.ToInput(versionsMap))(t)
+			l := linter.NewLinter().WithEnableAll(true).WithInputModules(&input)
+
+			f := NewFixer().
+				RegisterFixes(tc.fixes...).
+				RegisterRoots(rootPath).
+				SetRegoVersionsMap(versionsMap).
+				SetMaxPasses(tc.maxPasses)
+
+			fixReport := must.Return(f.Fix(t.Context(), &l, memfp))(t)
+
+			if fixReport.Convergence() != tc.expectedConvergence || fixReport.Passes() != tc.expectedPasses {
+				t.Errorf("expected %s after %d passes, got %s after %d passes",
+					tc.expectedConvergence, tc.expectedPasses, fixReport.Convergence(), fixReport.Passes())
+			}
+
+			var rolledBack []string
+			for _, fix := range fixReport.RolledBack() {
+				rolledBack = append(rolledBack, fix.Title)
+			}
+
+			if !slices.Equal(tc.expectedRolledBack, rolledBack) {
+				t.Errorf("expected rolled back fixes %v, got %v", tc.expectedRolledBack, rolledBack)
+			}
+
+			if content := must.Return(memfp.Get(mainRegoFile))(t); content != tc.expectedContent {
+				t.Errorf("unexpected content:\n%s", content)
+			}
+		})
+	}
+}
+
+// replacerFix fixes violations of the named rule by replacing strings in the whole file.
+type replacerFix struct {
+	replacer *strings.Replacer
+	name     string
+}
+
+func (r *replacerFix) Name() string {
+	return r.name
+}
+
+func (r *replacerFix) Fix(fc *fixes.FixCandidate, _ *fixes.RuntimeOptions) ([]fixes.FixResult, error) {
+	if contents := r.replacer.Replace(fc.Contents); contents != fc.Contents {
+		return []fixes.FixResult{{Title: r.name, Contents: contents}}, nil
+	}
+
+	return nil, nil
+}
+
 // 150116720 ns/op  101567417 B/op   2359322 allocs/op
 // 132816578 ns/op   89093239 B/op   2068892 allocs/op // Linter.Prepare()
 func BenchmarkFixViolations(b *testing.B) {
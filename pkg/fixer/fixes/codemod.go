@@ -0,0 +1,189 @@
+package fixes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/rego"
+
+	rbundle "github.com/open-policy-agent/regal/bundle"
+	"github.com/open-policy-agent/regal/internal/parse"
+	"github.com/open-policy-agent/regal/pkg/roast/encoding"
+	"github.com/open-policy-agent/regal/pkg/roast/transform"
+)
+
+// Codemod is a fix defined by a Rego policy rather than by a linter rule, for one-off rewrites across
+// many files, like replacing calls to a deprecated function. The policy is evaluated with the RoAST of
+// each file as input, and the edits rule of the policy is expected to contain objects with the location
+// of the text to replace, in the row:col:endRow:endCol format used in RoAST, and the replacement text:
+//
+//	edits contains {"location": expr.location, "text": "k in object.keys(x)"} if { ... }
+//
+// Empty text removes the text at the location. The Regal bundle is loaded when evaluating the policy,
+// so helpers like data.regal.ast may be used just like in linter rules.
+type Codemod struct {
+	name  string
+	query rego.PreparedEvalQuery
+}
+
+// CodemodEdit is a replacement of the text at Location in a file, as produced by a codemod policy.
+type CodemodEdit struct {
+	Location string `json:"location"`
+	Text     string `json:"text"`
+}
+
+// NewCodemod parses the codemod policy and prepares its edits rule for evaluation. The name of the
+// codemod is the path of its package, without the data prefix.
+func NewCodemod(ctx context.Context, filename, policy string) (*Codemod, error) {
+	module, err := parse.ModuleUnknownVersionWithOpts(filename, policy, parse.ParserOptions())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse codemod: %w", err)
+	}
+
+	query, err := rego.New(
+		rego.Query(module.Package.Path.Append(ast.InternedTerm("edits")).String()),
+		rego.ParsedModule(module),
+		rego.ParsedBundle("regal", rbundle.Loaded()),
+		rego.StoreReadAST(true),
+	).PrepareForEval(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare codemod: %w", err)
+	}
+
+	return &Codemod{name: strings.TrimPrefix(module.Package.Path.String(), "data."), query: query}, nil
+}
+
+func (c *Codemod) Name() string {
+	return c.name
+}
+
+// Fix applies the edits of the codemod to the file, or returns an error if the codemod fails, or its edits
+// conflict or leave the file unparseable.
+func (c *Codemod) Fix(ctx context.Context, fc *FixCandidate, opts *RuntimeOptions) ([]FixResult, error) {
+	if opts == nil {
+		return nil, errors.New("missing runtime options")
+	}
+
+	popts := parse.ParserOptions()
+	if fc.RegoVersion != ast.RegoUndefined {
+		popts.RegoVersion = fc.RegoVersion
+	}
+
+	module, err := parse.ModuleWithOpts(fc.Filename, fc.Contents, popts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse module: %w", err)
+	}
+
+	edits, err := c.Edits(ctx, fc.Filename, fc.Contents, module)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(edits) == 0 {
+		return nil, nil
+	}
+
+	contents, err := ApplyEdits(fc.Contents, edits)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply edits of codemod %s to %s: %w", c.name, fc.Filename, err)
+	}
+
+	if contents == fc.Contents {
+		return nil, nil
+	}
+
+	// catch broken replacements here, rather than leaving the user with files that no longer parse
+	popts.RegoVersion = module.RegoVersion()
+	if _, err = parse.ModuleWithOpts(fc.Filename, contents, popts); err != nil {
+		return nil, fmt.Errorf("codemod %s produced invalid Rego in %s: %w", c.name, fc.Filename, err)
+	}
+
+	return []FixResult{{Title: c.Name(), Root: opts.BaseDir, Contents: contents}}, nil
+}
+
+// Edits evaluates the codemod policy with the RoAST of module as input, and returns the edits produced.
+func (c *Codemod) Edits(ctx context.Context, filename, contents string, module *ast.Module) ([]CodemodEdit, error) {
+	input, err := transform.ToAST(filename, contents, module, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create codemod input: %w", err)
+	}
+
+	rs, err := c.query.Eval(ctx, rego.EvalParsedInput(input))
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate codemod %s: %w", c.name, err)
+	}
+
+	if len(rs) == 0 {
+		return nil, nil
+	}
+
+	edits, err := encoding.JSONRoundTripTo[[]CodemodEdit](rs[0].Expressions[0].Value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid edits from codemod %s: %w", c.name, err)
+	}
+
+	return edits, nil
+}
+
+// ApplyEdits replaces the text at the location of each edit in contents. Edits with overlapping locations
+// conflict, and are reported as an error, as there's no telling which of them should win.
+func ApplyEdits(contents string, edits []CodemodEdit) (string, error) {
+	lineOffsets := []int{0}
+
+	for i := range len(contents) {
+		if contents[i] == '\n' {
+			lineOffsets = append(lineOffsets, i+1)
+		}
+	}
+
+	offset := func(row, col int) (int, bool) {
+		if row < 1 || row > len(lineOffsets) || col < 1 {
+			return 0, false
+		}
+
+		offset := lineOffsets[row-1] + col - 1
+
+		return offset, offset <= len(contents)
+	}
+
+	textEdits := make([]TextEdit, 0, len(edits))
+
+	for _, edit := range edits {
+		parts := strings.Split(edit.Location, ":")
+		if len(parts) != 4 {
+			return "", fmt.Errorf("invalid location %q, expected row:col:endRow:endCol", edit.Location)
+		}
+
+		var coords [4]int
+
+		for i, part := range parts {
+			n, err := strconv.Atoi(part)
+			if err != nil {
+				return "", fmt.Errorf("invalid location %q: %w", edit.Location, err)
+			}
+
+			coords[i] = n
+		}
+
+		start, ok1 := offset(coords[0], coords[1])
+		end, ok2 := offset(coords[2], coords[3])
+
+		if !ok1 || !ok2 || end < start {
+			return "", fmt.Errorf("location %q is out of bounds", edit.Location)
+		}
+
+		textEdit := TextEdit{Start: start, End: end, Text: edit.Text}
+
+		// identical edits don't conflict, as would be the case for an edits rule producing an array
+		if !slices.Contains(textEdits, textEdit) {
+			textEdits = append(textEdits, textEdit)
+		}
+	}
+
+	return ApplyTextEdits(contents, textEdits)
+}
@@ -0,0 +1,139 @@
+package fixes
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/regal/internal/test/assert"
+	"github.com/open-policy-agent/regal/internal/test/must"
+)
+
+const hasKeyCodemod = `package codemod.has_key
+
+import data.regal.ast
+
+edits contains {"location": expr.location, "text": text} if {
+	walk(input.rules, [_, expr])
+	ast.ref_to_string(expr.terms[0].value) == "data.lib.util.has_key"
+
+	text := sprintf("%s in object.keys(%s)", [expr.terms[2].value, ast.ref_to_string(expr.terms[1].value)])
+}
+`
+
+func TestCodemod(t *testing.T) {
+	t.Parallel()
+
+	codemod := must.Return(NewCodemod(t.Context(), "has_key.rego", hasKeyCodemod))(t)
+
+	assert.Equal(t, "codemod.has_key", codemod.Name())
+
+	fc := &FixCandidate{Filename: "p.rego", Contents: `package p
+
+allow if {
+	data.lib.util.has_key(input, k)
+	data.lib.util.has_key(input.user, k)
+}
+`}
+
+	results := must.Return(codemod.Fix(t.Context(), fc, &RuntimeOptions{BaseDir: "/root"}))(t)
+
+	assert.Equal(t, 1, len(results), "number of results")
+	assert.Equal(t, "codemod.has_key", results[0].Title)
+	assert.Equal(t, "/root", results[0].Root)
+	assert.Equal(t, `package p
+
+allow if {
+	k in object.keys(input)
+	k in object.keys(input.user)
+}
+`, results[0].Contents)
+
+	// nothing left to replace
+	fc.Contents = results[0].Contents
+	results = must.Return(codemod.Fix(t.Context(), fc, &RuntimeOptions{}))(t)
+
+	assert.Equal(t, 0, len(results), "number of results")
+}
+
+func TestCodemodInvalidResult(t *testing.T) {
+	t.Parallel()
+
+	codemod := must.Return(NewCodemod(t.Context(), "c.rego", `package c
+
+edits contains {"location": input.package.location, "text": "packag"}
+`))(t)
+
+	fc := &FixCandidate{Filename: "p.rego", Contents: "package p\n"}
+	if _, err := codemod.Fix(t.Context(), fc, &RuntimeOptions{}); err == nil {
+		t.Fatal("expected error for codemod producing invalid Rego")
+	}
+}
+
+func TestApplyEdits(t *testing.T) {
+	t.Parallel()
+
+	contents := "package p\n\nx := 1\ny := [1, 2]\n"
+
+	testCases := map[string]struct {
+		edits    []CodemodEdit
+		expected string
+		err      bool
+	}{
+		"no edits": {
+			expected: contents,
+		},
+		"replace": {
+			edits:    []CodemodEdit{{Location: "3:6:3:7", Text: "2"}},
+			expected: "package p\n\nx := 2\ny := [1, 2]\n",
+		},
+		"replace several in order of location": {
+			edits: []CodemodEdit{
+				{Location: "3:6:3:7", Text: "true"},
+				{Location: "4:6:4:12", Text: "{1, 2}"},
+				{Location: "1:9:1:10", Text: "policy"},
+			},
+			expected: "package policy\n\nx := true\ny := {1, 2}\n",
+		},
+		"remove across rows": {
+			edits:    []CodemodEdit{{Location: "3:1:4:1"}},
+			expected: "package p\n\ny := [1, 2]\n",
+		},
+		"insert": {
+			edits:    []CodemodEdit{{Location: "3:1:3:1", Text: "# one\n"}},
+			expected: "package p\n\n# one\nx := 1\ny := [1, 2]\n",
+		},
+		"identical edits": {
+			edits:    []CodemodEdit{{Location: "3:6:3:7", Text: "2"}, {Location: "3:6:3:7", Text: "2"}},
+			expected: "package p\n\nx := 2\ny := [1, 2]\n",
+		},
+		"overlapping edits": {
+			edits: []CodemodEdit{{Location: "4:6:4:12", Text: "[]"}, {Location: "4:7:4:8", Text: "3"}},
+			err:   true,
+		},
+		"invalid location": {
+			edits: []CodemodEdit{{Location: "3:6", Text: "2"}},
+			err:   true,
+		},
+		"location out of bounds": {
+			edits: []CodemodEdit{{Location: "9:1:9:2", Text: "2"}},
+			err:   true,
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := ApplyEdits(contents, tc.edits)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error, got result %q", result)
+				}
+
+				return
+			}
+
+			must.Equal(t, nil, err)
+			assert.Equal(t, tc.expected, result)
+		})
+	}
+}
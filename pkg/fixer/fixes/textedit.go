@@ -0,0 +1,85 @@
+package fixes
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/open-policy-agent/regal/internal/diff"
+)
+
+// TextEdit replaces the text between the Start and End byte offsets of a file with Text.
+type TextEdit struct {
+	Text       string
+	Start, End int
+}
+
+// EditsBetween returns the edits turning before into after, with one edit for each run of lines that
+// differ between the two, narrowed down to span from the first to the last byte that differs in them.
+func EditsBetween(before, after string) []TextEdit {
+	changes := diff.Changes(before, after)
+	edits := make([]TextEdit, 0, len(changes))
+
+	for _, change := range changes {
+		old := before[change.Start:change.End]
+
+		prefix := 0
+		for prefix < len(old) && prefix < len(change.Text) && old[prefix] == change.Text[prefix] {
+			prefix++
+		}
+
+		suffix := 0
+		for suffix < len(old)-prefix && suffix < len(change.Text)-prefix &&
+			old[len(old)-1-suffix] == change.Text[len(change.Text)-1-suffix] {
+			suffix++
+		}
+
+		edits = append(edits, TextEdit{
+			Start: change.Start + prefix,
+			End:   change.End - suffix,
+			Text:  change.Text[prefix : len(change.Text)-suffix],
+		})
+	}
+
+	return edits
+}
+
+// Overlaps reports whether the edits touch the same text. Insertions at the same offset overlap too, as
+// the order to insert them in would be ambiguous.
+func (e TextEdit) Overlaps(other TextEdit) bool {
+	return e.Start == other.Start || (e.Start < other.End && other.Start < e.End)
+}
+
+// ApplyTextEdits applies the edits to contents, or returns an error if any of them overlap. Edits are
+// applied from the end of the file and backwards, so that replacing text doesn't invalidate the offsets
+// of the edits before it.
+func ApplyTextEdits(contents string, edits []TextEdit) (string, error) {
+	sorted := slices.SortedFunc(slices.Values(edits), func(a, b TextEdit) int {
+		return cmp.Or(cmp.Compare(b.Start, a.Start), cmp.Compare(b.End, a.End))
+	})
+
+	var sb strings.Builder
+
+	result := contents
+
+	for i, edit := range sorted {
+		if edit.Start < 0 || edit.End < edit.Start || edit.End > len(contents) {
+			return "", fmt.Errorf("edit at offsets %d to %d is out of bounds", edit.Start, edit.End)
+		}
+
+		if i > 0 && edit.Overlaps(sorted[i-1]) {
+			return "", fmt.Errorf("conflicting edits at overlapping locations in %q and %q",
+				contents[edit.Start:edit.End], contents[sorted[i-1].Start:sorted[i-1].End])
+		}
+
+		sb.Reset()
+		sb.WriteString(result[:edit.Start])
+		sb.WriteString(edit.Text)
+		sb.WriteString(result[edit.End:])
+
+		result = sb.String()
+	}
+
+	return result, nil
+}
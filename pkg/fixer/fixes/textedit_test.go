@@ -0,0 +1,75 @@
+package fixes
+
+import (
+	"testing"
+
+	"github.com/open-policy-agent/regal/internal/test/assert"
+	"github.com/open-policy-agent/regal/internal/test/must"
+)
+
+func TestEditsBetween(t *testing.T) {
+	t.Parallel()
+
+	testCases := map[string]struct {
+		before, after string
+		expected      []TextEdit
+	}{
+		"equal":   {before: "x := 1", after: "x := 1", expected: []TextEdit{}},
+		"replace": {before: "x = 1", after: "x := 1", expected: []TextEdit{{Start: 2, End: 2, Text: ":"}}},
+		"remove":  {before: "a\nb\nc\n", after: "a\nc\n", expected: []TextEdit{{Start: 2, End: 4}}},
+		"append":  {before: "a", after: "ab", expected: []TextEdit{{Start: 1, End: 1, Text: "b"}}},
+		"repeated": {
+			// the common suffix must not overlap with the common prefix
+			before: "aa", after: "aaa", expected: []TextEdit{{Start: 2, End: 2, Text: "a"}},
+		},
+		"separate lines": {
+			// changes far apart are separate edits, rather than one edit spanning the lines in between
+			before:   "package p\n\nx = 1\n\ny := 2\n\nz = 3\n",
+			after:    "package p\n\nx := 1\n\ny := 2\n\nz := 3\n",
+			expected: []TextEdit{{Start: 13, End: 13, Text: ":"}, {Start: 28, End: 28, Text: ":"}},
+		},
+	}
+
+	for name, tc := range testCases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			edits := EditsBetween(tc.before, tc.after)
+
+			assert.SlicesEqual(t, tc.expected, edits)
+			assert.Equal(t, tc.after, must.Return(ApplyTextEdits(tc.before, edits))(t))
+		})
+	}
+}
+
+func TestTextEditOverlaps(t *testing.T) {
+	t.Parallel()
+
+	edit := TextEdit{Start: 2, End: 5}
+
+	assert.True(t, edit.Overlaps(TextEdit{Start: 4, End: 8}))
+	assert.True(t, edit.Overlaps(TextEdit{Start: 0, End: 3}))
+	assert.True(t, edit.Overlaps(TextEdit{Start: 3, End: 3}))
+	assert.True(t, edit.Overlaps(TextEdit{Start: 2, End: 2}))
+	assert.False(t, edit.Overlaps(TextEdit{Start: 5, End: 7}))
+	assert.False(t, edit.Overlaps(TextEdit{Start: 0, End: 2}))
+}
+
+func TestApplyTextEdits(t *testing.T) {
+	t.Parallel()
+
+	result := must.Return(ApplyTextEdits("x = 1\ny = 2\n", []TextEdit{
+		{Start: 2, End: 3, Text: ":="},
+		{Start: 8, End: 9, Text: ":="},
+	}))(t)
+
+	assert.Equal(t, "x := 1\ny := 2\n", result)
+
+	if _, err := ApplyTextEdits("x = 1\n", []TextEdit{{Start: 0, End: 3}, {Start: 2, End: 5}}); err == nil {
+		t.Error("expected error for overlapping edits")
+	}
+
+	if _, err := ApplyTextEdits("x = 1\n", []TextEdit{{Start: 4, End: 10}}); err == nil {
+		t.Error("expected error for edit out of bounds")
+	}
+}
@@ -6,6 +6,27 @@ import (
 	"github.com/open-policy-agent/regal/pkg/fixer/fixes"
 )
 
+// Convergence describes how the passes of a fix operation ended.
+type Convergence string
+
+const (
+	// Converged means that no more fixes could be applied.
+	Converged Convergence = "converged"
+	// Oscillated means that fixes returned the files to the state of an earlier pass, and would have
+	// continued to do so if not stopped.
+	Oscillated Convergence = "oscillated"
+	// MaxPassesReached means that fixes could still be applied when the maximum number of passes was reached.
+	MaxPassesReached Convergence = "max-passes-reached"
+)
+
+// RolledBackFix is a fix that was not applied, as it failed, or the result of it failed to parse.
+type RolledBackFix struct {
+	Err   error
+	File  string
+	Title string
+	Root  string
+}
+
 // Report contains updated file contents and summary information about the fixes that were applied
 // during a fix operation.
 type Report struct {
@@ -13,7 +34,10 @@ type Report struct {
 	movedFiles          map[string][]string
 	conflictsManyToOne  map[string]map[string][]string
 	conflictsSourceFile map[string]map[string][]string
+	convergence         Convergence
+	rolledBack          []RolledBackFix
 	totalFixes          uint
+	passes              int
 }
 
 func NewReport() *Report {
@@ -22,6 +46,7 @@ func NewReport() *Report {
 		movedFiles:          make(map[string][]string),
 		conflictsManyToOne:  make(map[string]map[string][]string),
 		conflictsSourceFile: make(map[string]map[string][]string),
+		convergence:         Converged,
 	}
 }
 
@@ -89,3 +114,26 @@ func (r *Report) RegisterConflictSourceFile(root, newPath, oldPath string) {
 func (r *Report) HasConflicts() bool {
 	return len(r.conflictsManyToOne) > 0 || len(r.conflictsSourceFile) > 0
 }
+
+// SetConvergence records how the passes of the fix operation ended, and the number of passes made.
+func (r *Report) SetConvergence(convergence Convergence, passes int) {
+	r.convergence = convergence
+	r.passes = passes
+}
+
+func (r *Report) Convergence() Convergence {
+	return r.convergence
+}
+
+func (r *Report) Passes() int {
+	return r.passes
+}
+
+func (r *Report) AddRolledBack(fix RolledBackFix) {
+	r.rolledBack = append(r.rolledBack, fix)
+}
+
+// RolledBack returns the fixes that were not applied, as they failed or produced Rego that failed to parse.
+func (r *Report) RolledBack() []RolledBackFix {
+	return r.rolledBack
+}
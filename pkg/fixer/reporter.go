@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"io"
 	"path/filepath"
+	"strings"
 
 	"github.com/open-policy-agent/opa/v1/util"
 
@@ -121,7 +122,7 @@ func (r *PrettyReporter) Report(fixReport *Report) error {
 	case 0:
 		fmt.Fprintf(r.outputWriter, "No fixes %s.\n", action)
 
-		return nil
+		return r.reportOutcome(fixReport)
 	case 1:
 		fmt.Fprintf(r.outputWriter, "1 fix %s:\n", action)
 	default:
@@ -176,6 +177,31 @@ func (r *PrettyReporter) Report(fixReport *Report) error {
 		i++
 	}
 
+	return r.reportOutcome(fixReport)
+}
+
+// reportOutcome reports fixes rolled back, and passes not converging, neither of which is expected when
+// all goes well, and so nothing is reported in that case.
+func (r *PrettyReporter) reportOutcome(fixReport *Report) error {
+	if rolledBack := fixReport.RolledBack(); len(rolledBack) > 0 {
+		fmt.Fprintln(r.outputWriter, "\nFixes rolled back, as they failed or produced invalid Rego:")
+
+		for _, fix := range rolledBack {
+			fmt.Fprintf(r.outputWriter, "- %s: %s\n  %v\n", relOrDefault(fix.Root, fix.File, fix.File), fix.Title,
+				strings.ReplaceAll(fix.Err.Error(), "\n", "\n  "))
+		}
+	}
+
+	switch fixReport.Convergence() {
+	case Oscillated:
+		fmt.Fprintf(r.outputWriter, "\nFixing stopped after %d passes, as fixes kept undoing each other.\n",
+			fixReport.Passes())
+	case MaxPassesReached:
+		fmt.Fprintf(r.outputWriter, "\nFixing stopped at the maximum number of passes (%d), "+
+			"and more fixes may be applied by running the fixer again.\n", fixReport.Passes())
+	case Converged:
+	}
+
 	return nil
 }
 
@@ -2,6 +2,7 @@ package fixer
 
 import (
 	"bytes"
+	"errors"
 	"testing"
 
 	"github.com/open-policy-agent/regal/internal/test/must"
@@ -144,3 +145,41 @@ Cannot move multiple files to: foo/policy1.rego
 
 	must.Equal(t, expected, buffer.String())
 }
+
+func TestPrettyReporterOutputWithRollbackAndNoConvergence(t *testing.T) {
+	t.Parallel()
+
+	var buffer bytes.Buffer
+
+	reporter := NewPrettyReporter(&buffer)
+	report := NewReport()
+
+	report.AddFileFix("/workspace/bundle1/policy1.rego", fixes.FixResult{
+		Title: "no-whitespace-comment",
+		Root:  "/workspace/bundle1",
+	})
+	report.AddRolledBack(RolledBackFix{
+		Err:   errors.New("1 error occurred:\npolicy1.rego:3: rego_parse_error: unexpected assign token"),
+		File:  "/workspace/bundle1/policy1.rego",
+		Title: "use-assignment-operator",
+		Root:  "/workspace/bundle1",
+	})
+	report.SetConvergence(Oscillated, 4)
+
+	must.Equal(t, nil, reporter.Report(report))
+
+	expected := `1 fix applied:
+In project root: /workspace/bundle1
+policy1.rego:
+- no-whitespace-comment
+
+Fixes rolled back, as they failed or produced invalid Rego:
+- policy1.rego: use-assignment-operator
+  1 error occurred:
+  policy1.rego:3: rego_parse_error: unexpected assign token
+
+Fixing stopped after 4 passes, as fixes kept undoing each other.
+`
+
+	must.Equal(t, expected, buffer.String())
+}
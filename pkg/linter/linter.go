@@ -9,9 +9,12 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime"
 	"slices"
 	"strings"
+	"sync"
 	"testing/fstest"
+	"time"
 
 	"golang.org/x/sync/errgroup"
 	"gopkg.in/yaml.v3"
@@ -27,9 +30,12 @@ import (
 
 	rbundle "github.com/open-policy-agent/regal/bundle"
 	rio "github.com/open-policy-agent/regal/internal/io"
+	"github.com/open-policy-agent/regal/internal/io/files"
+	"github.com/open-policy-agent/regal/internal/io/files/filter"
 	regalmetrics "github.com/open-policy-agent/regal/internal/metrics"
 	"github.com/open-policy-agent/regal/internal/ogre"
 	"github.com/open-policy-agent/regal/internal/util"
+	regalbuiltins "github.com/open-policy-agent/regal/pkg/builtins/regal"
 	"github.com/open-policy-agent/regal/pkg/config"
 	"github.com/open-policy-agent/regal/pkg/report"
 	"github.com/open-policy-agent/regal/pkg/roast/intern"
@@ -40,15 +46,27 @@ import (
 	_ "github.com/open-policy-agent/regal/pkg/builtins"
 )
 
-// Linter stores data to use for linting.
+// Linter stores data to use for linting. A Linter is a value, and each of the With* methods
+// returns a copy with the option applied, leaving the original unchanged. This allows a linter
+// to be configured and prepared once, using Prepare, and then be reused for any number of
+// calls to Lint, each with its own input provided via WithInputPaths, WithInputModules or
+// WithInputFS. A prepared linter is safe for concurrent use by multiple goroutines, provided
+// that options changing the rules or configuration (which require it to be prepared again)
+// aren't applied to the shared instance, and that any handlers, metrics or print hook shared
+// between concurrent calls are themselves safe for concurrent use.
 type Linter struct {
 	printHook         print.Hook
+	violationsHandler ViolationsHandler
+	progressHandler   ProgressHandler
 	metrics           metrics.Metrics
 	inputModules      *rules.Input
+	inputFS           fs.FS
 	userConfig        *config.Config
 	combinedCfg       *config.Config
 	pathPrefix        string
+	inputFSRoot       string
 	customRuleError   error
+	concurrency       int
 	inputPaths        []string
 	ruleBundles       []*bundle.Bundle
 	disable           []string
@@ -63,10 +81,34 @@ type Linter struct {
 	disableAll        bool
 	enableAll         bool
 	profiling         bool
+	stats             bool
+	complexity        bool
 	instrumentation   bool
 	isPrepared        bool
 
 	preparedQuery *ogre.Query
+	// rulesToRun is the set of rule titles by category determined to run when prepared
+	rulesToRun ast.Object
+}
+
+// ViolationsHandler is called with the violations found in a single file as soon as that
+// file has been linted, and with the violations from aggregate rules once those have been
+// evaluated. The violations provided may be empty. Calls are serialized, so handlers need
+// not be safe for concurrent use.
+type ViolationsHandler func(context.Context, []report.Violation) error
+
+// ProgressHandler is called with events describing the progress of linting, like a file
+// having been linted, or the aggregate phase having started. Calls are serialized, so handlers
+// need not be safe for concurrent use.
+type ProgressHandler func(report.ProgressEvent)
+
+// violationStream passes violations on to a ViolationsHandler, keeping count of what has
+// been passed, as violations aren't retained in the report when streaming.
+type violationStream struct {
+	handler       ViolationsHandler
+	filesFailed   *util.Set[string]
+	numViolations int
+	mu            sync.Mutex
 }
 
 var (
@@ -96,7 +138,33 @@ var (
 		)),
 	)
 
+	operationsLintStats        = ast.ArrayTerm(ast.InternedTerm("lint"), ast.InternedTerm("stats"))
+	operationsLintCollectStats = ast.ArrayTerm(
+		ast.InternedTerm("lint"), ast.InternedTerm("collect"), ast.InternedTerm("stats"),
+	)
+
+	aggregateStatsRegalObject = ast.ObjectTerm(
+		ast.Item(ast.InternedTerm("operations"), ast.ArrayTerm(
+			ast.InternedTerm("aggregate"),
+			ast.InternedTerm("stats"),
+		)),
+		ast.Item(ast.InternedTerm("file"), ast.ObjectTerm(
+			ast.Item(ast.InternedTerm("name"), ast.InternedTerm("__aggregate_report__")),
+			ast.Item(ast.InternedTerm("lines"), ast.InternedEmptyArray),
+		)),
+	)
+
 	preparedPath = storage.Path{"internal", "prepared"}
+
+	// dataFileRules are the rules using the contents of data files, which are only read when
+	// any of these rules is enabled
+	dataFileRules = []string{
+		"bugs/data-conflicts-with-rule",
+		"bugs/duplicate-data-key",
+		"bugs/invalid-data-file",
+		"imports/unresolved-import",
+		"imports/unresolved-reference",
+	}
 )
 
 func init() {
@@ -125,6 +193,18 @@ func (l Linter) WithInputPaths(paths []string) Linter {
 	return l
 }
 
+// WithInputFS sets a filesystem to read the policies to lint from, which may be any implementation
+// of fs.FS, like an in-memory filesystem, or one created with rules.NewOverlayFS. All Rego files
+// under root, which is "." for the whole filesystem, are linted, unless ignored by configuration.
+// File names in the report are paths in the filesystem. Data files and bundle manifests are not
+// read from the filesystem, and it can't be combined with WithInputPaths.
+func (l Linter) WithInputFS(fsys fs.FS, root string) Linter {
+	l.inputFS = fsys
+	l.inputFSRoot = cmp.Or(root, ".")
+
+	return l
+}
+
 // WithInputModules sets the input modules to lint. This is used for programmatic
 // access, where you don't necessarily want to lint *files*.
 func (l Linter) WithInputModules(input *rules.Input) Linter {
@@ -253,6 +333,47 @@ func (l Linter) WithIgnore(ignore []string) Linter {
 	return l.notPrepared()
 }
 
+// WithConcurrency limits the number of files read, parsed and linted concurrently.
+// A value of 0 or less means no limit, which is the default, except in streaming mode,
+// where the default limit is GOMAXPROCS.
+func (l Linter) WithConcurrency(concurrency int) Linter {
+	l.concurrency = concurrency
+
+	return l
+}
+
+// WithViolationsHandler enables streaming mode, where violations are passed to the handler
+// as soon as each file has been linted, instead of being collected in the report returned
+// from Lint. In this mode, files provided via WithInputPaths are read and parsed only when
+// about to be linted, and only the data needed for aggregate rules is kept in memory. The
+// summary of the returned report still accounts for all violations passed to the handler.
+// Use with WithConcurrency to bound memory usage for very large workspaces.
+func (l Linter) WithViolationsHandler(handler ViolationsHandler) Linter {
+	l.violationsHandler = handler
+
+	return l
+}
+
+// WithProgressHandler sets a function called with events describing the progress of linting.
+func (l Linter) WithProgressHandler(handler ProgressHandler) Linter {
+	if handler == nil {
+		l.progressHandler = nil
+
+		return l
+	}
+
+	var mu sync.Mutex
+
+	l.progressHandler = func(event report.ProgressEvent) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		handler(event)
+	}
+
+	return l
+}
+
 // WithMetrics enables metrics collection.
 func (l Linter) WithMetrics(m metrics.Metrics) Linter {
 	l.metrics = m
@@ -273,6 +394,20 @@ func (l Linter) WithProfiling(enabled bool) Linter {
 	return l
 }
 
+// WithStats enables collection of timing and violation statistics per rule.
+func (l Linter) WithStats(enabled bool) Linter {
+	l.stats = enabled
+
+	return l
+}
+
+// WithComplexity enables reporting of complexity metrics for all rules and functions.
+func (l Linter) WithComplexity(enabled bool) Linter {
+	l.complexity = enabled
+
+	return l
+}
+
 // WithInstrumentation enables instrumentation metrics.
 func (l Linter) WithInstrumentation(enabled bool) Linter {
 	l.instrumentation = enabled
@@ -307,10 +442,10 @@ func (l Linter) WithCollectQuery(enabled bool) Linter {
 }
 
 // Prepare stores linter preparation state, like the determined configuration,
-// and the query perpared for linting.
+// and the query perpared for linting. A prepared linter may be reused for any
+// number of calls to Lint, including concurrent ones, as described for Linter.
 // Experimental: while used internally, the details of what is prepared here
-// are very likely to change in the future, and this method should not yet be
-// relied on by external clients.
+// are very likely to change in the future.
 func (l Linter) Prepare(ctx context.Context) (Linter, error) {
 	l.startTimer(regalmetrics.RegalPrepare)
 	defer l.stopTimer(regalmetrics.RegalPrepare)
@@ -345,7 +480,7 @@ func (l Linter) Prepare(ctx context.Context) (Linter, error) {
 
 	// TODO: this offers a tremendous perf boost in projects, but may slow down
 	// linting a single file. investigate further, and if we can skip this in that case.
-	if err := l.regoPrepare(ctx); err != nil {
+	if l.rulesToRun, err = l.regoPrepare(ctx); err != nil {
 		return l, fmt.Errorf("failed to prepare Rego: %w", err)
 	}
 
@@ -382,6 +517,10 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		ignore = l.ignoreFiles
 	}
 
+	if l.inputFS != nil && len(l.inputPaths) > 0 {
+		return report.Report{}, errors.New("input paths can't be combined with an input filesystem")
+	}
+
 	l.startTimer(regalmetrics.RegalFilterIgnoredFiles)
 
 	filtered, err := config.FilterIgnoredPaths(l.inputPaths, ignore, true, l.pathPrefix)
@@ -401,9 +540,17 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		}
 	}
 
-	inputFromPaths, err := rules.InputFromPaths(filtered, l.pathPrefix, versionsMap)
-	if err != nil {
-		return report.Report{}, fmt.Errorf("errors encountered when reading files to lint: %w", err)
+	var inputFromPaths rules.Input
+
+	if l.violationsHandler != nil && !slices.Equal(filtered, []string{"-"}) {
+		// Files are read and parsed just before being linted when streaming.
+		inputFromPaths = rules.NewInput(map[string]string{}, map[string]*ast.Module{})
+		inputFromPaths.FileNames = filtered
+	} else {
+		inputFromPaths, err = rules.InputFromPaths(filtered, l.pathPrefix, versionsMap)
+		if err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when reading files to lint: %w", err)
+		}
 	}
 
 	l.stopTimer(regalmetrics.RegalInputParse)
@@ -427,29 +574,106 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		l.stopTimer(regalmetrics.RegalFilterIgnoredModules)
 	}
 
-	if len(l.inputPaths) == 0 && l.inputModules == nil {
+	if l.inputFS != nil {
+		if input, err = l.addInputFromFS(input, ignore, versionsMap); err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when reading files to lint: %w", err)
+		}
+	}
+
+	if len(l.inputPaths) == 0 && l.inputModules == nil && l.inputFS == nil {
 		return report.Report{}, errors.New("nothing provided to lint")
 	}
 
-	regoReport, err := l.lint(ctx, input)
+	// bundle files are only needed by the rules using them, although the data files also need the
+	// manifests to determine where their documents are mounted
+	useData, useBundles := l.anyRuleEnabled(dataFileRules...), l.anyRuleEnabled("bundle")
+
+	var (
+		dataPaths, manifestPaths []string
+		dataFiles                []*rules.DataFile
+		bundles                  []*rules.Bundle
+	)
+
+	if useData || useBundles {
+		if dataPaths, manifestPaths, err = l.bundleFiles(ignore); err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when finding bundle files: %w", err)
+		}
+	}
+
+	if useData {
+		if dataFiles, err = l.dataFiles(dataPaths, manifestPaths); err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when reading data files: %w", err)
+		}
+	}
+
+	if useBundles {
+		if bundles, err = l.bundles(manifestPaths, input); err != nil {
+			return report.Report{}, fmt.Errorf("errors encountered when reading bundles: %w", err)
+		}
+	}
+
+	schemaNames, schemas, err := l.schemas()
+	if err != nil {
+		return report.Report{}, fmt.Errorf("errors encountered when reading schemas: %w", err)
+	}
+
+	if schemas != nil {
+		ctx = regalbuiltins.WithSchemas(ctx, schemas)
+	}
+
+	// schemas only take part in aggregate rules, and the fan-in of complexity metrics is determined from
+	// aggregated references, so aggregates need to be collected even when there's only a single policy
+	runAggregate := len(input.FileNames) > 1 || schemaNames != nil || l.complexity
+	l.useCollectQuery = l.useCollectQuery || runAggregate
+
+	var stream *violationStream
+	if l.violationsHandler != nil {
+		stream = &violationStream{handler: l.violationsHandler, filesFailed: util.NewSet[string]()}
+	}
+
+	regoReport, err := l.lint(ctx, input, versionsMap, bundles, stream)
 	if err != nil {
 		return report.Report{}, fmt.Errorf("failed to lint using Rego rules: %w", err)
 	}
 
-	if len(input.FileNames) > 1 {
+	if runAggregate {
+		if err := ctx.Err(); err != nil {
+			return report.Report{}, fmt.Errorf("context cancelled: %w", err)
+		}
+
 		allAggregates := regoReport.Aggregates
 
 		if allAggregates != nil && allAggregates.Len() > 0 {
-			aggregateReport, err := l.lintWithAggregateRules(ctx, allAggregates, regoReport.IgnoreDirectives)
+			l.progress(report.ProgressAggregateStarted, "", 0)
+
+			aggregateReport, err := l.lintWithAggregateRules(
+				ctx, allAggregates, regoReport.IgnoreDirectives, dataFiles, bundles, schemaNames,
+			)
 			if err != nil {
 				return report.Report{}, fmt.Errorf("failed to lint using Rego aggregate rules: %w", err)
 			}
 
-			regoReport.Violations = append(regoReport.Violations, aggregateReport.Violations...)
+			l.progress(report.ProgressAggregateFinished, "", len(aggregateReport.Violations))
+
+			if stream != nil {
+				if err := stream.publish(ctx, aggregateReport.Violations); err != nil {
+					return report.Report{}, fmt.Errorf("failed to handle aggregate violations: %w", err)
+				}
+			} else {
+				regoReport.Violations = append(regoReport.Violations, aggregateReport.Violations...)
+			}
 
 			if l.profiling {
 				regoReport.AggregateProfile = aggregateReport.AggregateProfile
 			}
+
+			if l.stats {
+				regoReport.AddRuleStats(aggregateReport.AggregateStats)
+			}
+
+			if l.complexity {
+				regoReport.AddFanIn(aggregateReport.FanIn)
+			}
 		}
 	}
 
@@ -462,6 +686,11 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		NumViolations: len(regoReport.Violations),
 	}
 
+	if stream != nil {
+		regoReport.Summary.FilesFailed = stream.filesFailed.Size()
+		regoReport.Summary.NumViolations = stream.numViolations
+	}
+
 	if !l.exportAggregates {
 		regoReport.Aggregates = nil
 	}
@@ -477,6 +706,11 @@ func (l Linter) Lint(ctx context.Context) (report.Report, error) {
 		regoReport.AggregateProfile = nil
 	}
 
+	if l.stats {
+		regoReport.AggregateStatsToSortedStats()
+		regoReport.AggregateStats = nil
+	}
+
 	return regoReport, nil
 }
 
@@ -590,7 +824,9 @@ func (l Linter) countSkippedFromNotices(ctx context.Context, r report.Report) (r
 	return r, rulesSkippedCounter
 }
 
-func (l Linter) regoPrepare(ctx context.Context) error {
+// regoPrepare evaluates the prepare stage of the Rego rules and writes the result to the store,
+// returning the rules determined to run.
+func (l Linter) regoPrepare(ctx context.Context) (ast.Object, error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
@@ -607,6 +843,8 @@ func (l Linter) regoPrepare(ctx context.Context) error {
 	stg := l.preparedQuery.Store().Storage()
 	txn := storage.NewTransactionOrDie(ctx, stg, storage.WriteParams)
 
+	rulesToRun := ast.NewObject()
+
 	// TODO: profiling, instrumentation, metrics
 	ev := l.preparedQuery.Evaluator().
 		WithTransaction(txn).
@@ -622,6 +860,10 @@ func (l Linter) regoPrepare(ctx context.Context) error {
 				return errors.New("expected 'prepared' field in result object")
 			}
 
+			if toRun, ok := rast.GetValue[ast.Object](prep, "rules_to_run"); ok {
+				rulesToRun = toRun
+			}
+
 			// Ensure all intermediate path segments exist before writing the leaf.
 			// Sometimes these are missing at start up and we want to be reliable.
 			for i := 1; i < len(preparedPath); i++ {
@@ -660,10 +902,29 @@ func (l Linter) regoPrepare(ctx context.Context) error {
 	if err := ev.Eval(ctx); err != nil {
 		stg.Abort(ctx, txn)
 
-		return fmt.Errorf("failed to evaluate prepare query: %w", err)
+		return nil, fmt.Errorf("failed to evaluate prepare query: %w", err)
+	}
+
+	if err := stg.Commit(ctx, txn); err != nil {
+		return nil, fmt.Errorf("failed to commit prepared data: %w", err)
+	}
+
+	return rulesToRun, nil
+}
+
+// anyRuleEnabled reports whether any of the rules, given as category/title, or as a category for
+// any rule in that category, is determined to run.
+func (l Linter) anyRuleEnabled(names ...string) bool {
+	for _, name := range names {
+		category, title, found := strings.Cut(name, "/")
+
+		titles, ok := rast.GetValue[ast.Set](l.rulesToRun, category)
+		if ok && (!found && titles.Len() > 0 || titles.Contains(ast.InternedTerm(title))) {
+			return true
+		}
 	}
 
-	return stg.Commit(ctx, txn)
+	return false
 }
 
 func (l Linter) notPrepared() Linter {
@@ -783,7 +1044,13 @@ func (l Linter) validate(conf *config.Config) error {
 	return nil
 }
 
-func (l Linter) lint(ctx context.Context, input rules.Input) (report.Report, error) {
+func (l Linter) lint(
+	ctx context.Context,
+	input rules.Input,
+	versionsMap map[string]ast.RegoVersion,
+	bundles []*rules.Bundle,
+	stream *violationStream,
+) (report.Report, error) {
 	l.startTimer(regalmetrics.RegalLintRego)
 	defer l.stopTimer(regalmetrics.RegalLintRego)
 
@@ -796,38 +1063,126 @@ func (l Linter) lint(ctx context.Context, input rules.Input) (report.Report, err
 	// NB(sr): We benchmarked using `wg.SetLimit(runtime.GOMAXPROCS(-1))` here, but performance
 	// got a little worse. So let's not bother.
 	wg, ctx := errgroup.WithContext(ctx)
-	results := make([]report.Report, numFiles)
 
-	l.preparedQuery.StartReadTransaction(ctx)
-	defer l.preparedQuery.EndReadTransaction(ctx)
+	// When streaming, the report of each file is merged into a single one as soon as its violations
+	// have been handed to the handler, rather than kept until all files have been linted.
+	var (
+		results  []report.Report
+		merged   report.Report
+		mergedMu sync.Mutex
+	)
+
+	if stream == nil {
+		results = make([]report.Report, numFiles)
+	}
+
+	limit, procs := l.concurrency, runtime.GOMAXPROCS(-1)
+
+	// Streaming is meant to bound memory usage, which requires bounding the number of files
+	// read, parsed and linted at the same time, even when no limit was set.
+	if stream != nil && limit <= 0 {
+		limit = procs
+	}
+
+	// The rule timer measures wall time between trace events, which with more goroutines than
+	// processors would include time spent evaluating other files. This is documented for --stats.
+	if l.stats && (limit <= 0 || limit > procs) {
+		limit = procs
+	}
+
+	if limit > 0 {
+		wg.SetLimit(limit)
+	}
+
+	// A transaction is started for each call rather than on the prepared query, which may be
+	// shared by concurrent calls to Lint.
+	txn := l.preparedQuery.Store().ReadTransaction(ctx)
+	defer l.preparedQuery.Store().Storage().Abort(ctx, txn)
+
+	var ruleFiles map[string]report.RuleStats
+	if l.stats {
+		ruleFiles = l.ruleFiles()
+	}
+
+	// files in bundles declaring their Rego version are checked against the declared version as they're
+	// parsed, with each file checked by the goroutine linting it
+	versionedFiles := make(map[string]*rules.BundleFile)
+
+	for _, b := range bundles {
+		for i := range b.Files {
+			if b.Files[i].RegoVersion != nil {
+				versionedFiles[b.Files[i].Name] = &b.Files[i]
+			}
+		}
+	}
 
 	for i, name := range input.FileNames {
 		wg.Go(func() error {
-			inputValue, err := transform.ToAST(name, input.FileContent[name], input.Modules[name], operationCollect)
+			l.progress(report.ProgressFileStarted, name, 0)
+
+			// files queued before the context was cancelled are skipped
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			content, module := input.FileContent[name], input.Modules[name]
+			if module == nil {
+				parsed, err := l.parseFile(name, versionsMap)
+				if err != nil {
+					return fmt.Errorf("errors encountered when reading files to lint: %w", err)
+				}
+
+				name = parsed.FileNames[0]
+				content, module = parsed.FileContent[name], parsed.Modules[name]
+			}
+
+			if bf, ok := versionedFiles[input.FileNames[i]]; ok {
+				bf.CheckRegoVersion(module, content)
+			}
+
+			inputValue, err := transform.ToAST(name, content, module, operationCollect)
 			if err != nil {
 				return fmt.Errorf("failed to transform input value: %w", err)
 			} else {
-				ex := l.preparedQuery.Evaluator().WithInput(inputValue)
+				if l.stats {
+					addStatsOperation(inputValue, operationCollect)
+				}
+
+				if l.complexity {
+					addOperation(inputValue, "complexity")
+				}
+
+				ex := l.preparedQuery.Evaluator().WithTransaction(txn).WithInput(inputValue)
 
 				if l.profiling {
 					ex = ex.WithProfiler(profiler.New())
 				}
 
+				var timer *ruleTimer
+				if l.stats {
+					timer = newRuleTimer(ruleFiles)
+					ex = ex.WithQueryTracer(timer)
+				}
+
 				ex = ex.WithResultHandler(func(result ast.Value) error {
 					r, err := report.FromQueryResult(result, false)
 					if err != nil {
 						return fmt.Errorf("failed to convert query result to report: %w", err)
 					}
 
-					if l.profiling {
-						// Perhaps we'll want to make this number configurable later, but do note that
-						// this is only the top 10 locations for a *single* file, not the final report.
-						profRep := ex.Profiler().ReportTopNResults(10, []string{"total_time_ns"})
+					l.addProfileAndStats(ex, &r, timer)
+					l.progress(report.ProgressFileFinished, name, len(r.Violations))
 
-						r.AggregateProfile = make(map[string]report.ProfileEntry, len(profRep))
-						for _, rs := range profRep {
-							r.AggregateProfile[rs.Location.String()] = regalmetrics.FromExprStats(rs)
+					if stream != nil {
+						if err := stream.publish(ctx, r.Violations); err != nil {
+							return fmt.Errorf("failed to handle violations: %w", err)
 						}
+
+						mergedMu.Lock()
+						l.mergeFileReport(&merged, r)
+						mergedMu.Unlock()
+
+						return nil
 					}
 
 					results[i] = r
@@ -852,6 +1207,10 @@ func (l Linter) lint(ctx context.Context, input rules.Input) (report.Report, err
 		return report.Report{}, fmt.Errorf("error encountered in rule evaluation %w", err)
 	}
 
+	if stream != nil {
+		return merged, nil
+	}
+
 	var regoReport report.Report
 
 	if len(results) == 0 {
@@ -866,27 +1225,50 @@ func (l Linter) lint(ctx context.Context, input rules.Input) (report.Report, err
 	for i := range results[1:] {
 		i++
 		regoReport.Violations = append(regoReport.Violations, results[i].Violations...)
-		regoReport.Notices = append(regoReport.Notices, results[i].Notices...)
 
-		// Since the "primary key" is the file name, there is no need to handle collisions here.
-		results[i].Aggregates.Foreach(regoReport.Aggregates.Insert)
+		l.mergeFileReport(&regoReport, results[i])
+	}
 
-		if results[i].IgnoreDirectives != nil {
-			regoReport.IgnoreDirectives, _ = regoReport.IgnoreDirectives.Merge(results[i].IgnoreDirectives)
-		}
+	return regoReport, nil
+}
+
+// mergeFileReport merges everything but the violations of the report of a single file into the
+// report of all files linted so far.
+func (l Linter) mergeFileReport(into *report.Report, from report.Report) {
+	into.Notices = append(into.Notices, from.Notices...)
+	into.Complexity = append(into.Complexity, from.Complexity...)
+
+	// Since the "primary key" is the file name, there is no need to handle collisions here.
+	if into.Aggregates == nil {
+		into.Aggregates = from.Aggregates
+	} else if from.Aggregates != nil {
+		from.Aggregates.Foreach(into.Aggregates.Insert)
+	}
 
-		if l.profiling {
-			regoReport.AddProfileEntries(results[i].AggregateProfile)
+	if from.IgnoreDirectives != nil {
+		if into.IgnoreDirectives == nil {
+			into.IgnoreDirectives = from.IgnoreDirectives
+		} else {
+			into.IgnoreDirectives, _ = into.IgnoreDirectives.Merge(from.IgnoreDirectives)
 		}
 	}
 
-	return regoReport, nil
+	if l.profiling {
+		into.AddProfileEntries(from.AggregateProfile)
+	}
+
+	if l.stats {
+		into.AddRuleStats(from.AggregateStats)
+	}
 }
 
 func (l Linter) lintWithAggregateRules(
 	ctx context.Context,
 	aggregates ast.Object,
 	ignoreDirectives ast.Object,
+	dataFiles []*rules.DataFile,
+	bundles []*rules.Bundle,
+	schemaNames []string,
 ) (report.Report, error) {
 	l.startTimer(regalmetrics.RegalLintRegoAggregate)
 	defer l.stopTimer(regalmetrics.RegalLintRegoAggregate)
@@ -894,15 +1276,48 @@ func (l Linter) lintWithAggregateRules(
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
 
+	regalObject := aggregateRegalObject
+	if l.stats {
+		regalObject = aggregateStatsRegalObject
+	}
+
 	inputValue := ast.NewObject(
 		rast.Item("ignore_directives", ast.NewTerm(cmp.Or(ignoreDirectives, intern.EmptyObject))),
-		rast.Item("regal", aggregateRegalObject),
+		rast.Item("regal", regalObject),
 	)
 
+	if l.complexity {
+		// the regal object is shared between evaluations, so the operation is added to a copy
+		inputValue.Insert(ast.InternedTerm("regal"), regalObject.Copy())
+		addOperation(inputValue, "complexity")
+	}
+
 	if aggregates != nil && aggregates.Len() > 0 {
 		inputValue.Insert(ast.StringTerm("aggregates_internal"), ast.NewTerm(aggregates))
 	}
 
+	if len(dataFiles) > 0 {
+		files := ast.NewObject()
+		for _, df := range dataFiles {
+			files.Insert(ast.StringTerm(df.Name), ast.NewTerm(df.ToValue()))
+		}
+
+		inputValue.Insert(ast.StringTerm("data_files"), ast.NewTerm(files))
+	}
+
+	if len(bundles) > 0 {
+		values := make([]*ast.Term, 0, len(bundles))
+		for _, b := range bundles {
+			values = append(values, ast.NewTerm(b.ToValue()))
+		}
+
+		inputValue.Insert(ast.StringTerm("bundles"), ast.ArrayTerm(values...))
+	}
+
+	if schemaNames != nil {
+		inputValue.Insert(ast.StringTerm("schema_files"), rast.ArrayTerm(schemaNames))
+	}
+
 	var rep report.Report
 
 	ex := l.preparedQuery.Evaluator().WithInput(inputValue)
@@ -910,6 +1325,12 @@ func (l Linter) lintWithAggregateRules(
 		ex = ex.WithProfiler(profiler.New())
 	}
 
+	var timer *ruleTimer
+	if l.stats {
+		timer = newRuleTimer(l.ruleFiles())
+		ex = ex.WithQueryTracer(timer)
+	}
+
 	ex = ex.WithResultHandler(func(result ast.Value) (err error) {
 		rep, err = report.FromQueryResult(result, true)
 		if err != nil {
@@ -920,14 +1341,7 @@ func (l Linter) lintWithAggregateRules(
 			rep.Violations[i].IsAggregate = true
 		}
 
-		if l.profiling {
-			profRep := ex.Profiler().ReportTopNResults(10, []string{"total_time_ns"})
-
-			rep.AggregateProfile = make(map[string]report.ProfileEntry, len(profRep))
-			for _, rs := range profRep {
-				rep.AggregateProfile[rs.Location.String()] = regalmetrics.FromExprStats(rs)
-			}
-		}
+		l.addProfileAndStats(ex, &rep, timer)
 
 		return nil
 	})
@@ -939,6 +1353,512 @@ func (l Linter) lintWithAggregateRules(
 	return rep, nil
 }
 
+// bundleFiles finds the data files and .manifest files under the directories provided as input paths,
+// excluding any ignored files.
+func (l Linter) bundleFiles(ignore []string) (dataPaths []string, manifestPaths []string, err error) {
+	for _, path := range l.inputPaths {
+		if !rio.IsDir(path) {
+			continue
+		}
+
+		if err := files.DefaultWalker(path).Walk(func(file string) error {
+			switch name := filepath.Base(file); {
+			case name == ".manifest":
+				manifestPaths = append(manifestPaths, file)
+			case slices.Contains(rules.DataFileNames, name):
+				dataPaths = append(dataPaths, file)
+			}
+
+			return nil
+		}); err != nil {
+			return nil, nil, fmt.Errorf("failed to walk %s: %w", path, err)
+		}
+	}
+
+	if dataPaths, err = config.FilterIgnoredPaths(dataPaths, ignore, false, l.pathPrefix); err != nil {
+		return nil, nil, fmt.Errorf("failed to filter data files: %w", err)
+	}
+
+	if manifestPaths, err = config.FilterIgnoredPaths(manifestPaths, ignore, false, l.pathPrefix); err != nil {
+		return nil, nil, fmt.Errorf("failed to filter manifest files: %w", err)
+	}
+
+	return dataPaths, manifestPaths, nil
+}
+
+// dataFiles parses the data files found under the directories provided as input paths. The document
+// of each file is mounted under data relative to its closest bundle root, which is either a directory
+// containing a .manifest file, a project root from the configuration, or the input path.
+func (l Linter) dataFiles(paths []string, manifestPaths []string) ([]*rules.DataFile, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	roots := make([]string, 0, len(l.inputPaths)+len(manifestPaths))
+
+	for _, path := range l.inputPaths {
+		if rio.IsDir(path) {
+			roots = append(roots, path)
+		}
+	}
+
+	for _, path := range manifestPaths {
+		roots = append(roots, filepath.Dir(path))
+	}
+
+	roots = append(roots, l.projectRoots()...)
+
+	for i, root := range roots {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", root, err)
+		}
+
+		roots[i] = abs
+	}
+
+	dataFiles := make([]*rules.DataFile, 0, len(paths))
+
+	for _, path := range paths {
+		df, err := rules.DataFileFromPath(path, roots)
+		if err != nil {
+			return nil, err
+		}
+
+		dataFiles = append(dataFiles, df)
+	}
+
+	return dataFiles, nil
+}
+
+// bundles returns the bundles among the policies being linted, which are the directories containing
+// a .manifest file, and the project roots from the configuration. Each policy file belongs to the
+// bundle with the closest root, if any.
+func (l Linter) bundles(manifestPaths []string, input rules.Input) ([]*rules.Bundle, error) {
+	byRoot := make(map[string]*rules.Bundle)
+
+	for _, path := range manifestPaths {
+		manifest, err := rules.ManifestFromPath(path)
+		if err != nil {
+			return nil, err
+		}
+
+		abs, err := filepath.Abs(filepath.Dir(path))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+		}
+
+		byRoot[abs] = &rules.Bundle{Path: filepath.Dir(path), Manifest: manifest}
+	}
+
+	for _, root := range l.projectRoots() {
+		abs, err := filepath.Abs(root)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", root, err)
+		}
+
+		if _, ok := byRoot[abs]; !ok && rio.IsDir(abs) {
+			// prefer a path relative to the working directory, like those of the linted files
+			if wd, err := os.Getwd(); err == nil {
+				if rel, err := filepath.Rel(wd, abs); err == nil && !strings.HasPrefix(rel, "..") {
+					root = rel
+				}
+			}
+
+			byRoot[abs] = &rules.Bundle{Path: root}
+		}
+	}
+
+	if len(byRoot) == 0 {
+		return nil, nil
+	}
+
+	for _, name := range input.FileNames {
+		abs, err := filepath.Abs(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get absolute path for %s: %w", name, err)
+		}
+
+		var closest string
+
+		for root := range byRoot {
+			if strings.HasPrefix(abs, root+string(filepath.Separator)) && len(root) > len(closest) {
+				closest = root
+			}
+		}
+
+		if closest == "" {
+			continue
+		}
+
+		b := byRoot[closest]
+		b.Files = append(b.Files, rules.NewBundleFile(name, strings.TrimPrefix(abs, closest), b.Manifest))
+	}
+
+	bundles := make([]*rules.Bundle, 0, len(byRoot))
+	for _, root := range outil.KeysSorted(byRoot) {
+		// project roots without any linted files are of no interest
+		if b := byRoot[root]; b.Manifest != nil || len(b.Files) > 0 {
+			bundles = append(bundles, b)
+		}
+	}
+
+	return bundles, nil
+}
+
+// projectRoots returns the paths of the project roots from the configuration.
+func (l Linter) projectRoots() []string {
+	if l.pathPrefix == "" || strings.HasPrefix(l.pathPrefix, "file://") ||
+		l.combinedCfg.Project == nil || l.combinedCfg.Project.Roots == nil {
+		return nil
+	}
+
+	// roots are relative to the directory containing the .regal directory, which
+	// may itself be provided as the path prefix
+	dir := l.pathPrefix
+	if filepath.Base(dir) == ".regal" {
+		dir = filepath.Dir(dir)
+	}
+
+	roots := make([]string, 0, len(*l.combinedCfg.Project.Roots))
+	for _, root := range *l.combinedCfg.Project.Roots {
+		roots = append(roots, filepath.Join(dir, root.Path))
+	}
+
+	return roots
+}
+
+// schemas returns the names of the schemas found at the paths configured for the unresolved-metadata-schema
+// rule, along with the schemas themselves, or nil if no paths are configured. Like project roots, relative
+// paths are resolved from the directory containing the .regal directory, when known.
+func (l Linter) schemas() ([]string, *ast.SchemaSet, error) {
+	if l.combinedCfg == nil {
+		return nil, nil, nil
+	}
+
+	paths, ok := l.combinedCfg.Rules["custom"]["unresolved-metadata-schema"].Extra["schema-paths"].([]any)
+	if !ok || len(paths) == 0 {
+		return nil, nil, nil
+	}
+
+	var dir string
+	if l.pathPrefix != "" && !strings.HasPrefix(l.pathPrefix, "file://") {
+		if dir = l.pathPrefix; filepath.Base(dir) == ".regal" {
+			dir = filepath.Dir(dir)
+		}
+	}
+
+	names := make([]string, 0, len(paths))
+	schemas := ast.NewSchemaSet()
+
+	for _, path := range paths {
+		str, ok := path.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("schema path must be a string, got %v", path)
+		}
+
+		if !filepath.IsAbs(str) {
+			str = filepath.Join(dir, str)
+		}
+
+		found, err := rules.SchemaNamesFromPath(str)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if err := rules.AddSchemasFromPath(schemas, str); err != nil {
+			return nil, nil, err
+		}
+
+		names = append(names, found...)
+	}
+
+	return names, schemas, nil
+}
+
+// addInputFromFS adds the Rego files found in the input filesystem to the input, excluding any
+// ignored files. Like files provided as input paths, these are read and parsed just before being
+// linted when streaming.
+func (l Linter) addInputFromFS(
+	input rules.Input,
+	ignore []string,
+	versionsMap map[string]ast.RegoVersion,
+) (rules.Input, error) {
+	names, err := files.DefaultWalkReducer(l.inputFSRoot, []string{}).
+		WithFilters(filter.NotRego).
+		ReduceFS(l.inputFS, files.PathAppendReducer)
+	if err != nil {
+		return input, fmt.Errorf("failed to walk input filesystem: %w", err)
+	}
+
+	if names, err = config.FilterIgnoredPaths(names, ignore, false, l.pathPrefix); err != nil {
+		return input, fmt.Errorf("failed to filter paths: %w", err)
+	}
+
+	if l.violationsHandler != nil {
+		input.FileNames = append(input.FileNames, names...)
+
+		return input, nil
+	}
+
+	fromFS, err := rules.InputFromFS(l.inputFS, names, versionsMap)
+	if err != nil {
+		return input, err
+	}
+
+	for _, name := range fromFS.FileNames {
+		input.FileNames = append(input.FileNames, name)
+		input.Modules[name] = fromFS.Modules[name]
+		input.FileContent[name] = fromFS.FileContent[name]
+	}
+
+	return input, nil
+}
+
+// parseFile reads and parses a single policy file, from the input filesystem if provided,
+// and otherwise from disk.
+func (l Linter) parseFile(name string, versionsMap map[string]ast.RegoVersion) (rules.Input, error) {
+	if l.inputFS != nil {
+		return rules.InputFromFS(l.inputFS, []string{name}, versionsMap)
+	}
+
+	return rules.InputFromPaths([]string{name}, l.pathPrefix, versionsMap)
+}
+
+func (l Linter) progress(event, file string, numViolations int) {
+	if l.progressHandler != nil {
+		l.progressHandler(report.ProgressEvent{
+			Time:          time.Now(),
+			Event:         event,
+			File:          file,
+			NumViolations: numViolations,
+		})
+	}
+}
+
+func (s *violationStream) publish(ctx context.Context, violations []report.Violation) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.numViolations += len(violations)
+	for i := range violations {
+		s.filesFailed.Add(violations[i].Location.File)
+	}
+
+	return s.handler(ctx, violations)
+}
+
+// addProfileAndStats adds profiling data from the evaluator's profiler, and rule statistics
+// from the timer, to the report, if either profiling or stats are enabled.
+func (l Linter) addProfileAndStats(ex *ogre.Evaluator, r *report.Report, timer *ruleTimer) {
+	if l.profiling {
+		// Perhaps we'll want to make this number configurable later, but do note that
+		// this is only the top 10 locations for a *single* file, not the final report.
+		top := ex.Profiler().ReportTopNResults(10, []string{"total_time_ns"})
+
+		r.AggregateProfile = make(map[string]report.ProfileEntry, len(top))
+		for _, rs := range top {
+			r.AggregateProfile[rs.Location.String()] = regalmetrics.FromExprStats(rs)
+		}
+	}
+
+	if l.stats {
+		r.AggregateStats = ruleStats(timer, r.Violations, r.Suppressed)
+	}
+}
+
+// ruleFiles maps the file name of each module belonging to a linter rule to an
+// empty stats entry for that rule, allowing evaluation of the module to be attributed
+// to the rule package (data.regal.rules.<category>.<rule>) it was found in.
+func (l Linter) ruleFiles() map[string]report.RuleStats {
+	files := make(map[string]report.RuleStats)
+
+	add := func(module *ast.Module, offset int) {
+		if module == nil || module.Package.Location == nil {
+			return
+		}
+
+		parts, _ := storage.NewPathForRef(module.Package.Path)
+		if len(parts) != offset+4 || parts[offset] != "regal" || parts[offset+1] != "rules" {
+			return
+		}
+
+		files[module.Package.Location.File] = report.RuleStats{Category: parts[offset+2], Title: parts[offset+3]}
+	}
+
+	for _, b := range l.ruleBundles {
+		for _, module := range b.Modules {
+			add(module.Parsed, 0)
+		}
+	}
+
+	for _, module := range l.customRuleModules {
+		add(module, 1)
+	}
+
+	return files
+}
+
+func ruleStats(
+	timer *ruleTimer,
+	violations []report.Violation,
+	suppressed []report.Violation,
+) map[string]report.RuleStats {
+	stats := make(map[string]report.RuleStats, len(timer.stats))
+
+	for key, entry := range timer.stats {
+		stats[key] = *entry
+	}
+
+	update := func(category, title string, f func(*report.RuleStats)) {
+		key := category + "/" + title
+
+		entry, ok := stats[key]
+		if !ok {
+			entry = report.RuleStats{Category: category, Title: title}
+		}
+
+		f(&entry)
+
+		stats[key] = entry
+	}
+
+	for i := range violations {
+		update(violations[i].Category, violations[i].Title, func(entry *report.RuleStats) {
+			entry.NumViolations++
+		})
+	}
+
+	for i := range suppressed {
+		update(suppressed[i].Category, suppressed[i].Title, func(entry *report.RuleStats) {
+			entry.NumSuppressed++
+		})
+	}
+
+	return stats
+}
+
+// ruleTimer is a query tracer attributing the time spent between trace events to the linter rule
+// whose package the current query was entered from. Queries inherit the rule of their parent query,
+// so time spent in helpers, like those in data.regal.ast and data.regal.util, counts towards the
+// rule calling them. As the values of rules are cached for the rest of the evaluation, the cost of
+// a helper used by several linter rules is attributed to the first rule to evaluate it.
+type ruleTimer struct {
+	ruleFiles map[string]report.RuleStats
+	stats     map[string]*report.RuleStats
+	owners    map[uint64]*report.RuleStats
+	current   *report.RuleStats
+	since     time.Time
+}
+
+func newRuleTimer(ruleFiles map[string]report.RuleStats) *ruleTimer {
+	return &ruleTimer{
+		ruleFiles: ruleFiles,
+		stats:     make(map[string]*report.RuleStats),
+		owners:    make(map[uint64]*report.RuleStats),
+	}
+}
+
+func (*ruleTimer) Enabled() bool {
+	return true
+}
+
+func (*ruleTimer) Config() topdown.TraceConfig {
+	return topdown.TraceConfig{}
+}
+
+func (t *ruleTimer) TraceEvent(event topdown.Event) {
+	now := time.Now()
+
+	if t.current != nil {
+		t.current.TotalTimeNs += now.Sub(t.since).Nanoseconds()
+	}
+
+	owner, ok := t.owners[event.QueryID]
+	if !ok {
+		owner = t.owners[event.ParentID]
+		t.owners[event.QueryID] = owner
+	}
+
+	// the body of a rule is evaluated in a query of its own, which is entered with the rule as node
+	if rule, ok := event.Node.(*ast.Rule); ok && event.Op == topdown.EnterOp && rule.Location != nil {
+		if entry := t.entry(rule.Location.File); entry != nil {
+			owner = entry
+			t.owners[event.QueryID] = owner
+		}
+	}
+
+	if owner != nil && event.Op == topdown.EvalOp {
+		owner.NumEval++
+	}
+
+	t.current, t.since = owner, now
+}
+
+// entry returns the stats entry of the linter rule the file belongs to, or nil if it belongs to none.
+func (t *ruleTimer) entry(file string) *report.RuleStats {
+	rule, ok := t.ruleFiles[file]
+	if !ok {
+		return nil
+	}
+
+	key := rule.Category + "/" + rule.Title
+
+	entry, ok := t.stats[key]
+	if !ok {
+		entry = &report.RuleStats{Category: rule.Category, Title: rule.Title}
+		t.stats[key] = entry
+	}
+
+	return entry
+}
+
+// addStatsOperation replaces the operations of the regal object in the input value with
+// the same operations plus "stats", instructing the Rego linter to also report suppressed
+// violations.
+func addStatsOperation(inputValue ast.Value, collect bool) {
+	obj, ok := inputValue.(ast.Object)
+	if !ok {
+		return
+	}
+
+	if regal, ok := rast.GetValue[ast.Object](obj, "regal"); ok {
+		operations := operationsLintStats
+		if collect {
+			operations = operationsLintCollectStats
+		}
+
+		regal.Insert(ast.InternedTerm("operations"), operations)
+	}
+}
+
+// addOperation adds an operation to those of the regal object in the input value, like
+// "complexity", instructing the Rego linter to also provide the data of that operation.
+func addOperation(inputValue ast.Value, operation string) {
+	obj, ok := inputValue.(ast.Object)
+	if !ok {
+		return
+	}
+
+	regal, ok := rast.GetValue[ast.Object](obj, "regal")
+	if !ok {
+		return
+	}
+
+	terms := []*ast.Term{ast.InternedTerm(operation)}
+
+	// the operations array may be shared, so a new one is created rather than appending to it
+	if operations, ok := rast.GetValue[*ast.Array](regal, "operations"); ok {
+		terms = make([]*ast.Term, 0, operations.Len()+1)
+		operations.Foreach(func(term *ast.Term) {
+			terms = append(terms, term)
+		})
+		terms = append(terms, ast.InternedTerm(operation))
+	}
+
+	regal.Insert(ast.InternedTerm("operations"), ast.ArrayTerm(terms...))
+}
+
 func (l Linter) startTimer(name string) {
 	if l.metrics != nil {
 		l.metrics.Timer(name).Start()
@@ -2,10 +2,15 @@ package linter_test
 
 import (
 	"bytes"
+	"context"
 	"embed"
+	"errors"
+	"fmt"
 	"path/filepath"
 	"slices"
+	"sync"
 	"testing"
+	"testing/fstest"
 
 	"github.com/open-policy-agent/opa/v1/ast"
 	"github.com/open-policy-agent/opa/v1/topdown"
@@ -17,6 +22,8 @@ import (
 	"github.com/open-policy-agent/regal/internal/util"
 	"github.com/open-policy-agent/regal/pkg/config"
 	regal "github.com/open-policy-agent/regal/pkg/linter"
+	"github.com/open-policy-agent/regal/pkg/report"
+	"github.com/open-policy-agent/regal/pkg/rules"
 )
 
 func TestLintWithDefaultBundle(t *testing.T) {
@@ -399,3 +406,488 @@ allow if {
 	assert.Equal(t, 6, result.Violations[1].Location.Row, "unexpected line number")
 	assert.Equal(t, 17, result.Violations[1].Location.Column, "unexpected column number")
 }
+
+func TestLintWithStats(t *testing.T) {
+	t.Parallel()
+
+	input := test.InputPolicy("p/p.rego", `package p
+
+# regal ignore:prefer-snake-case
+camelCase := true
+
+otherCamelCase := true
+`)
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case", "todo-comment").
+		WithStats(true).
+		WithInputModules(input).
+		Lint(t.Context()))(t)
+
+	testutil.AssertNumViolations(t, 1, result)
+
+	stats := make(map[string]report.RuleStats, len(result.Stats))
+	for _, entry := range result.Stats {
+		stats[entry.Category+"/"+entry.Title] = entry
+	}
+
+	snakeCase, ok := stats["style/prefer-snake-case"]
+	must.Equal(t, true, ok, "expected stats for prefer-snake-case")
+	assert.Equal(t, 1, snakeCase.NumViolations, "violations")
+	assert.Equal(t, 1, snakeCase.NumSuppressed, "suppressed violations")
+	assert.True(t, snakeCase.TotalTimeNs > 0, "expected time spent in prefer-snake-case")
+
+	todoComment, ok := stats["style/todo-comment"]
+	must.Equal(t, true, ok, "expected stats for todo-comment")
+	assert.Equal(t, 0, todoComment.NumViolations, "violations")
+	assert.True(t, todoComment.NumEval > 0, "expected evaluations in todo-comment")
+
+	assert.True(t, result.AggregateStats == nil, "aggregate stats should not be exposed")
+}
+
+func TestLintWithStatsCountsHelpers(t *testing.T) {
+	t.Parallel()
+
+	customRules := fstest.MapFS{
+		"rule.rego": {Data: []byte(`# METADATA
+# description: Rule doing all its work in a helper package
+package custom.regal.rules.custom["uses-helper"]
+
+import data.custom.helpers
+import data.regal.result
+
+report contains violation if {
+	helpers.evens > 1000
+
+	violation := result.fail(rego.metadata.chain(), result.location(input.package))
+}
+`)},
+		"helpers.rego": {Data: []byte(`package custom.helpers
+
+evens := count([n | some n in numbers.range(1, 5000); n % 2 == 0])
+`)},
+	}
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("uses-helper").
+		WithCustomRulesFromFS(customRules, ".").
+		WithStats(true).
+		WithInputModules(test.InputPolicy("p/p.rego", "package p\n")).
+		Lint(t.Context()))(t)
+
+	testutil.AssertNumViolations(t, 1, result)
+	must.Equal(t, 1, len(result.Stats), "stats entries")
+
+	// the expressions of the comprehension in the helper package count towards the rule calling it
+	assert.Equal(t, "uses-helper", result.Stats[0].Title, "title")
+	assert.True(t, result.Stats[0].NumEval >= 5000, "expected evaluations in helper, got %d", result.Stats[0].NumEval)
+}
+
+func TestLintWithComplexity(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"p/p.rego": "package p\n\nadmin if input.user.role == \"admin\"\n",
+		"q/q.rego": `package q
+
+import data.p
+
+allow if p.admin
+
+deny contains msg if {
+	not p.admin
+	msg := [m | some m in input.messages; every c in m { c != "" }][0]
+}
+`,
+	})
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithComplexity(true).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	names := make([]string, 0, len(result.Complexity))
+	for _, entry := range result.Complexity {
+		names = append(names, entry.Name)
+	}
+
+	// sorted by file and location
+	assert.SlicesEqual(t, []string{"data.p.admin", "data.q.allow", "data.q.deny"}, names)
+
+	admin, deny := result.Complexity[0], result.Complexity[2]
+
+	assert.Equal(t, filepath.Join(root, "p", "p.rego"), admin.Location.File, "file")
+	assert.Equal(t, 2, admin.FanIn, "fan-in of admin")
+	assert.Equal(t, 1, admin.FanOut, "fan-out of admin")
+	assert.Equal(t, 5, deny.Expressions, "expressions of deny")
+	assert.Equal(t, 2, deny.Nesting, "nesting of deny")
+	assert.Equal(t, 2, deny.FanOut, "fan-out of deny")
+	assert.True(t, result.FanIn == nil, "fan-in should not be exposed")
+}
+
+func TestLintWithViolationsHandler(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"p/p.rego": "package p\n\ncamelCase := true\n",
+		"q/q.rego": "package q\n\notherCamelCase := true\n",
+	})
+
+	var streamed []report.Violation
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case", "no-defined-entrypoint").
+		WithConcurrency(1).
+		WithViolationsHandler(func(_ context.Context, violations []report.Violation) error {
+			streamed = append(streamed, violations...)
+
+			return nil
+		}).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	assert.Equal(t, 0, len(result.Violations), "violations should not be retained in report")
+	assert.Equal(t, 3, len(streamed), "streamed violations")
+	assert.Equal(t, 3, result.Summary.NumViolations, "violations in summary")
+	assert.Equal(t, 2, result.Summary.FilesScanned, "files scanned")
+}
+
+func TestLintWithViolationsHandlerAggregates(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"p/p.rego": "package p\n\nallow := true\n",
+		"q/q.rego": "package q\n\n# regal ignore:unresolved-import\nimport data.p.missing\n\nx := missing\n",
+		"r/r.rego": "package r\n\nimport data.p.other\n\ny := other\n",
+	})
+
+	var streamed []report.Violation
+
+	// no concurrency set, and the reports of each file dropped as soon as they're streamed, while
+	// their aggregates and ignore directives are still used in the aggregate phase
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("unresolved-import").
+		WithViolationsHandler(func(_ context.Context, violations []report.Violation) error {
+			streamed = append(streamed, violations...)
+
+			return nil
+		}).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	must.Equal(t, 1, len(streamed), "streamed violations")
+	assert.Equal(t, "unresolved-import", streamed[0].Title, "title")
+	assert.Equal(t, filepath.Join(root, "r", "r.rego"), streamed[0].Location.File, "file")
+	assert.Equal(t, 1, result.Summary.NumViolations, "violations in summary")
+}
+
+func TestLintWithInputFS(t *testing.T) {
+	t.Parallel()
+
+	base := fstest.MapFS{
+		"p/p.rego":        {Data: []byte("package p\n\ncamelCase := true\n")},
+		"q/q.rego":        {Data: []byte("package q\n\notherCamelCase := true\n")},
+		"vendor/v.rego":   {Data: []byte("package v\n\nvendorCamelCase := true\n")},
+		"p/not_rego.json": {Data: []byte("{}")},
+	}
+
+	// the overlay fixes the violation in q.rego, and adds a file not in the base
+	overlay := rules.NewOverlayFS(base, map[string]string{
+		"q/q.rego": "package q\n\nsnake_case := true\n",
+		"r/r.rego": "package r\n\nunsavedCamelCase := true\n",
+	})
+
+	linter := regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case").
+		WithIgnore([]string{"vendor/*"}).
+		WithInputFS(overlay, ".")
+
+	result := must.Return(linter.Lint(t.Context()))(t)
+
+	files := util.Map(result.Violations, func(v report.Violation) string { return v.Location.File })
+	slices.Sort(files)
+
+	assert.SlicesEqual(t, []string{"p/p.rego", "r/r.rego"}, files, "files with violations")
+	assert.Equal(t, 3, result.Summary.FilesScanned, "files scanned")
+
+	var streamed []report.Violation
+
+	result = must.Return(linter.
+		WithViolationsHandler(func(_ context.Context, violations []report.Violation) error {
+			streamed = append(streamed, violations...)
+
+			return nil
+		}).
+		Lint(t.Context()))(t)
+
+	assert.Equal(t, 2, len(streamed), "streamed violations")
+	assert.Equal(t, 2, result.Summary.NumViolations, "violations in summary")
+
+	_, err := linter.WithInputPaths([]string{"p"}).Lint(t.Context())
+	assert.StringContains(t, fmt.Sprint(err), "can't be combined", "error combining input paths and filesystem")
+}
+
+func TestLintCancelledFromViolationsHandler(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{}
+	for i := range 10 {
+		fsys[fmt.Sprintf("p%d.rego", i)] = &fstest.MapFile{Data: fmt.Appendf(nil, "package p%d\n\ncamelCase := 1\n", i)}
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	calls := 0
+
+	_, err := regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case").
+		WithConcurrency(1).
+		WithViolationsHandler(func(_ context.Context, _ []report.Violation) error {
+			calls++
+
+			cancel()
+
+			return nil
+		}).
+		WithInputFS(fsys, ".").
+		Lint(ctx)
+
+	assert.True(t, errors.Is(err, context.Canceled), "expected context.Canceled error, got:", err)
+	assert.Equal(t, 1, calls, "handler calls after cancellation")
+}
+
+func TestLintPreparedLinterConcurrently(t *testing.T) {
+	t.Parallel()
+
+	linter := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case").
+		Prepare(t.Context()))(t)
+
+	var wg sync.WaitGroup
+
+	errs := make([]error, 8)
+
+	for i := range errs {
+		wg.Go(func() {
+			fsys := fstest.MapFS{}
+			for j := range i + 1 {
+				fsys[fmt.Sprintf("p%d.rego", j)] = &fstest.MapFile{Data: []byte("package p\n\ncamelCase := 1\n")}
+			}
+
+			result, err := linter.WithInputFS(fsys, ".").Lint(t.Context())
+			if err == nil && len(result.Violations) != i+1 {
+				err = fmt.Errorf("expected %d violations, got %d", i+1, len(result.Violations))
+			}
+
+			errs[i] = err
+		})
+	}
+
+	wg.Wait()
+
+	if err := errors.Join(errs...); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLintWithProgressHandler(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"p/p.rego": "package p\n\ncamelCase := true\n",
+		"q/q.rego": "package q\n\nimport data.unresolved\n",
+	})
+
+	events := map[string]int{}
+	violations := 0
+
+	must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("prefer-snake-case", "unresolved-import").
+		WithProgressHandler(func(event report.ProgressEvent) {
+			events[event.Event]++
+			violations += event.NumViolations
+		}).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	assert.MapsEqual(t, map[string]int{
+		report.ProgressFileStarted:       2,
+		report.ProgressFileFinished:      2,
+		report.ProgressAggregateStarted:  1,
+		report.ProgressAggregateFinished: 1,
+	}, events, "progress events")
+	assert.Equal(t, 2, violations, "violations reported in progress events")
+}
+
+func TestLintWithDataFiles(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"policy/policy.rego":     "package policy\n\nimport data.users\n\nallow if users.admins[input.user]\n",
+		"policy/data.json":       "{\"allow\": false}\n",
+		"roles/roles.rego":       "package roles\n\nadmin := \"admin\"\n",
+		"roles/data.json":        "{\"viewer\": {\n",
+		"users/data.yaml":        "admins:\n  alice: true\n  alice: false\n",
+		"users/nested/.manifest": "{}\n",
+		"users/nested/data.json": "{\"policy\": true}\n",
+		"ignored/data.json":      "{ not valid",
+	})
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("data-conflicts-with-rule", "duplicate-data-key", "invalid-data-file", "unresolved-import").
+		WithIgnore([]string{"ignored/"}).
+		WithPathPrefix(root).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	titles := make([]string, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		titles = append(titles, violation.Title+" "+filepath.Base(filepath.Dir(violation.Location.File)))
+	}
+
+	slices.Sort(titles)
+
+	// users/nested/data.json is mounted at data.policy by its .manifest file, conflicting with the package
+	assert.SlicesEqual(t, []string{
+		"data-conflicts-with-rule nested",
+		"data-conflicts-with-rule policy",
+		"duplicate-data-key users",
+		"invalid-data-file roles",
+	}, titles)
+}
+
+func TestLintWithDataFilesSingleFile(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"policy/policy.rego": "package policy\n\nallow if input.admin\n",
+		"policy/data.json":   "{\"allow\": false, \"allow\": true}\n",
+		"users/data.json":    "{ not valid",
+	})
+
+	// data files only take part in aggregate rules, which aren't run for a single policy
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("data-conflicts-with-rule", "duplicate-data-key", "invalid-data-file").
+		WithPathPrefix(root).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	assert.Equal(t, 0, len(result.Violations), "violations")
+}
+
+func TestLintWithSchemaPaths(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"policy/policy.rego": `# METADATA
+# schemas:
+#   - input: schema.k8s.pod
+#   - data.users: schema.users
+package policy
+`,
+		"schemas/k8s/pod.json": "{}",
+		".regal/config.yaml": `rules:
+  custom:
+    unresolved-metadata-schema:
+      level: error
+      schema-paths:
+        - schemas
+`,
+	})
+
+	// a single file is linted, but aggregate rules still need to run when schema paths are configured
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledRules("unresolved-metadata-schema").
+		WithUserConfig(must.Return(config.FromPath(filepath.Join(root, ".regal", "config.yaml")))(t)).
+		WithPathPrefix(root).
+		WithInputPaths([]string{filepath.Join(root, "policy", "policy.rego")}).
+		Lint(t.Context()))(t)
+
+	assert.Equal(t, 1, len(result.Violations), "violations")
+	assert.Equal(t, 4, result.Violations[0].Location.Row, "row")
+}
+
+func TestLintWithBundles(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"authz/.manifest": `{
+  "roots": ["authz", "authz/users"],
+  "file_rego_versions": {"/legacy.rego": 0},
+  "wasm": [{"entrypoint": "authz/deny", "module": "/policy.wasm"}]
+}
+`,
+		"authz/policy.rego": "package authz\n\nallow if true\n",
+		"authz/legacy.rego": "package authz.legacy\n\nallow if true\n",
+		"authz/other.rego":  "package other\n\nallow := true\n",
+		"users/policy.rego": "package users\n\nallow := true\n",
+		".regal/config.yaml": `project:
+  roots:
+    - path: users
+`,
+	})
+
+	result := must.Return(regal.NewLinter().
+		WithDisableAll(true).
+		WithEnabledCategories("bundle").
+		WithUserConfig(must.Return(config.FromPath(filepath.Join(root, ".regal", "config.yaml")))(t)).
+		WithPathPrefix(root).
+		WithInputPaths([]string{root}).
+		Lint(t.Context()))(t)
+
+	titles := make([]string, 0, len(result.Violations))
+	for _, violation := range result.Violations {
+		titles = append(titles, violation.Title+" "+filepath.Base(violation.Location.File))
+	}
+
+	slices.Sort(titles)
+
+	assert.SlicesEqual(t, []string{
+		"missing-manifest policy.rego",
+		"overlapping-roots .manifest",
+		"package-outside-roots other.rego",
+		"rego-version-mismatch .manifest",
+		"unresolved-wasm-entrypoint .manifest",
+	}, titles)
+}
+
+func TestLintWithManifestSingleFile(t *testing.T) {
+	t.Parallel()
+
+	policy := "package policy\n\nallow if input.admin\n"
+
+	lint := func(files map[string]string) []string {
+		root := testutil.TempDirectoryOf(t, files)
+
+		result := must.Return(regal.NewLinter().
+			WithPathPrefix(root).
+			WithInputPaths([]string{root}).
+			Lint(t.Context()))(t)
+
+		titles := make([]string, 0, len(result.Violations))
+		for _, violation := range result.Violations {
+			titles = append(titles, violation.Title)
+		}
+
+		return titles
+	}
+
+	// the manifest only takes part in aggregate rules, which aren't run for a single policy
+	assert.SlicesEqual(t,
+		lint(map[string]string{"policy/policy.rego": policy}),
+		lint(map[string]string{"policy/policy.rego": policy, "policy/.manifest": `{"roots": ["other"]}`}),
+	)
+}
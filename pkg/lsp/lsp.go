@@ -35,12 +35,22 @@ func New(ctx context.Context, ws *websocket.Conn, c *config.Config) (*Handle, er
 	}
 
 	ls := lsp.NewLanguageServerMinimal(ctx, &opts, c)
+
+	// the connection starts reading messages right away, so hold off handling them until it's been set
+	ready := make(chan struct{})
+	handle := func(ctx context.Context, conn *jsonrpc2.Conn, req *jsonrpc2.Request) (any, error) {
+		<-ready
+
+		return ls.Handle(ctx, conn, req)
+	}
+
 	jconn := jsonrpc2.NewConn(
 		ctx,
-		jsonrpc2_ws.NewObjectStream(ws),
-		jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(ls.Handle)),
+		NewObjectStream(ws),
+		jsonrpc2.AsyncHandler(jsonrpc2.HandlerWithError(handle)),
 	)
 	ls.SetConn(jconn)
+	close(ready)
 
 	ls.StartDiagnosticsWorker(ctx)
 	ls.StartTestLocationsWorker(ctx)
@@ -49,6 +59,12 @@ func New(ctx context.Context, ws *websocket.Conn, c *config.Config) (*Handle, er
 	return &Handle{conn: jconn, ls: ls}, nil
 }
 
+// NewObjectStream returns a stream of JSON-RPC messages exchanged over the websocket connection `ws`,
+// with one message per websocket message, as used for language server sessions with web clients.
+func NewObjectStream(ws *websocket.Conn) jsonrpc2.ObjectStream {
+	return jsonrpc2_ws.NewObjectStream(ws)
+}
+
 // Wait waits for the client to finish its exchange. It aborts if ctx is done.
 func (h *Handle) Wait(ctx context.Context) error {
 	select {
@@ -1,8 +1,11 @@
 package report
 
 import (
+	"cmp"
 	"fmt"
 	"slices"
+	"strings"
+	"time"
 
 	"github.com/open-policy-agent/opa/v1/ast"
 
@@ -68,10 +71,15 @@ type Report struct {
 	Aggregates       ast.Object              `json:"aggregates,omitempty"`
 	Metrics          map[string]any          `json:"metrics,omitempty"`
 	AggregateProfile map[string]ProfileEntry `json:"-"`
+	AggregateStats   map[string]RuleStats    `json:"-"`
 	IgnoreDirectives ast.Object              `json:"-"`
 	Violations       []Violation             `json:"violations"`
+	Suppressed       []Violation             `json:"-"`
 	Notices          []Notice                `json:"notices,omitempty"`
 	Profile          []ProfileEntry          `json:"profile,omitempty"`
+	Stats            []RuleStats             `json:"stats,omitempty"`
+	Complexity       []RuleComplexity        `json:"complexity,omitempty"`
+	FanIn            map[string]int          `json:"-"`
 	Summary          Summary                 `json:"summary"`
 }
 
@@ -85,6 +93,55 @@ type ProfileEntry struct {
 	NumGenExpr  int    `json:"num_gen_expr"`
 }
 
+// RuleStats is timing and violation statistics for a single linter rule, identified
+// by its category and title. This data may have been aggregated across multiple files,
+// and includes time spent in the aggregate phase, if any.
+type RuleStats struct {
+	Category      string `json:"category"`
+	Title         string `json:"title"`
+	TotalTimeNs   int64  `json:"total_time_ns"`
+	NumEval       int    `json:"num_eval"`
+	NumViolations int    `json:"num_violations"`
+	NumSuppressed int    `json:"num_suppressed"`
+}
+
+// RuleComplexity is the complexity metrics of a single rule or function, identified by its full
+// name. Rules defined more than once, possibly across several files, are counted as one per file.
+type RuleComplexity struct {
+	Name     string   `json:"name"`
+	Location Location `json:"location"`
+	Function bool     `json:"function"`
+	// Definitions is the number of definitions of the rule, not counting else branches.
+	Definitions int `json:"definitions"`
+	// Expressions is the number of expressions in the bodies of all definitions, including nested ones.
+	Expressions int `json:"expressions"`
+	// Nesting is the deepest nesting of comprehensions and every constructs in any definition.
+	Nesting int `json:"nesting"`
+	// FanOut is the number of distinct rules and documents referenced from the rule.
+	FanOut int `json:"fan_out"`
+	// FanIn is the number of other rules referencing the rule, across all linted files.
+	FanIn int `json:"fan_in"`
+	// Else is the number of else branches in all definitions.
+	Else int `json:"else"`
+}
+
+// Progress event types, as found in the Event attribute of ProgressEvent.
+const (
+	ProgressFileStarted       = "file_started"
+	ProgressFileFinished      = "file_finished"
+	ProgressAggregateStarted  = "aggregate_started"
+	ProgressAggregateFinished = "aggregate_finished"
+)
+
+// ProgressEvent describes progress made by the linter, like a file having been linted.
+// NumViolations is only set for events marking something as finished.
+type ProgressEvent struct {
+	Time          time.Time `json:"time"`
+	Event         string    `json:"event"`
+	File          string    `json:"file,omitempty"`
+	NumViolations int       `json:"num_violations,omitempty"`
+}
+
 func FromQueryResult(result ast.Value, aggregate bool) (r Report, err error) {
 	obj, ok := result.(ast.Object)
 	if !ok {
@@ -108,6 +165,15 @@ func FromQueryResult(result ast.Value, aggregate bool) (r Report, err error) {
 		})
 	}
 
+	if val, ok := rast.GetValue[ast.Set](obj, "suppressed"); ok {
+		r.Suppressed = make([]Violation, 0, val.Len())
+		val.Foreach(func(v *ast.Term) {
+			if vObj, ok := v.Value.(ast.Object); ok {
+				r.Suppressed = append(r.Suppressed, violationFromObject(vObj))
+			}
+		})
+	}
+
 	if notices, ok := rast.GetValue[ast.Set](obj, "notices"); ok {
 		for notice := range rast.ValuesOfType[ast.Object](notices.Slice()) {
 			r.Notices = append(r.Notices, NoticeFromObject(notice))
@@ -126,9 +192,48 @@ func FromQueryResult(result ast.Value, aggregate bool) (r Report, err error) {
 		r.IgnoreDirectives = val
 	}
 
+	if val, ok := rast.GetValue[ast.Object](obj, "complexity"); ok {
+		if aggregate {
+			err = ast.As(val, &r.FanIn)
+		} else {
+			err = complexityFromObject(val, &r)
+		}
+	}
+
 	return r, err
 }
 
+func complexityFromObject(obj ast.Object, r *Report) error {
+	var metrics map[string]RuleComplexity
+	if err := ast.As(obj, &metrics); err != nil {
+		return fmt.Errorf("failed to convert complexity metrics: %w", err)
+	}
+
+	r.Complexity = make([]RuleComplexity, 0, len(metrics))
+	for name, entry := range metrics {
+		entry.Name = name
+		r.Complexity = append(r.Complexity, entry)
+	}
+
+	return nil
+}
+
+// AddFanIn sets the fan-in of each rule in the complexity metrics of the report, and sorts the
+// metrics by location, for a stable order.
+func (r *Report) AddFanIn(fanIn map[string]int) {
+	for i := range r.Complexity {
+		r.Complexity[i].FanIn = fanIn[r.Complexity[i].Name]
+	}
+
+	slices.SortFunc(r.Complexity, func(a, b RuleComplexity) int {
+		return cmp.Or(
+			cmp.Compare(a.Location.File, b.Location.File),
+			cmp.Compare(a.Location.Row, b.Location.Row),
+			cmp.Compare(a.Name, b.Name),
+		)
+	})
+}
+
 func (r *Report) AddProfileEntries(prof map[string]ProfileEntry) {
 	if r.AggregateProfile == nil {
 		r.AggregateProfile = map[string]ProfileEntry{}
@@ -165,6 +270,42 @@ func (r *Report) AggregateProfileToSortedProfile(numResults int) {
 	r.Profile = r.Profile[:numResults]
 }
 
+// AddRuleStats merges the provided rule statistics into the aggregated statistics of the report.
+func (r *Report) AddRuleStats(stats map[string]RuleStats) {
+	if r.AggregateStats == nil {
+		r.AggregateStats = make(map[string]RuleStats, len(stats))
+	}
+
+	for key, entry := range stats {
+		if existing, ok := r.AggregateStats[key]; ok {
+			existing.TotalTimeNs += entry.TotalTimeNs
+			existing.NumEval += entry.NumEval
+			existing.NumViolations += entry.NumViolations
+			existing.NumSuppressed += entry.NumSuppressed
+			r.AggregateStats[key] = existing
+		} else {
+			r.AggregateStats[key] = entry
+		}
+	}
+}
+
+// AggregateStatsToSortedStats converts the aggregated rule statistics to a list sorted
+// by total time spent in each rule, with the most expensive rule first.
+func (r *Report) AggregateStatsToSortedStats() {
+	r.Stats = make([]RuleStats, 0, len(r.AggregateStats))
+	for key := range r.AggregateStats {
+		r.Stats = append(r.Stats, r.AggregateStats[key])
+	}
+
+	slices.SortFunc(r.Stats, func(a, b RuleStats) int {
+		if a.TotalTimeNs != b.TotalTimeNs {
+			return cmp.Compare(b.TotalTimeNs, a.TotalTimeNs)
+		}
+
+		return cmp.Or(strings.Compare(a.Category, b.Category), strings.Compare(a.Title, b.Title))
+	})
+}
+
 // ViolationsFileCount returns the number of files containing violations.
 func (r *Report) ViolationsFileCount() map[string]int {
 	fc := map[string]int{}
@@ -0,0 +1,205 @@
+package reporter
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	outil "github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/open-policy-agent/regal/internal/web"
+	"github.com/open-policy-agent/regal/pkg/report"
+)
+
+// HTMLReporter reports violations as a self-contained, single page HTML document, with a summary
+// of violations by category, level, rule and file, and the source of each file containing violations,
+// with violations highlighted inline.
+type HTMLReporter struct {
+	out      io.Writer
+	readFile func(string) ([]byte, error)
+}
+
+type htmlReport struct {
+	Tree    *htmlTreeNode
+	Notices []report.Notice
+	Global  []report.Violation
+	Files   []htmlFile
+	Counts  []htmlCounts
+	Summary report.Summary
+}
+
+type htmlCounts struct {
+	Title  string
+	Counts []htmlCount
+}
+
+type htmlCount struct {
+	Name  string
+	Count int
+}
+
+type htmlFile struct {
+	Name       string
+	ID         string
+	Violations []report.Violation
+	Lines      []htmlLine
+}
+
+type htmlLine struct {
+	Text       string
+	Level      string
+	Violations []report.Violation
+	Row        int
+}
+
+type htmlTreeNode struct {
+	Name     string
+	ID       string
+	Children []*htmlTreeNode
+	Count    int
+}
+
+// NewHTMLReporter creates a new HTMLReporter. Source files are read from disk, using
+// the file name found in the location of each violation.
+func NewHTMLReporter(out io.Writer) HTMLReporter {
+	return HTMLReporter{out: out, readFile: os.ReadFile}
+}
+
+// Publish prints an HTML report to the configured output.
+func (tr HTMLReporter) Publish(_ context.Context, r report.Report) error {
+	tmpl, err := web.ReportTemplate()
+	if err != nil {
+		return fmt.Errorf("failed to parse HTML report template: %w", err)
+	}
+
+	data := htmlReport{
+		Summary: r.Summary,
+		Tree:    &htmlTreeNode{},
+		Notices: slices.DeleteFunc(slices.Clone(r.Notices), func(n report.Notice) bool {
+			return n.Severity == "none"
+		}),
+	}
+
+	byCategory, byLevel, byRule, byFile := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	violationsPerFile := map[string][]report.Violation{}
+
+	for _, violation := range r.Violations { //nolint:gocritic
+		byCategory[violation.Category]++
+		byLevel[violation.Level]++
+		byRule[violation.Category+"/"+violation.Title]++
+
+		if violation.Location.File == "" {
+			data.Global = append(data.Global, violation)
+
+			continue
+		}
+
+		byFile[violation.Location.File]++
+		violationsPerFile[violation.Location.File] = append(violationsPerFile[violation.Location.File], violation)
+	}
+
+	data.Counts = []htmlCounts{
+		{Title: "Category", Counts: sortedCounts(byCategory)},
+		{Title: "Level", Counts: sortedCounts(byLevel)},
+		{Title: "Rule", Counts: sortedCounts(byRule)},
+		{Title: "File", Counts: sortedCounts(byFile)},
+	}
+
+	for i, name := range outil.KeysSorted(violationsPerFile) {
+		file := htmlFile{Name: name, ID: "file-" + strconv.Itoa(i), Violations: violationsPerFile[name]}
+
+		slices.SortStableFunc(file.Violations, func(a, b report.Violation) int {
+			return cmp.Or(cmp.Compare(a.Location.Row, b.Location.Row), cmp.Compare(a.Location.Column, b.Location.Column))
+		})
+
+		if bs, err := tr.readFile(name); err == nil {
+			file.Lines = sourceLines(string(bs), file.Violations)
+		}
+
+		data.Files = append(data.Files, file)
+		data.Tree.insert(strings.Split(filepath.ToSlash(name), "/"), file.ID, len(file.Violations))
+	}
+
+	data.Tree.compact()
+
+	if err := tmpl.Execute(tr.out, data); err != nil {
+		return fmt.Errorf("failed to render HTML report: %w", err)
+	}
+
+	return nil
+}
+
+// sourceLines splits the source into lines, attaching violations to the line they were found on.
+// Violations pointing past the last line are attached to the last line.
+func sourceLines(source string, violations []report.Violation) []htmlLine {
+	lines := make([]htmlLine, 0, strings.Count(source, "\n")+1)
+	for i, text := range strings.Split(strings.TrimSuffix(source, "\n"), "\n") {
+		lines = append(lines, htmlLine{Row: i + 1, Text: text})
+	}
+
+	for _, violation := range violations { //nolint:gocritic
+		idx := min(max(violation.Location.Row, 1), len(lines)) - 1
+		lines[idx].Violations = append(lines[idx].Violations, violation)
+
+		if lines[idx].Level != "error" {
+			lines[idx].Level = violation.Level
+		}
+	}
+
+	return lines
+}
+
+// insert adds a file to the tree, creating directory nodes as needed.
+func (n *htmlTreeNode) insert(parts []string, id string, count int) {
+	if len(parts) == 1 {
+		n.Children = append(n.Children, &htmlTreeNode{Name: parts[0], ID: id, Count: count})
+
+		return
+	}
+
+	for _, child := range n.Children {
+		if child.ID == "" && child.Name == parts[0] {
+			child.insert(parts[1:], id, count)
+
+			return
+		}
+	}
+
+	dir := &htmlTreeNode{Name: parts[0]}
+	n.Children = append(n.Children, dir)
+
+	dir.insert(parts[1:], id, count)
+}
+
+// compact merges directories having a single directory as their only child, so that
+// e.g. a/b/c.rego is presented as a/b/ -> c.rego rather than a/ -> b/ -> c.rego.
+func (n *htmlTreeNode) compact() {
+	for _, child := range n.Children {
+		for child.ID == "" && len(child.Children) == 1 && child.Children[0].ID == "" {
+			child.Name = path.Join(child.Name, child.Children[0].Name)
+			child.Children = child.Children[0].Children
+		}
+
+		child.compact()
+	}
+}
+
+func sortedCounts(counts map[string]int) []htmlCount {
+	sorted := make([]htmlCount, 0, len(counts))
+	for name, count := range counts {
+		sorted = append(sorted, htmlCount{Name: name, Count: count})
+	}
+
+	slices.SortFunc(sorted, func(a, b htmlCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Name, b.Name))
+	})
+
+	return sorted
+}
@@ -0,0 +1,196 @@
+package reporter
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	outil "github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/open-policy-agent/regal/pkg/report"
+)
+
+const (
+	// markdownMaxLength is the default maximum length of the Markdown report. GitHub limits comments
+	// to 65536 characters, and other code hosting platforms have similar limits.
+	markdownMaxLength = 60000
+	// markdownContextLines is the number of lines to include before and after the lines of a violation.
+	markdownContextLines = 2
+)
+
+// MarkdownReporter reports violations as Markdown, suitable for posting as a pull request comment.
+// Violations are grouped by file in collapsible sections, and the report is truncated to stay within
+// the size limits of comments on typical code hosting platforms.
+type MarkdownReporter struct {
+	out       io.Writer
+	readFile  func(string) ([]byte, error)
+	maxLength int
+}
+
+// NewMarkdownReporter creates a new MarkdownReporter. Source files are read from disk, using
+// the file name found in the location of each violation.
+func NewMarkdownReporter(out io.Writer) MarkdownReporter {
+	return MarkdownReporter{out: out, readFile: os.ReadFile, maxLength: markdownMaxLength}
+}
+
+// Publish prints a Markdown report to the configured output.
+func (tr MarkdownReporter) Publish(_ context.Context, r report.Report) error {
+	var sb strings.Builder
+
+	sb.WriteString("### Regal Lint Report\n\n")
+	fmt.Fprintf(&sb, "%d %s linted.", r.Summary.FilesScanned, pluralize("file", r.Summary.FilesScanned))
+
+	if r.Summary.NumViolations == 0 {
+		sb.WriteString(" No violations found.\n")
+	} else {
+		fmt.Fprintf(&sb, " %d %s found", r.Summary.NumViolations, pluralize("violation", r.Summary.NumViolations))
+
+		if r.Summary.FilesFailed > 0 {
+			fmt.Fprintf(&sb, " in %d %s", r.Summary.FilesFailed, pluralize("file", r.Summary.FilesFailed))
+		}
+
+		sb.WriteString(".\n")
+	}
+
+	violationsPerFile := map[string][]report.Violation{}
+	for _, violation := range r.Violations { //nolint:gocritic
+		violationsPerFile[violation.Location.File] = append(violationsPerFile[violation.Location.File], violation)
+	}
+
+	omittedViolations, omittedFiles := 0, 0
+
+	for _, name := range outil.KeysSorted(violationsPerFile) {
+		violations := violationsPerFile[name]
+
+		if omittedViolations > 0 {
+			omittedViolations += len(violations)
+			omittedFiles++
+
+			continue
+		}
+
+		slices.SortStableFunc(violations, func(a, b report.Violation) int {
+			return cmp.Or(cmp.Compare(a.Location.Row, b.Location.Row), cmp.Compare(a.Location.Column, b.Location.Column))
+		})
+
+		var lines []string
+
+		summary := "Workspace"
+		if name != "" {
+			summary = "<code>" + html.EscapeString(name) + "</code>"
+
+			if bs, err := tr.readFile(name); err == nil {
+				lines = strings.Split(strings.TrimSuffix(string(bs), "\n"), "\n")
+			}
+		}
+
+		section := fmt.Sprintf(
+			"\n<details>\n<summary>%s (%d %s)</summary>\n",
+			summary, len(violations), pluralize("violation", len(violations)),
+		)
+
+		included := 0
+
+		for _, violation := range violations { //nolint:gocritic
+			entry := markdownViolation(violation, lines)
+
+			// reserve room for closing the section and for the line summarizing what was omitted
+			if sb.Len()+len(section)+len(entry)+len("\n</details>\n")+200 > tr.maxLength {
+				break
+			}
+
+			section += entry
+			included++
+		}
+
+		if included > 0 {
+			sb.WriteString(section + "\n</details>\n")
+		}
+
+		if included < len(violations) {
+			omittedViolations += len(violations) - included
+			omittedFiles++
+		}
+	}
+
+	if omittedViolations > 0 {
+		fmt.Fprintf(&sb,
+			"\n%d %s in %d %s omitted to stay within the size limit. Run `regal lint` locally for the full report.\n",
+			omittedViolations, pluralize("violation", omittedViolations), omittedFiles, pluralize("file", omittedFiles),
+		)
+	}
+
+	_, err := io.WriteString(tr.out, sb.String())
+
+	return err
+}
+
+func markdownViolation(violation report.Violation, lines []string) string {
+	var sb strings.Builder
+
+	sb.WriteString("\n#### ")
+
+	if url := getDocumentationURL(violation); url != "" {
+		fmt.Fprintf(&sb, "[%s](%s)", violation.Title, url)
+	} else {
+		sb.WriteString(violation.Title)
+	}
+
+	fmt.Fprintf(&sb, "\n\n%s (%s, %s)", violation.Description, violation.Category, violation.Level)
+
+	if violation.Location.Row > 0 {
+		fmt.Fprintf(&sb, " at line %d, column %d", violation.Location.Row, violation.Location.Column)
+	}
+
+	sb.WriteString("\n")
+
+	if snippet := markdownSnippet(violation.Location, lines); snippet != "" {
+		fence := "```"
+		for strings.Contains(snippet, fence) {
+			fence += "`"
+		}
+
+		fmt.Fprintf(&sb, "\n%s\n%s\n%s\n", fence, snippet, fence)
+	}
+
+	return sb.String()
+}
+
+// markdownSnippet returns the lines of the violation along with the lines surrounding it, prefixed by
+// their row numbers and with the lines of the violation marked by '>'. If the source is not available,
+// the text of the location is returned as-is.
+func markdownSnippet(location report.Location, lines []string) string {
+	if location.Row < 1 || location.Row > len(lines) {
+		if location.Text != nil {
+			return *location.Text
+		}
+
+		return ""
+	}
+
+	end := location.Row
+	if location.End != nil && location.End.Row > end {
+		end = min(location.End.Row, len(lines))
+	}
+
+	first, last := max(location.Row-markdownContextLines, 1), min(end+markdownContextLines, len(lines))
+	width := len(strconv.Itoa(last))
+	snippet := make([]string, 0, last-first+1)
+
+	for row := first; row <= last; row++ {
+		marker := " "
+		if row >= location.Row && row <= end {
+			marker = ">"
+		}
+
+		snippet = append(snippet, fmt.Sprintf("%s %*d | %s", marker, width, row, lines[row-1]))
+	}
+
+	return strings.Join(snippet, "\n")
+}
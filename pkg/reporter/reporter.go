@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/jstemmer/go-junit-report/v2/junit"
@@ -29,6 +31,16 @@ type Reporter interface {
 	Publish(context.Context, report.Report) error
 }
 
+// StreamingReporter is a Reporter capable of publishing violations as they are found, rather
+// than all at once when linting has finished. When streaming, the report later provided to
+// Publish contains the summary and any other data, but not the violations already published.
+type StreamingReporter interface {
+	Reporter
+	// PublishViolations releases violations found in a single file, or by aggregate rules,
+	// to any appropriate target. Calls are never made concurrently.
+	PublishViolations(context.Context, []report.Violation) error
+}
+
 // PrettyReporter is a Reporter for representing reports as tables.
 type PrettyReporter struct {
 	out io.Writer
@@ -44,6 +56,17 @@ type JSONReporter struct {
 	out io.Writer
 }
 
+// JSONLinesReporter reports violations as JSON Lines (https://jsonlines.org/), with one violation
+// per line, followed by a final line containing the summary and any other data from the report.
+type JSONLinesReporter struct {
+	out io.Writer
+}
+
+// ProgressReporter reports linter progress events as JSON Lines, with one event per line.
+type ProgressReporter struct {
+	out io.Writer
+}
+
 // GitHubReporter reports violations in a format suitable for GitHub Actions.
 type GitHubReporter struct {
 	out io.Writer
@@ -80,6 +103,16 @@ func NewJSONReporter(out io.Writer) JSONReporter {
 	return JSONReporter{out: out}
 }
 
+// NewJSONLinesReporter creates a new JSONLinesReporter.
+func NewJSONLinesReporter(out io.Writer) JSONLinesReporter {
+	return JSONLinesReporter{out: out}
+}
+
+// NewProgressReporter creates a new ProgressReporter.
+func NewProgressReporter(out io.Writer) ProgressReporter {
+	return ProgressReporter{out: out}
+}
+
 // NewGitHubReporter creates a new GitHubReporter.
 func NewGitHubReporter(out io.Writer) GitHubReporter {
 	return GitHubReporter{out: out}
@@ -160,6 +193,12 @@ func (tr PrettyReporter) Publish(_ context.Context, r report.Report) error {
 		return fmt.Errorf("failed to write report: %w", err)
 	}
 
+	if len(r.Stats) > 0 {
+		if _, err = fmt.Fprintln(tr.out, buildStatsTable(r.Stats)); err != nil {
+			return fmt.Errorf("failed to write rule stats: %w", err)
+		}
+	}
+
 	if !mode.Standalone { // don't bother advertising `regal fix` when not in standalone mode
 		return nil
 	}
@@ -274,10 +313,39 @@ func buildPrettyViolationsTable(violations []report.Violation) string {
 	return sb.String() + end
 }
 
-// Publish prints a compact report to the configured output.
+// PublishViolations prints violations to the configured output as they are found, one line per violation.
+func (tr CompactReporter) PublishViolations(_ context.Context, violations []report.Violation) error {
+	for i := range violations {
+		line := violations[i].Description
+		if location := violations[i].Location.String(); location != "" {
+			line = location + ": " + line
+		}
+
+		if _, err := fmt.Fprintln(tr.out, line); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Publish prints a compact report to the configured output. If the violations of the report have
+// already been published via PublishViolations, only the summary is printed.
 func (tr CompactReporter) Publish(_ context.Context, r report.Report) error {
+	if len(r.Violations) == 0 && r.Summary.NumViolations > 0 {
+		_, err := fmt.Fprintln(tr.out, compactSummary(r))
+		if err == nil && len(r.Stats) > 0 {
+			_, err = fmt.Fprintln(tr.out, buildStatsTable(r.Stats))
+		}
+
+		return err
+	}
+
 	if len(r.Violations) == 0 {
 		_, err := fmt.Fprintln(tr.out)
+		if err == nil && len(r.Stats) > 0 {
+			_, err = fmt.Fprintln(tr.out, buildStatsTable(r.Stats))
+		}
 
 		return err
 	}
@@ -297,15 +365,54 @@ func (tr CompactReporter) Publish(_ context.Context, r report.Report) error {
 		table.Append([]string{r.Violations[i].Location.String(), r.Violations[i].Description})
 	}
 
-	summary := fmt.Sprintf("%d %s linted , %d %s found.",
+	table.Render()
+
+	if _, err := fmt.Fprintln(tr.out, strings.TrimSuffix(sb.String(), ""), compactSummary(r)); err != nil {
+		return err
+	}
+
+	if len(r.Stats) > 0 {
+		_, err := fmt.Fprintln(tr.out, buildStatsTable(r.Stats))
+
+		return err
+	}
+
+	return nil
+}
+
+func compactSummary(r report.Report) string {
+	return fmt.Sprintf("%d %s linted , %d %s found.",
 		r.Summary.FilesScanned, pluralize("file", r.Summary.FilesScanned),
 		r.Summary.NumViolations, pluralize("violation", r.Summary.NumViolations))
+}
 
-	table.Render()
+// buildStatsTable renders rule stats as a table, in the order provided.
+func buildStatsTable(stats []report.RuleStats) string {
+	sb := &strings.Builder{}
+	table := tablewriter.NewTable(sb, tablewriter.WithConfig(tablewriter.Config{
+		Row: tw.CellConfig{
+			Alignment: tw.CellAlignment{PerColumn: []tw.Align{
+				tw.AlignLeft, tw.AlignLeft, tw.AlignRight, tw.AlignRight, tw.AlignRight, tw.AlignRight,
+			}},
+		},
+	}))
 
-	_, err := fmt.Fprintln(tr.out, strings.TrimSuffix(sb.String(), ""), summary)
+	table.Header([]string{"Category", "Rule", "Time", "Evals", "Violations", "Suppressed"})
+
+	for i := range stats {
+		table.Append([]string{
+			stats[i].Category,
+			stats[i].Title,
+			time.Duration(stats[i].TotalTimeNs).String(),
+			strconv.Itoa(stats[i].NumEval),
+			strconv.Itoa(stats[i].NumViolations),
+			strconv.Itoa(stats[i].NumSuppressed),
+		})
+	}
 
-	return err
+	table.Render()
+
+	return sb.String()
 }
 
 // Publish prints a JSON report to the configured output.
@@ -318,6 +425,48 @@ func (tr JSONReporter) Publish(_ context.Context, r report.Report) error {
 	return enc.Encode(r)
 }
 
+// PublishViolations prints violations to the configured output as they are found, one JSON object per line.
+func (tr JSONLinesReporter) PublishViolations(_ context.Context, violations []report.Violation) error {
+	enc := encoding.JSON().NewEncoder(tr.out)
+
+	for i := range violations {
+		if err := enc.Encode(violations[i]); err != nil {
+			return fmt.Errorf("failed to write violation: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Publish prints any violations not already published via PublishViolations to the configured output,
+// one JSON object per line, followed by a final object containing the summary and other data of the report.
+func (tr JSONLinesReporter) Publish(ctx context.Context, r report.Report) error {
+	if err := tr.PublishViolations(ctx, r.Violations); err != nil {
+		return err
+	}
+
+	return encoding.JSON().NewEncoder(tr.out).Encode(struct {
+		Metrics    map[string]any          `json:"metrics,omitempty"`
+		Notices    []report.Notice         `json:"notices,omitempty"`
+		Profile    []report.ProfileEntry   `json:"profile,omitempty"`
+		Stats      []report.RuleStats      `json:"stats,omitempty"`
+		Complexity []report.RuleComplexity `json:"complexity,omitempty"`
+		Summary    report.Summary          `json:"summary"`
+	}{
+		Metrics:    r.Metrics,
+		Notices:    r.Notices,
+		Profile:    r.Profile,
+		Stats:      r.Stats,
+		Complexity: r.Complexity,
+		Summary:    r.Summary,
+	})
+}
+
+// PublishProgress prints a single progress event to the configured output, as one JSON object on one line.
+func (pr ProgressReporter) PublishProgress(event report.ProgressEvent) error {
+	return encoding.JSON().NewEncoder(pr.out).Encode(event)
+}
+
 // Publish first prints the pretty formatted report to console for easy access in the logs. It then goes on
 // to print the GitHub Actions annotations for each violation. Finally, it prints a summary of the report suitable
 // for the GitHub Actions UI.
@@ -2,6 +2,7 @@ package reporter
 
 import (
 	"bytes"
+	"os"
 	"strings"
 	"testing"
 
@@ -141,6 +142,24 @@ func TestCompactReporterPublishNoViolations(t *testing.T) {
 	assert.Equal(t, "\n", buf.String(), "compact output")
 }
 
+func TestCompactReporterPublishStreaming(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	cr := NewCompactReporter(&buf)
+	must.Equal(t, nil, cr.PublishViolations(t.Context(), rep.Violations[:1]))
+	must.Equal(t, nil, cr.PublishViolations(t.Context(), nil))
+	must.Equal(t, nil, cr.PublishViolations(t.Context(), rep.Violations[1:]))
+	must.Equal(t, nil, cr.Publish(t.Context(), report.Report{Summary: rep.Summary}))
+
+	expect := `a.rego:1:1: Rego must not break the law!
+b.rego:22:18: Questionable decision found
+3 files linted , 2 violations found.
+`
+	assert.Equal(t, expect, buf.String(), "compact output")
+}
+
 func TestJSONReporterPublish(t *testing.T) {
 	t.Parallel()
 
@@ -157,6 +176,141 @@ func TestJSONReporterPublishNoViolations(t *testing.T) {
 	assert.Equal(t, must.ReadFile(t, "testdata/json/reporter-no-violations.json"), buf.String(), "json output")
 }
 
+func TestJSONLinesReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	must.Equal(t, nil, NewJSONLinesReporter(&buf).Publish(t.Context(), rep))
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	must.Equal(t, 3, len(lines), "number of lines")
+
+	first := must.Unmarshal[report.Violation](t, []byte(lines[0]))
+	assert.Equal(t, "breaking-the-law", first.Title, "title of first violation")
+
+	second := must.Unmarshal[report.Violation](t, []byte(lines[1]))
+	assert.Equal(t, "questionable-decision", second.Title, "title of second violation")
+
+	summary := must.Unmarshal[map[string]any](t, []byte(lines[2]))
+	assert.Equal(t, 2, len(summary["notices"].([]any)), "number of notices")
+	assert.Equal(t, 2.0, summary["summary"].(map[string]any)["num_violations"].(float64), "violations in summary")
+}
+
+func TestJSONLinesReporterPublishStreaming(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	jr := NewJSONLinesReporter(&buf)
+	must.Equal(t, nil, jr.PublishViolations(t.Context(), rep.Violations[:1]))
+	must.Equal(t, nil, jr.Publish(t.Context(), report.Report{
+		Summary: report.Summary{FilesScanned: 1, NumViolations: 1},
+	}))
+
+	expect := `{"title":"breaking-the-law","description":"Rego must not break the law!",` +
+		`"category":"legal","level":"error",` +
+		`"related_resources":[{"description":"documentation","ref":"https://example.com/illegal"}],` +
+		`"location":{"end":{"row":1,"col":14},"text":"package illegal","file":"a.rego","col":1,"row":1}}
+{"summary":{"files_scanned":1,"files_failed":0,"rules_skipped":0,"num_violations":1}}
+`
+	assert.Equal(t, expect, buf.String(), "jsonl output")
+}
+
+func TestProgressReporterPublishProgress(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	pr := NewProgressReporter(&buf)
+	must.Equal(t, nil, pr.PublishProgress(report.ProgressEvent{Event: report.ProgressFileStarted, File: "a.rego"}))
+	must.Equal(t, nil, pr.PublishProgress(report.ProgressEvent{
+		Event: report.ProgressFileFinished, File: "a.rego", NumViolations: 2,
+	}))
+
+	expect := `{"time":"0001-01-01T00:00:00Z","event":"file_started","file":"a.rego"}
+{"time":"0001-01-01T00:00:00Z","event":"file_finished","file":"a.rego","num_violations":2}
+`
+	assert.Equal(t, expect, buf.String(), "progress output")
+}
+
+func TestHTMLReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	sources := map[string]string{
+		"a.rego": "package illegal\n",
+		"b.rego": strings.Repeat("\n", 21) + "default allow = true # <script>\n",
+	}
+
+	var buf bytes.Buffer
+
+	hr := HTMLReporter{out: &buf, readFile: func(name string) ([]byte, error) {
+		return []byte(sources[name]), nil
+	}}
+	must.Equal(t, nil, hr.Publish(t.Context(), rep))
+
+	out := buf.String()
+
+	assert.StringContains(t, out, "<title>Regal Lint Report</title>")
+	assert.StringContains(t, out, "<tr><td>legal</td><td class=\"count\">1</td></tr>")
+	assert.StringContains(t, out, "<tr><td>warning</td><td class=\"count\">1</td></tr>")
+	assert.StringContains(t, out, `<li><a href="#file-1">b.rego</a> (1)`)
+	assert.StringContains(t, out, `<h2 id="file-0">a.rego</h2>`)
+	assert.StringContains(t, out, `<tr class="line-error">
+            <td class="row">1</td>`)
+	assert.StringContains(t, out, `<tr class="line-warning">
+            <td class="row">22</td>`)
+	assert.StringContains(t, out, "<pre>default allow = true # &lt;script&gt;</pre>")
+	assert.StringContains(t, out, `<a href="https://example.com/questionable">documentation</a>`)
+	assert.StringContains(t, out, "<strong>rule-missing-capability</strong>")
+}
+
+func TestMarkdownReporterPublish(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	mr := MarkdownReporter{out: &buf, maxLength: markdownMaxLength, readFile: func(name string) ([]byte, error) {
+		if name == "a.rego" {
+			return []byte("package illegal\n\nimport rego.v1\n\nallow := true\n"), nil
+		}
+
+		return nil, os.ErrNotExist
+	}}
+	must.Equal(t, nil, mr.Publish(t.Context(), rep))
+
+	expect := "### Regal Lint Report\n\n3 files linted. 2 violations found in 2 files.\n" +
+		"\n<details>\n<summary><code>a.rego</code> (1 violation)</summary>\n" +
+		"\n#### [breaking-the-law](https://example.com/illegal)\n" +
+		"\nRego must not break the law! (legal, error) at line 1, column 1\n" +
+		"\n```\n> 1 | package illegal\n  2 | \n  3 | import rego.v1\n```\n" +
+		"\n</details>\n" +
+		"\n<details>\n<summary><code>b.rego</code> (1 violation)</summary>\n" +
+		"\n#### [questionable-decision](https://example.com/questionable)\n" +
+		"\nQuestionable decision found (really?, warning) at line 22, column 18\n" +
+		"\n```\ndefault allow = true\n```\n" +
+		"\n</details>\n"
+
+	assert.Equal(t, expect, buf.String())
+}
+
+func TestMarkdownReporterPublishTruncated(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+
+	mr := MarkdownReporter{out: &buf, maxLength: 550, readFile: func(string) ([]byte, error) {
+		return nil, os.ErrNotExist
+	}}
+	must.Equal(t, nil, mr.Publish(t.Context(), rep))
+
+	out := buf.String()
+
+	assert.StringContains(t, out, "breaking-the-law")
+	assert.True(t, !strings.Contains(out, "questionable-decision"), "expected questionable-decision to be omitted")
+	assert.StringContains(t, out, "\n1 violation in 1 file omitted to stay within the size limit.")
+	assert.True(t, len(out) <= 550, "expected output to be within the size limit")
+}
+
 func TestGitHubReporterPublish(t *testing.T) {
 	// Can't use t.Parallel() here because t.Setenv() forbids that
 	t.Setenv("GITHUB_STEP_SUMMARY", "")
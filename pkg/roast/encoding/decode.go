@@ -0,0 +1,809 @@
+package encoding
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/format"
+)
+
+// DecodeModule decodes a module from RoAST JSON, like that produced by `regal parse`. This is the reverse
+// of the RoAST encoding, and includes comments and annotations. Locations and comments can only be restored
+// when the source lines are present in the regal.file.lines attribute, as the location strings of RoAST don't
+// carry the text needed by the formatter. Without them, the module is decoded without locations or comments.
+func DecodeModule(bs []byte) (*ast.Module, error) {
+	mod, _, err := decodeModule(bs)
+
+	return mod, err
+}
+
+// Unparse decodes a module from RoAST JSON and formats it as Rego. When the source lines aren't present in
+// the RoAST, and comments consequently can't be restored, annotations are written as METADATA comments.
+func Unparse(bs []byte) (result []byte, err error) {
+	mod, d, err := decodeModule(bs)
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		// the formatter panics on some ASTs it doesn't expect, which decoded RoAST may well be
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("failed to format module: %v", r)
+		}
+	}()
+
+	if d.content == nil {
+		// nodes without locations are laid out by the formatter as if on a single line, which it does by
+		// assigning them a location, except for terms in rule head refs, which are not walked
+		ast.WalkRules(mod, func(rule *ast.Rule) bool {
+			ast.WalkTerms(rule.Head.Reference, func(term *ast.Term) bool {
+				if term.Location == nil {
+					term.Location = ast.NewLocation([]byte(term.String()), d.file, 1, 1)
+				}
+
+				return false
+			})
+
+			return false
+		})
+	}
+
+	if result, err = format.AstWithOpts(mod, format.Opts{RegoVersion: mod.RegoVersion()}); err != nil {
+		return nil, fmt.Errorf("failed to format module: %w", err)
+	}
+
+	if d.content != nil || len(mod.Annotations) == 0 {
+		return result, nil
+	}
+
+	return insertMetadataComments(result, mod)
+}
+
+// decoder builds AST nodes from RoAST, as unmarshalled into maps and slices. The first error encountered
+// is kept in err, and decoding any further nodes after that is a no-op.
+type decoder struct {
+	err     error
+	file    string
+	content []byte
+	// offset of the first byte of each line in content
+	offsets []int
+}
+
+func decodeModule(bs []byte) (*ast.Module, *decoder, error) {
+	var doc map[string]any
+	if err := SafeNumberConfig.Unmarshal(bs, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to unmarshal RoAST: %w", err)
+	}
+
+	d := &decoder{}
+
+	file := d.object(d.object(doc["regal"], "regal", true)["file"], "regal.file", true)
+	if mod := d.module(doc, file); d.err == nil {
+		return mod, d, nil
+	}
+
+	return nil, nil, d.err
+}
+
+func (d *decoder) module(doc, file map[string]any) *ast.Module {
+	d.file, _ = file["name"].(string)
+
+	pkg := d.object(doc["package"], "package", false)
+
+	// source lines are of no use without locations, like in RoAST with locations stripped
+	if lines, ok := file["lines"].([]any); ok && pkg["location"] != nil {
+		var buf bytes.Buffer
+
+		d.offsets = make([]int, 0, len(lines))
+
+		for i, line := range lines {
+			if i > 0 {
+				buf.WriteByte('\n')
+			}
+
+			d.offsets = append(d.offsets, buf.Len())
+			buf.WriteString(d.string(line, "line"))
+		}
+
+		d.content = buf.Bytes()
+	}
+
+	mod := &ast.Module{}
+
+	switch file["rego_version"] {
+	case "v0":
+		mod.SetRegoVersion(ast.RegoV0)
+	case "v0v1":
+		mod.SetRegoVersion(ast.RegoV0CompatV1)
+	default:
+		mod.SetRegoVersion(ast.RegoV1)
+	}
+
+	mod.Package = &ast.Package{Location: d.location(pkg["location"]), Path: ast.Ref(d.terms(pkg["path"]))}
+	mod.Annotations = d.annotations(pkg["annotations"])
+
+	for _, v := range d.array(doc["imports"], "imports", true) {
+		imp := d.object(v, "import", false)
+		mod.Imports = append(mod.Imports, &ast.Import{
+			Location: d.location(imp["location"]),
+			Path:     d.term(imp["path"]),
+			Alias:    ast.Var(d.optionalString(imp["alias"], "alias")),
+		})
+	}
+
+	// document scoped annotations are found on each rule of the document, but only once in the module
+	documents := make(map[string][]*ast.Annotations)
+
+	for _, v := range d.array(doc["rules"], "rules", true) {
+		rule := d.rule(v, mod, nil)
+		mod.Rules = append(mod.Rules, rule)
+
+		for _, a := range rule.Annotations {
+			if a.Scope == "document" {
+				path := rule.Head.Ref().GroundPrefix().String()
+				if slices.ContainsFunc(documents[path], func(other *ast.Annotations) bool { return a.Compare(other) == 0 }) {
+					continue
+				}
+
+				documents[path] = append(documents[path], a)
+			}
+
+			mod.Annotations = append(mod.Annotations, a)
+		}
+	}
+
+	for _, v := range d.array(doc["comments"], "comments", true) {
+		// comment text is only available from the source lines, and the location of a METADATA comment
+		// spans the whole block of annotations, of which only the first line is the text of the comment
+		if loc := d.location(d.string(v, "comment")); loc != nil && len(loc.Text) > 0 && loc.Text[0] == '#' {
+			text, _, _ := bytes.Cut(loc.Text[1:], []byte("\n"))
+			mod.Comments = append(mod.Comments, &ast.Comment{Text: text, Location: loc})
+		}
+	}
+
+	return mod
+}
+
+func (d *decoder) rule(v any, mod *ast.Module, parent *ast.Head) *ast.Rule {
+	obj := d.object(v, "rule", false)
+	rule := &ast.Rule{
+		Location:    d.location(obj["location"]),
+		Annotations: d.annotations(obj["annotations"]),
+		Default:     d.bool(obj["default"], "default"),
+		Module:      mod,
+	}
+
+	body, hasBody := obj["body"]
+	head := d.head(obj["head"], hasBody)
+	rule.Head = head
+
+	if hasBody {
+		rule.Body = d.body(body)
+	} else {
+		// the body is omitted from RoAST when generated, which the parser does with the location of the rule
+		rule.Body = ast.NewBody(ast.NewExpr(ast.BooleanTerm(true).SetLocation(rule.Location)).SetLocation(rule.Location))
+	}
+
+	// the name is kept by the parser for backwards compatibility, and for the heads of else rules,
+	// copied from the head of the rule they belong to
+	switch {
+	case parent != nil:
+		head.Name = parent.Name
+	case len(head.Reference) == 1:
+		head.Name, _ = head.Reference[0].Value.(ast.Var)
+	case len(head.Reference) != 2 || rule.Default || head.Value == nil:
+	case !hasBody && len(head.Args) == 0, hasBody && (len(head.Args) > 0 || !isGeneratedValue(head)):
+		// p.q := 1, or p.q := 1 if ..., but not p[x] if ...
+		head.Name, _ = head.Reference[0].Value.(ast.Var)
+	}
+
+	if els, ok := obj["else"]; ok {
+		rule.Else = d.rule(els, mod, head)
+	}
+
+	return rule
+}
+
+func (d *decoder) head(v any, hasBody bool) *ast.Head {
+	obj := d.object(v, "head", false)
+	head := &ast.Head{
+		Location:  d.location(obj["location"]),
+		Reference: ast.Ref(d.terms(obj["ref"])),
+		Args:      ast.Args(d.optionalTerms(obj["args"])),
+		Assign:    d.bool(obj["assign"], "assign"),
+	}
+
+	if key, ok := obj["key"]; ok {
+		head.Key = d.term(key)
+	}
+
+	value, ok := obj["value"]
+	if !ok {
+		return head
+	}
+
+	head.Value = d.term(value)
+
+	// generated values have the location of the head, which is omitted from RoAST. Without source lines,
+	// a `true` value is assumed to be generated only for functions, or when followed by a body, as in
+	// `f(x)` or `p if { ... }`
+	if head.Value.Location == nil && ast.InternedTerm(true).Equal(head.Value) &&
+		(d.content != nil || hasBody || len(head.Args) > 0) {
+		// a location is still needed to tell the formatter that the value is generated
+		if head.Location == nil {
+			head.Location = ast.NewLocation([]byte(head.String()), d.file, 1, 1)
+		}
+
+		head.Value.Location = head.Location
+	}
+
+	return head
+}
+
+// isGeneratedValue reports whether the value of the head was generated, which like in the parser, is
+// told by the value sharing the location of the head.
+func isGeneratedValue(head *ast.Head) bool {
+	return head.Value.Location != nil && head.Value.Location == head.Location
+}
+
+func (d *decoder) body(v any) ast.Body {
+	arr := d.array(v, "body", false)
+	body := make(ast.Body, 0, len(arr))
+
+	for i, e := range arr {
+		expr := d.expr(e)
+		expr.Index = i
+
+		body = append(body, expr)
+	}
+
+	return body
+}
+
+func (d *decoder) expr(v any) *ast.Expr {
+	obj := d.object(v, "expression", false)
+	expr := &ast.Expr{
+		Location:  d.location(obj["location"]),
+		Negated:   d.bool(obj["negated"], "negated"),
+		Generated: d.bool(obj["generated"], "generated"),
+	}
+
+	for _, w := range d.array(obj["with"], "with", true) {
+		with := d.object(w, "with", false)
+		expr.With = append(expr.With, &ast.With{
+			Location: d.location(with["location"]),
+			Target:   d.term(with["target"]),
+			Value:    d.term(with["value"]),
+		})
+	}
+
+	switch terms := obj["terms"].(type) {
+	case []any:
+		expr.Terms = d.terms(terms)
+	case map[string]any:
+		_, hasBody := terms["body"]
+		_, hasLhs := terms["lhs"]
+
+		switch {
+		case terms["symbols"] != nil:
+			expr.Terms = &ast.SomeDecl{Location: d.location(terms["location"]), Symbols: d.terms(terms["symbols"])}
+		case terms["domain"] != nil:
+			expr.Terms = d.every(terms)
+		case terms["type"] == "not" && hasBody:
+			expr.Terms = d.not(terms)
+		case terms["type"] == "and" && hasLhs:
+			expr.Terms = &ast.LogicalAnd{
+				Location:    d.location(terms["location"]),
+				Lhs:         d.body(terms["lhs"]),
+				Rhs:         d.body(terms["rhs"]),
+				ExplicitLhs: d.bool(terms["explicit_lhs"], "explicit_lhs"),
+				ExplicitRhs: d.bool(terms["explicit_rhs"], "explicit_rhs"),
+			}
+		case terms["type"] == "or" && hasLhs:
+			expr.Terms = &ast.LogicalOr{
+				Location:    d.location(terms["location"]),
+				Lhs:         d.body(terms["lhs"]),
+				Rhs:         d.body(terms["rhs"]),
+				ExplicitLhs: d.bool(terms["explicit_lhs"], "explicit_lhs"),
+				ExplicitRhs: d.bool(terms["explicit_rhs"], "explicit_rhs"),
+			}
+		default:
+			expr.Terms = d.term(terms)
+		}
+	default:
+		d.fail("expression terms", obj["terms"])
+	}
+
+	return expr
+}
+
+func (d *decoder) every(obj map[string]any) *ast.Every {
+	every := &ast.Every{
+		Location: d.location(obj["location"]),
+		Value:    d.term(obj["value"]),
+		Domain:   d.term(obj["domain"]),
+		Body:     d.body(obj["body"]),
+	}
+
+	if key, ok := obj["key"]; ok {
+		every.Key = d.term(key)
+	}
+
+	return every
+}
+
+func (d *decoder) not(obj map[string]any) *ast.Not {
+	return &ast.Not{
+		Location:     d.location(obj["location"]),
+		ExplicitBody: d.bool(obj["explicit_body"], "explicit_body"),
+		Body:         d.body(obj["body"]),
+	}
+}
+
+func (d *decoder) term(v any) *ast.Term {
+	obj := d.object(v, "term", false)
+
+	return &ast.Term{Location: d.location(obj["location"]), Value: d.value(obj["type"], obj["value"])}
+}
+
+func (d *decoder) terms(v any) []*ast.Term {
+	arr := d.array(v, "terms", false)
+	terms := make([]*ast.Term, 0, len(arr))
+
+	for _, t := range arr {
+		terms = append(terms, d.term(t))
+	}
+
+	return terms
+}
+
+func (d *decoder) optionalTerms(v any) []*ast.Term {
+	if v == nil {
+		return nil
+	}
+
+	return d.terms(v)
+}
+
+func (d *decoder) value(typ, v any) ast.Value {
+	if d.err != nil {
+		return ast.Null{}
+	}
+
+	switch typ {
+	case "null":
+		return ast.Null{}
+	case "boolean":
+		return ast.Boolean(d.bool(v, "boolean"))
+	case "number":
+		if n, ok := v.(json.Number); ok {
+			return ast.Number(n)
+		}
+
+		d.fail("number", v)
+	case "string":
+		return ast.String(d.string(v, "string"))
+	case "var":
+		return ast.Var(d.string(v, "var"))
+	case "ref":
+		return ast.Ref(d.terms(v))
+	case "array":
+		return ast.NewArray(d.terms(v)...)
+	case "set":
+		return ast.NewSet(d.terms(v)...)
+	case "call":
+		return ast.Call(d.terms(v))
+	case "object":
+		arr := d.array(v, "object", false)
+		items := make([][2]*ast.Term, 0, len(arr))
+
+		for _, item := range arr {
+			if pair := d.terms(item); len(pair) == 2 {
+				items = append(items, ast.Item(pair[0], pair[1]))
+			} else {
+				d.fail("object item", item)
+			}
+		}
+
+		return ast.NewObject(items...)
+	case "arraycomprehension":
+		obj := d.object(v, "array comprehension", false)
+
+		return &ast.ArrayComprehension{Term: d.term(obj["term"]), Body: d.body(obj["body"])}
+	case "setcomprehension":
+		obj := d.object(v, "set comprehension", false)
+
+		return &ast.SetComprehension{Term: d.term(obj["term"]), Body: d.body(obj["body"])}
+	case "objectcomprehension":
+		obj := d.object(v, "object comprehension", false)
+
+		return &ast.ObjectComprehension{Key: d.term(obj["key"]), Value: d.term(obj["value"]), Body: d.body(obj["body"])}
+	case "templatestring":
+		obj := d.object(v, "template string", false)
+		ts := &ast.TemplateString{MultiLine: d.bool(obj["multi_line"], "multi_line")}
+
+		for _, part := range d.array(obj["parts"], "template string parts", false) {
+			if p, ok := part.(map[string]any); ok && p["interpolated"] == true {
+				ts.Parts = append(ts.Parts, d.expr(p))
+			} else {
+				ts.Parts = append(ts.Parts, d.term(part))
+			}
+		}
+
+		return ts
+	case "not":
+		return d.not(d.object(v, "not", false))
+	default:
+		d.fail("term type", typ)
+	}
+
+	return ast.Null{}
+}
+
+func (d *decoder) annotations(v any) []*ast.Annotations {
+	arr := d.array(v, "annotations", true)
+	if len(arr) == 0 {
+		return nil
+	}
+
+	annotations := make([]*ast.Annotations, 0, len(arr))
+
+	for _, a := range arr {
+		obj := d.object(a, "annotation", false)
+		annotation := &ast.Annotations{
+			Location:    d.location(obj["location"]),
+			Scope:       d.string(obj["scope"], "scope"),
+			Title:       d.optionalString(obj["title"], "title"),
+			Description: d.optionalString(obj["description"], "description"),
+			Entrypoint:  d.bool(obj["entrypoint"], "entrypoint"),
+		}
+
+		for _, org := range d.array(obj["organizations"], "organizations", true) {
+			annotation.Organizations = append(annotation.Organizations, d.string(org, "organization"))
+		}
+
+		for _, r := range d.array(obj["related_resources"], "related_resources", true) {
+			rr := d.object(r, "related resource", false)
+
+			ref, err := url.Parse(d.string(rr["ref"], "related resource ref"))
+			if err != nil {
+				d.fail("related resource ref", rr["ref"])
+
+				continue
+			}
+
+			annotation.RelatedResources = append(annotation.RelatedResources, &ast.RelatedResourceAnnotation{
+				Ref:         *ref,
+				Description: d.optionalString(rr["description"], "related resource description"),
+			})
+		}
+
+		for _, a := range d.array(obj["authors"], "authors", true) {
+			author := d.object(a, "author", false)
+			annotation.Authors = append(annotation.Authors, &ast.AuthorAnnotation{
+				Name:  d.optionalString(author["name"], "author name"),
+				Email: d.optionalString(author["email"], "author email"),
+			})
+		}
+
+		for _, s := range d.array(obj["schemas"], "schemas", true) {
+			schema := d.object(s, "schema", false)
+			sa := &ast.SchemaAnnotation{Path: d.schemaRef(schema["path"])}
+
+			if ref, ok := schema["schema"]; ok {
+				sa.Schema = d.schemaRef(ref)
+			}
+
+			if def, ok := schema["definition"]; ok {
+				definition := normalizeNumbers(def)
+				sa.Definition = &definition
+			}
+
+			annotation.Schemas = append(annotation.Schemas, sa)
+		}
+
+		if custom, ok := obj["custom"]; ok {
+			annotation.Custom, _ = normalizeNumbers(d.object(custom, "custom", false)).(map[string]any)
+		}
+
+		annotations = append(annotations, annotation)
+	}
+
+	return annotations
+}
+
+// schemaRef decodes the ref of a schema annotation, which is either an array of terms, or as in the
+// linter input, an array of strings where the first element is the name of the root document.
+func (d *decoder) schemaRef(v any) ast.Ref {
+	arr := d.array(v, "schema ref", false)
+	ref := make(ast.Ref, 0, len(arr))
+
+	for i, x := range arr {
+		switch {
+		case i == 0 && isString(x):
+			ref = append(ref, ast.VarTerm(x.(string)))
+		case isString(x):
+			ref = append(ref, ast.StringTerm(x.(string)))
+		default:
+			ref = append(ref, d.term(x))
+		}
+	}
+
+	return ref
+}
+
+// location parses a RoAST location string, and uses the source lines to restore the text and offset of
+// the location, which the formatter relies on. Without source lines, no locations are decoded.
+func (d *decoder) location(v any) *ast.Location {
+	if d.err != nil || d.content == nil || v == nil {
+		return nil
+	}
+
+	s := d.string(v, "location")
+	parts := strings.Split(s, ":")
+
+	if len(parts) != 4 {
+		d.fail("location", v)
+
+		return nil
+	}
+
+	nums := make([]int, 4)
+
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			d.fail("location", v)
+
+			return nil
+		}
+
+		nums[i] = n
+	}
+
+	start, end := d.offset(nums[0], nums[1]), d.offset(nums[2], nums[3])
+	if start < 0 || end < start || end > len(d.content) {
+		d.fail("location", v)
+
+		return nil
+	}
+
+	return &ast.Location{Text: d.content[start:end], File: d.file, Row: nums[0], Col: nums[1], Offset: start}
+}
+
+func (d *decoder) offset(row, col int) int {
+	// column 0 is allowed, as the parser reports that for some parts of multi-line template strings
+	if row < 1 || row > len(d.offsets) || col < 0 {
+		return -1
+	}
+
+	return max(0, d.offsets[row-1]+col-1)
+}
+
+func (d *decoder) object(v any, name string, optional bool) map[string]any {
+	if obj, ok := v.(map[string]any); ok || (optional && v == nil) {
+		return obj
+	}
+
+	d.fail(name, v)
+
+	return nil
+}
+
+func (d *decoder) array(v any, name string, optional bool) []any {
+	if arr, ok := v.([]any); ok || (optional && v == nil) {
+		return arr
+	}
+
+	d.fail(name, v)
+
+	return nil
+}
+
+func (d *decoder) string(v any, name string) string {
+	s, ok := v.(string)
+	if !ok {
+		d.fail(name, v)
+	}
+
+	return s
+}
+
+func (d *decoder) optionalString(v any, name string) string {
+	if v == nil {
+		return ""
+	}
+
+	return d.string(v, name)
+}
+
+func (d *decoder) bool(v any, name string) bool {
+	b, ok := v.(bool)
+	if !ok && v != nil {
+		d.fail(name, v)
+	}
+
+	return b
+}
+
+func isString(v any) bool {
+	_, ok := v.(string)
+
+	return ok
+}
+
+func (d *decoder) fail(name string, v any) {
+	if d.err == nil {
+		d.err = fmt.Errorf("invalid %s in RoAST: %v", name, v)
+	}
+}
+
+// normalizeNumbers converts the json.Number values of v, as used when decoding terms, to int64 or float64,
+// as expected in annotation values.
+func normalizeNumbers(v any) any {
+	switch x := v.(type) {
+	case json.Number:
+		if i, err := x.Int64(); err == nil {
+			return i
+		}
+
+		f, _ := x.Float64()
+
+		return f
+	case map[string]any:
+		for k, val := range x {
+			x[k] = normalizeNumbers(val)
+		}
+	case []any:
+		for i, val := range x {
+			x[i] = normalizeNumbers(val)
+		}
+	}
+
+	return v
+}
+
+// metadata is the YAML representation of annotations, in the order attributes are commonly written.
+type metadata struct {
+	Scope            string              `yaml:"scope,omitempty"`
+	Title            string              `yaml:"title,omitempty"`
+	Description      string              `yaml:"description,omitempty"`
+	RelatedResources []map[string]string `yaml:"related_resources,omitempty"`
+	Authors          []map[string]string `yaml:"authors,omitempty"`
+	Organizations    []string            `yaml:"organizations,omitempty"`
+	Schemas          []map[string]any    `yaml:"schemas,omitempty"`
+	Entrypoint       bool                `yaml:"entrypoint,omitempty"`
+	Custom           map[string]any      `yaml:"custom,omitempty"`
+}
+
+// insertMetadataComments adds METADATA comments for the annotations of mod to the formatted source,
+// above the package and rules they belong to. The source is parsed again to find the rows to insert
+// comments at, which is the only way to know where the formatter put them.
+func insertMetadataComments(src []byte, mod *ast.Module) ([]byte, error) {
+	formatted, err := ast.ParseModuleWithOpts("", string(src), ast.ParserOptions{
+		RegoVersion:  mod.RegoVersion(),
+		Capabilities: ast.CapabilitiesForThisVersion(ast.CapabilitiesExperimentalKeywords(true)),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse formatted module: %w", err)
+	}
+
+	if len(formatted.Rules) != len(mod.Rules) {
+		return nil, errors.New("failed to insert metadata comments: rules changed by formatting")
+	}
+
+	// rows are 1-indexed, and metadata comments are inserted before the row of the node
+	comments := make(map[int][]string)
+
+	for _, a := range mod.Annotations {
+		if a.Scope == "package" || a.Scope == "subpackages" {
+			if comments[formatted.Package.Location.Row], err = appendMetadataComment(
+				comments[formatted.Package.Location.Row], a, "package",
+			); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i, rule := range mod.Rules {
+		row := formatted.Rules[i].Location.Row
+
+		for _, a := range rule.Annotations {
+			// document scoped annotations are attached to each rule of the document, but written only once
+			if !slices.Contains(mod.Annotations, a) {
+				continue
+			}
+
+			if comments[row], err = appendMetadataComment(comments[row], a, "rule"); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	lines := strings.Split(string(src), "\n")
+	rows := make([]int, 0, len(comments))
+
+	for row := range comments {
+		rows = append(rows, row)
+	}
+
+	// insert from the bottom up, so that the rows of the nodes above remain valid
+	slices.Sort(rows)
+	slices.Reverse(rows)
+
+	for _, row := range rows {
+		lines = slices.Insert(lines, row-1, comments[row]...)
+	}
+
+	return []byte(strings.Join(lines, "\n")), nil
+}
+
+func appendMetadataComment(lines []string, a *ast.Annotations, defaultScope string) ([]string, error) {
+	md := metadata{
+		Title:         a.Title,
+		Description:   a.Description,
+		Organizations: a.Organizations,
+		Entrypoint:    a.Entrypoint,
+		Custom:        a.Custom,
+	}
+
+	if a.Scope != defaultScope {
+		md.Scope = a.Scope
+	}
+
+	for _, rr := range a.RelatedResources {
+		resource := map[string]string{"ref": rr.Ref.String()}
+		if rr.Description != "" {
+			resource["description"] = rr.Description
+		}
+
+		md.RelatedResources = append(md.RelatedResources, resource)
+	}
+
+	for _, author := range a.Authors {
+		entry := make(map[string]string, 2)
+		if author.Name != "" {
+			entry["name"] = author.Name
+		}
+
+		if author.Email != "" {
+			entry["email"] = author.Email
+		}
+
+		md.Authors = append(md.Authors, entry)
+	}
+
+	for _, schema := range a.Schemas {
+		if schema.Definition != nil {
+			md.Schemas = append(md.Schemas, map[string]any{schema.Path.String(): *schema.Definition})
+		} else {
+			md.Schemas = append(md.Schemas, map[string]any{schema.Path.String(): schema.Schema.String()})
+		}
+	}
+
+	var buf bytes.Buffer
+
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	if err := enc.Encode(md); err != nil {
+		return nil, fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	lines = append(lines, "# METADATA")
+
+	for line := range strings.SplitSeq(strings.TrimSuffix(buf.String(), "\n"), "\n") {
+		lines = append(lines, strings.TrimSuffix("# "+line, " "))
+	}
+
+	return lines, nil
+}
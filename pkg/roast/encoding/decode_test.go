@@ -0,0 +1,211 @@
+package encoding
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/format"
+
+	"github.com/open-policy-agent/regal/internal/test/must"
+	"github.com/open-policy-agent/regal/pkg/roast/transform"
+)
+
+func TestUnparseRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	paths := []string{
+		"../../../internal/roast/encoding/testdata/policy.rego",
+		"../../../internal/roast/transforms/testdata/ast.rego",
+	}
+
+	// the Regal bundle makes for a good variety of real world policies
+	must.Equal(t, nil, filepath.WalkDir("../../../bundle", func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() && strings.HasSuffix(path, ".rego") {
+			paths = append(paths, path)
+		}
+
+		return err
+	}))
+
+	for _, path := range paths {
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+
+			content := string(must.Return(os.ReadFile(path))(t))
+			module, expected, roast := formatAndEncode(t, path, content)
+
+			if decoded := must.Return(DecodeModule(roast))(t); module.Compare(decoded) != 0 {
+				t.Errorf("expected decoded module to equal:\n%s\ngot:\n%s", module, decoded)
+			}
+
+			if unparsed := must.Return(Unparse(roast))(t); !bytes.Equal(expected, unparsed) {
+				t.Errorf("expected:\n%s\ngot:\n%s", expected, unparsed)
+			}
+
+			// without source lines and locations, comments are lost, but the module should be the same
+			unparsed := must.Return(Unparse(withoutSourceLines(t, roast)))(t)
+			reparsed := must.Return(ast.ParseModuleWithOpts(path, string(unparsed), parserOptions))(t)
+
+			// imports are sorted by the formatter when there are no locations to tell their order
+			slices.SortFunc(reparsed.Imports, (*ast.Import).Compare)
+			slices.SortFunc(module.Imports, (*ast.Import).Compare)
+
+			if module.Compare(reparsed) != 0 {
+				t.Errorf("expected module without source lines to equal:\n%s\ngot:\n%s", module, reparsed)
+			}
+		})
+	}
+}
+
+func TestUnparseWithoutSourceLines(t *testing.T) {
+	t.Parallel()
+
+	policy := `# METADATA
+# title: Policy
+# authors:
+# - name: Jane Doe
+#   email: jane@example.com
+package policy
+
+# comment, which is lost without source lines
+allow if input.admin
+
+# METADATA
+# description: Deny all
+# custom:
+#   severity: 2
+deny contains "denied" if {
+	not allow
+}
+`
+	_, _, roast := formatAndEncode(t, "policy.rego", policy)
+
+	expected := `# METADATA
+# title: Policy
+# authors:
+#   - email: jane@example.com
+#     name: Jane Doe
+package policy
+
+allow if input.admin
+
+# METADATA
+# description: Deny all
+# custom:
+#   severity: 2
+deny contains "denied" if not allow
+`
+	if unparsed := string(must.Return(Unparse(withoutSourceLines(t, roast)))(t)); unparsed != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, unparsed)
+	}
+}
+
+func TestDecodeModuleAnnotations(t *testing.T) {
+	t.Parallel()
+
+	_, _, roast := formatAndEncode(t, "policy.rego", `package policy
+
+# METADATA
+# title: Allow
+# entrypoint: true
+# related_resources:
+# - ref: https://example.com
+#   description: docs
+# schemas:
+# - input: {"type": "object"}
+allow if input.admin
+`)
+
+	mod := must.Return(DecodeModule(roast))(t)
+
+	must.Equal(t, 1, len(mod.Rules[0].Annotations), "number of rule annotations")
+	must.Equal(t, 1, len(mod.Annotations), "number of module annotations")
+
+	annotation := mod.Rules[0].Annotations[0]
+
+	must.Equal(t, "Allow", annotation.Title)
+	must.Equal(t, true, annotation.Entrypoint)
+	must.Equal(t, "https://example.com", annotation.RelatedResources[0].Ref.String())
+	must.Equal(t, "input", annotation.Schemas[0].Path.String())
+	must.Equal(t, "map[type:object]", fmt.Sprint(*annotation.Schemas[0].Definition))
+}
+
+func TestDecodeModuleInvalid(t *testing.T) {
+	t.Parallel()
+
+	cases := map[string]string{
+		"not json":          `package p`,
+		"missing package":   `{"rules": []}`,
+		"invalid term type": `{"package": {"path": [{"type": "unknown", "value": "data"}]}}`,
+		"invalid location": `{"package": {"location": "1:1", "path": [{"type": "var", "value": "data"}]},
+			"regal": {"file": {"lines": ["package p"]}}}`,
+		"location out of bounds": `{"package": {"location": "2:1:2:10", "path": [{"type": "var", "value": "data"}]},
+			"regal": {"file": {"lines": ["package p"]}}}`,
+	}
+
+	for name, input := range cases {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := DecodeModule([]byte(input)); err == nil {
+				t.Errorf("expected error decoding %s", input)
+			}
+		})
+	}
+}
+
+var parserOptions = ast.ParserOptions{ProcessAnnotation: true}
+
+// formatAndEncode returns the parsed and formatted policy, and the RoAST of the policy as JSON.
+func formatAndEncode(t *testing.T, name, content string) (*ast.Module, []byte, []byte) {
+	t.Helper()
+
+	module := must.Return(ast.ParseModuleWithOpts(name, content, parserOptions))(t)
+
+	// formatting must be done first, as the encoder strips locations from generated head values
+	formatted := must.Return(format.AstWithOpts(module, format.Opts{RegoVersion: module.RegoVersion()}))(t)
+	value := must.Return(transform.ToAST(name, content, module, false))(t)
+
+	var buf bytes.Buffer
+
+	must.Equal(t, nil, OfValue().Encode(&buf, value))
+
+	return module, formatted, buf.Bytes()
+}
+
+// withoutSourceLines returns the RoAST without the regal attribute, and without locations, like RoAST
+// generated by tools rather than from source.
+func withoutSourceLines(t *testing.T, roast []byte) []byte {
+	t.Helper()
+
+	var doc map[string]any
+
+	must.Equal(t, nil, SafeNumberConfig.Unmarshal(roast, &doc))
+	delete(doc, "regal")
+
+	return must.Return(SafeNumberConfig.Marshal(stripLocations(doc)))(t)
+}
+
+func stripLocations(v any) any {
+	switch x := v.(type) {
+	case map[string]any:
+		delete(x, "location")
+
+		for _, val := range x {
+			stripLocations(val)
+		}
+	case []any:
+		for _, val := range x {
+			stripLocations(val)
+		}
+	}
+
+	return v
+}
@@ -24,6 +24,12 @@ func IsBodyGenerated(rule *ast.Rule) bool {
 		return true
 	}
 
+	// a generated body is always a single `true` expression, while the parser uses the location of the
+	// rule for the body of e.g. `else if x` too, so the location alone can't tell them apart
+	if term, ok := rule.Body[0].Terms.(*ast.Term); !ok || len(rule.Body) > 1 || !ast.InternedTerm(true).Equal(term) {
+		return false
+	}
+
 	if rule.Head != nil && rule.Body[0] != nil {
 		bodyStart := rule.Body[0].Location
 		if bodyStart == rule.Location || rule.Head.Value != nil && bodyStart == rule.Head.Value.Location {
@@ -166,6 +166,44 @@ func TestRefStringToBody(t *testing.T) {
 	}
 }
 
+func TestIsBodyGenerated(t *testing.T) {
+	t.Parallel()
+
+	module := ast.MustParseModule(`package p
+
+default a := 1
+
+b := 1
+
+c if input.c
+
+d := 1 if {
+	input.x
+} else := 2 if input.y
+
+e := 1 if {
+	input.x
+} else := 2
+`)
+
+	exp := map[string][]bool{
+		"a": {true},
+		"b": {true},
+		"c": {false},
+		"d": {false, false},
+		"e": {false, true},
+	}
+
+	for _, rule := range module.Rules {
+		var got []bool
+		for r := rule; r != nil; r = r.Else {
+			got = append(got, rast.IsBodyGenerated(r))
+		}
+
+		assert.SlicesEqual(t, exp[rule.Head.Name.String()], got, rule.Head.Name.String())
+	}
+}
+
 // BenchmarkAppendLocation/single_line_no_prealloc-16         34704147        34.05 ns/op       8 B/op       1 allocs/op
 // BenchmarkAppendLocation/multi_line_no_prealloc-16          29631702        39.94 ns/op      16 B/op       1 allocs/op
 // BenchmarkAppendLocation/single_line_with_prealloc-16       41071040        27.80 ns/op       0 B/op       0 allocs/op
@@ -0,0 +1,293 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/open-policy-agent/regal/internal/parse"
+	"github.com/open-policy-agent/regal/pkg/roast/rast"
+)
+
+// Bundle represents a bundle found among the policies being linted, i.e. a directory containing
+// a .manifest file, or a project root from the configuration.
+type Bundle struct {
+	// Path is the root directory of the bundle.
+	Path string
+	// Manifest is the .manifest file of the bundle, or nil if the bundle has none.
+	Manifest *Manifest
+	// Files are the policy files for which this is the closest bundle root.
+	Files []BundleFile
+}
+
+// BundleFile is a policy file in a bundle.
+type BundleFile struct {
+	// Name is the name of the file, as provided for linting.
+	Name string
+	// RegoVersion is the entry in the manifest declaring the Rego version of the file, if any.
+	RegoVersion *ManifestEntry
+	// Parses is true when the file can be parsed using the Rego version declared in the manifest.
+	Parses bool
+}
+
+// Manifest represents the parts of a .manifest file relevant for linting, along with
+// the location of each entry in the file.
+type Manifest struct {
+	// Name is the path of the file.
+	Name string
+	// Roots are the roots declared in the manifest, or nil if none were declared.
+	Roots []ManifestEntry
+	// RegoVersion is the rego_version entry of the manifest, if any.
+	RegoVersion *ManifestEntry
+	// FileRegoVersions are the entries of the file_rego_versions object, sorted by pattern.
+	FileRegoVersions []ManifestEntry
+	// Wasm are the wasm module resolvers, where the key is the module and the value the entrypoint.
+	Wasm []ManifestEntry
+	// lines of the file, used to provide text for locations.
+	lines []string
+}
+
+// ManifestEntry is an entry in a .manifest file, and its location.
+type ManifestEntry struct {
+	Key    string
+	Value  string
+	Row    int
+	Col    int
+	EndCol int
+}
+
+// ManifestFromPath reads and parses the .manifest file at path.
+func ManifestFromPath(path string) (*Manifest, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return ManifestFromContent(path, util.ByteSliceToString(bs))
+}
+
+// ManifestFromContent parses the contents of a .manifest file.
+func ManifestFromContent(name, content string) (*Manifest, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(util.StringToByteSlice(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest %s: %w", name, err)
+	}
+
+	m := &Manifest{Name: name, lines: strings.Split(content, "\n")}
+
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return m, nil
+	}
+
+	root := doc.Content[0]
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		keyNode, valueNode := root.Content[i], root.Content[i+1]
+
+		switch keyNode.Value {
+		case "roots":
+			m.Roots = []ManifestEntry{}
+
+			for _, node := range valueNode.Content {
+				// roots are normalized by OPA to have no leading or trailing slashes
+				m.Roots = append(m.Roots, entry("", strings.Trim(node.Value, "/"), node))
+			}
+		case "rego_version":
+			e := entry(keyNode.Value, valueNode.Value, keyNode)
+			m.RegoVersion = &e
+		case "file_rego_versions":
+			for j := 0; j+1 < len(valueNode.Content); j += 2 {
+				m.FileRegoVersions = append(
+					m.FileRegoVersions,
+					entry(valueNode.Content[j].Value, valueNode.Content[j+1].Value, valueNode.Content[j]),
+				)
+			}
+		case "wasm":
+			for _, resolver := range valueNode.Content {
+				if e, ok := wasmEntry(resolver); ok {
+					m.Wasm = append(m.Wasm, e)
+				}
+			}
+		}
+	}
+
+	// OPA uses the first matching pattern in sorted order when patterns overlap
+	slices.SortFunc(m.FileRegoVersions, func(a, b ManifestEntry) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	return m, nil
+}
+
+// RegoVersionForFile returns the entry declaring the Rego version of the file at path, relative
+// to the bundle root, or nil if the manifest doesn't declare a version for the file.
+func (m *Manifest) RegoVersionForFile(path string) *ManifestEntry {
+	path = "/" + strings.TrimPrefix(filepath.ToSlash(path), "/")
+
+	for i, fv := range m.FileRegoVersions {
+		if g, err := glob.Compile(fv.Key); err == nil && g.Match(path) {
+			return &m.FileRegoVersions[i]
+		}
+	}
+
+	return m.RegoVersion
+}
+
+// NewBundleFile creates a BundleFile for the policy file with the given name, located at path relative
+// to the bundle root. Whether the file parses using the Rego version declared for it in the manifest is
+// determined by CheckRegoVersion, once the file has been parsed for linting.
+func NewBundleFile(name, path string, manifest *Manifest) BundleFile {
+	bf := BundleFile{Name: name}
+
+	if manifest != nil {
+		bf.RegoVersion = manifest.RegoVersionForFile(path)
+	}
+
+	return bf
+}
+
+// CheckRegoVersion determines whether the file, parsed as module from content, can be parsed using the
+// Rego version declared for it in the manifest, if any. The module is only parsed again when it was
+// parsed using another version than the one declared.
+func (bf *BundleFile) CheckRegoVersion(module *ast.Module, content string) {
+	if bf.RegoVersion == nil {
+		return
+	}
+
+	version, err := strconv.Atoi(bf.RegoVersion.Value)
+	if err != nil {
+		return
+	}
+
+	if module != nil && module.RegoVersion() == ast.RegoVersionFromInt(version) {
+		bf.Parses = true
+
+		return
+	}
+
+	opts := parse.ParserOptions()
+	opts.RegoVersion = ast.RegoVersionFromInt(version)
+
+	_, err = ast.ParseModuleWithOpts(bf.Name, content, opts)
+	bf.Parses = err == nil
+}
+
+// ToValue converts the bundle to the value provided to aggregate rules.
+func (b *Bundle) ToValue() ast.Value {
+	files := ast.NewObject()
+
+	for _, file := range b.Files {
+		value := ast.NewObject()
+
+		if file.RegoVersion != nil {
+			version, _ := strconv.Atoi(file.RegoVersion.Value)
+
+			value.Insert(ast.InternedTerm("rego_version"), ast.ObjectTerm(
+				rast.Item("value", ast.InternedTerm(version)),
+				rast.Item("parses", ast.InternedTerm(file.Parses)),
+				rast.Item("location", b.Manifest.location(*file.RegoVersion)),
+			))
+		}
+
+		files.Insert(ast.StringTerm(file.Name), ast.NewTerm(value))
+	}
+
+	value := ast.NewObject(
+		rast.Item("path", ast.StringTerm(b.Path)),
+		rast.Item("files", ast.NewTerm(files)),
+	)
+
+	if b.Manifest != nil {
+		value.Insert(ast.InternedTerm("manifest"), ast.NewTerm(b.Manifest.toValue()))
+	}
+
+	return value
+}
+
+func (m *Manifest) toValue() ast.Object {
+	obj := ast.NewObject(rast.Item("file", ast.StringTerm(m.Name)))
+
+	if m.Roots != nil {
+		roots := make([]*ast.Term, 0, len(m.Roots))
+		for _, root := range m.Roots {
+			roots = append(roots, ast.ObjectTerm(
+				rast.Item("value", ast.StringTerm(root.Value)),
+				rast.Item("location", m.location(root)),
+			))
+		}
+
+		obj.Insert(ast.InternedTerm("roots"), ast.ArrayTerm(roots...))
+	}
+
+	wasm := make([]*ast.Term, 0, len(m.Wasm))
+	for _, resolver := range m.Wasm {
+		wasm = append(wasm, ast.ObjectTerm(
+			rast.Item("module", ast.StringTerm(resolver.Key)),
+			rast.Item("entrypoint", ast.StringTerm(resolver.Value)),
+			rast.Item("location", m.location(resolver)),
+		))
+	}
+
+	obj.Insert(ast.InternedTerm("wasm"), ast.ArrayTerm(wasm...))
+
+	return obj
+}
+
+func (m *Manifest) location(e ManifestEntry) *ast.Term {
+	var text string
+	if e.Row > 0 && e.Row <= len(m.lines) {
+		text = m.lines[e.Row-1]
+	}
+
+	return ast.ObjectTerm(
+		rast.Item("file", ast.StringTerm(m.Name)),
+		rast.Item("row", ast.InternedTerm(e.Row)),
+		rast.Item("col", ast.InternedTerm(e.Col)),
+		rast.Item("text", ast.StringTerm(text)),
+		rast.Item("end", ast.ObjectTerm(
+			rast.Item("row", ast.InternedTerm(e.Row)),
+			rast.Item("col", ast.InternedTerm(e.EndCol)),
+		)),
+	)
+}
+
+func entry(key, value string, node *yaml.Node) ManifestEntry {
+	return ManifestEntry{
+		Key:    key,
+		Value:  value,
+		Row:    node.Line,
+		Col:    node.Column,
+		EndCol: node.Column + keyLength(node),
+	}
+}
+
+// wasmEntry returns the entry for a wasm resolver, located at its entrypoint.
+func wasmEntry(resolver *yaml.Node) (ManifestEntry, bool) {
+	var module string
+
+	var entrypoint *yaml.Node
+
+	for i := 0; i+1 < len(resolver.Content); i += 2 {
+		switch resolver.Content[i].Value {
+		case "module":
+			module = resolver.Content[i+1].Value
+		case "entrypoint":
+			entrypoint = resolver.Content[i+1]
+		}
+	}
+
+	if entrypoint == nil {
+		return ManifestEntry{}, false
+	}
+
+	return entry(module, strings.Trim(entrypoint.Value, "/"), entrypoint), true
+}
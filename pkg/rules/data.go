@@ -0,0 +1,269 @@
+package rules
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/util"
+
+	"github.com/open-policy-agent/regal/pkg/roast/rast"
+)
+
+// DataFileNames are the names of files loaded as data documents, following the conventions of OPA bundles.
+var DataFileNames = []string{"data.json", "data.yaml", "data.yml"}
+
+// DataFile represents a JSON or YAML data file found alongside the policies being linted.
+type DataFile struct {
+	// Name is the path of the file.
+	Name string
+	// Path is the path where the document of the file is mounted under data, as determined
+	// by the directory of the file relative to its bundle root.
+	Path []string
+	// Keys contains every object key in the document, except for those found inside arrays.
+	Keys []DataKey
+	// Duplicates contains any keys that are declared more than once in the same object.
+	Duplicates []DataKey
+	// ParseError is set if the file couldn't be parsed, in which case it has no keys.
+	ParseError *DataParseError
+	// lines of the file, used to provide text for locations.
+	lines []string
+}
+
+// DataParseError is the error encountered parsing a data file.
+type DataParseError struct {
+	Message string
+	// Row is the line the error was found on, or 0 if not known.
+	Row int
+}
+
+// DataKey is the location of an object key in a data file.
+type DataKey struct {
+	// Path is the full path of the key, starting from data.
+	Path []string
+	// Row and Col point to the start of the key in the file.
+	Row int
+	Col int
+	// EndCol points to the end of the key.
+	EndCol int
+	// Leaf is true when the value of the key isn't an object.
+	Leaf bool
+}
+
+// DataFileFromPath reads and parses the data file at path. The document of the file is mounted under
+// data at the path of its directory relative to the closest of the provided (absolute) bundle roots, or
+// at the root of data if the file isn't found under any of them.
+func DataFileFromPath(path string, roots []string) (*DataFile, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	dir, err := filepath.Abs(filepath.Dir(path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path for %s: %w", path, err)
+	}
+
+	var mount []string
+
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, dir)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+
+		// the closest root is the one leaving the shortest path to mount at
+		if parts := mountPath(rel); mount == nil || len(parts) < len(mount) {
+			mount = parts
+		}
+	}
+
+	return DataFileFromContent(path, util.ByteSliceToString(bs), mount), nil
+}
+
+// DataFileFromContent parses the contents of a JSON or YAML data file, mounted under data at mount.
+// Content that can't be parsed is reported by the ParseError of the data file.
+func DataFileFromContent(name, content string, mount []string) *DataFile {
+	df := &DataFile{
+		Name:  name,
+		Path:  mount,
+		lines: strings.Split(content, "\n"),
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(util.StringToByteSlice(content), &doc); err != nil {
+		df.ParseError = newDataParseError(err)
+
+		return df
+	}
+
+	if len(doc.Content) > 0 {
+		df.collect(append([]string{"data"}, mount...), doc.Content[0])
+	}
+
+	return df
+}
+
+// collect adds the keys of the mapping node to the data file, and recursively those of any
+// mappings nested under them.
+func (df *DataFile) collect(path []string, node *yaml.Node) {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+
+	seen := make(map[string]struct{}, len(node.Content)/2)
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		keyNode, valueNode := node.Content[i], node.Content[i+1]
+
+		key := DataKey{
+			Path:   append(slices.Clip(path), keyNode.Value),
+			Row:    keyNode.Line,
+			Col:    keyNode.Column,
+			EndCol: keyNode.Column + keyLength(keyNode),
+			Leaf:   valueNode.Kind != yaml.MappingNode && valueNode.Kind != yaml.AliasNode,
+		}
+
+		if _, ok := seen[keyNode.Value]; ok {
+			df.Duplicates = append(df.Duplicates, key)
+
+			continue
+		}
+
+		seen[keyNode.Value] = struct{}{}
+
+		df.Keys = append(df.Keys, key)
+		df.collect(key.Path, valueNode)
+	}
+}
+
+// ToValue converts the data file to the value provided to aggregate rules. Besides the keys,
+// this includes a tree of all keys under data, where any value that isn't an object is an
+// empty object, mirroring the tree of rules built from policies.
+func (df *DataFile) ToValue() ast.Value {
+	tree := ast.NewObject()
+
+	for _, key := range df.Keys {
+		insertPath(tree, key.Path)
+	}
+
+	// documents without keys, like arrays, are leaves at the path they're mounted at
+	if len(df.Keys) == 0 && len(df.Path) > 0 {
+		insertPath(tree, append([]string{"data"}, df.Path...))
+	}
+
+	obj := ast.NewObject(
+		rast.Item("path", rast.ArrayTerm(df.Path)),
+		rast.Item("tree", ast.NewTerm(tree)),
+		rast.Item("keys", ast.ArrayTerm(df.keyTerms(df.Keys)...)),
+		rast.Item("duplicates", ast.ArrayTerm(df.keyTerms(df.Duplicates)...)),
+	)
+
+	if df.ParseError != nil {
+		// errors without a known line are reported at the start of the file
+		row := max(df.ParseError.Row, 1)
+
+		obj.Insert(ast.InternedTerm("error"), ast.ObjectTerm(
+			rast.Item("message", ast.StringTerm(df.ParseError.Message)),
+			rast.Item("location", df.locationTerm(row, 1, len(df.line(row))+1)),
+		))
+	}
+
+	return obj
+}
+
+func (df *DataFile) keyTerms(keys []DataKey) []*ast.Term {
+	terms := make([]*ast.Term, 0, len(keys))
+
+	for _, key := range keys {
+		terms = append(terms, ast.ObjectTerm(
+			rast.Item("path", rast.ArrayTerm(key.Path)),
+			rast.Item("leaf", ast.InternedTerm(key.Leaf)),
+			rast.Item("location", df.locationTerm(key.Row, key.Col, key.EndCol)),
+		))
+	}
+
+	return terms
+}
+
+// locationTerm returns the location of a range on a single line of the file.
+func (df *DataFile) locationTerm(row, col, endCol int) *ast.Term {
+	return ast.ObjectTerm(
+		rast.Item("file", ast.StringTerm(df.Name)),
+		rast.Item("row", ast.InternedTerm(row)),
+		rast.Item("col", ast.InternedTerm(col)),
+		rast.Item("text", ast.StringTerm(df.line(row))),
+		rast.Item("end", ast.ObjectTerm(
+			rast.Item("row", ast.InternedTerm(row)),
+			rast.Item("col", ast.InternedTerm(endCol)),
+		)),
+	)
+}
+
+func (df *DataFile) line(row int) string {
+	if row > 0 && row <= len(df.lines) {
+		return df.lines[row-1]
+	}
+
+	return ""
+}
+
+// yamlErrorPattern matches the errors of the YAML parser, like "yaml: line 1: did not find expected node content".
+var yamlErrorPattern = regexp.MustCompile(`^yaml: line (\d+): (.+)$`)
+
+func newDataParseError(err error) *DataParseError {
+	if m := yamlErrorPattern.FindStringSubmatch(err.Error()); m != nil {
+		row, _ := strconv.Atoi(m[1])
+
+		return &DataParseError{Message: m[2], Row: row}
+	}
+
+	return &DataParseError{Message: strings.TrimPrefix(err.Error(), "yaml: ")}
+}
+
+// keyLength returns the length of the key as written in the file, including any quotes.
+func keyLength(node *yaml.Node) int {
+	switch node.Style {
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		return len(node.Value) + 2
+	default:
+		return len(node.Value)
+	}
+}
+
+func insertPath(tree ast.Object, path []string) {
+	for _, part := range path {
+		key := ast.StringTerm(part)
+
+		var child ast.Object
+		if term := tree.Get(key); term != nil {
+			child, _ = term.Value.(ast.Object)
+		}
+
+		if child == nil {
+			child = ast.NewObject()
+			tree.Insert(key, ast.NewTerm(child))
+		}
+
+		tree = child
+	}
+}
+
+func mountPath(rel string) []string {
+	if rel = filepath.ToSlash(rel); rel == "." {
+		return []string{}
+	}
+
+	return strings.Split(rel, "/")
+}
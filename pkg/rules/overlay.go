@@ -0,0 +1,195 @@
+package rules
+
+import (
+	"io"
+	"io/fs"
+	"path"
+	"slices"
+	"strings"
+	"time"
+)
+
+// overlayFS is a read-only filesystem serving files from memory on top of a base filesystem.
+type overlayFS struct {
+	base  fs.FS
+	files map[string]string
+}
+
+type memFile struct {
+	*strings.Reader
+
+	info fileInfo
+}
+
+type dirFile struct {
+	info    fileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+type fileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+// NewOverlayFS returns a filesystem where the provided files, mapping paths to their contents, take
+// precedence over those of the base filesystem. The base may be nil for a filesystem consisting of only
+// the provided files. This is useful for linting files not yet saved to disk, like those being edited,
+// along with the rest of a project. The paths of the provided files must be valid according to
+// fs.ValidPath, and directories containing them need not exist in the base filesystem.
+func NewOverlayFS(base fs.FS, files map[string]string) fs.FS {
+	return &overlayFS{base: base, files: files}
+}
+
+func (o *overlayFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if content, ok := o.files[name]; ok {
+		return &memFile{
+			Reader: strings.NewReader(content),
+			info:   fileInfo{name: path.Base(name), size: int64(len(content))},
+		}, nil
+	}
+
+	entries, isDir := o.overlayEntries(name)
+
+	if o.base != nil {
+		f, err := o.base.Open(name)
+		if err == nil && !isDir {
+			return f, nil
+		}
+
+		if err == nil {
+			info, statErr := f.Stat()
+
+			_ = f.Close()
+
+			if statErr == nil && !info.IsDir() {
+				// a file in the base can't also be a directory in the overlay
+				return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+			}
+
+			if baseEntries, err := fs.ReadDir(o.base, name); err == nil {
+				for _, entry := range baseEntries {
+					if !slices.ContainsFunc(entries, func(e fs.DirEntry) bool { return e.Name() == entry.Name() }) {
+						entries = append(entries, entry)
+					}
+				}
+			}
+		}
+	}
+
+	if !isDir {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	slices.SortFunc(entries, func(a, b fs.DirEntry) int {
+		return strings.Compare(a.Name(), b.Name())
+	})
+
+	return &dirFile{info: fileInfo{name: path.Base(name), dir: true}, entries: entries}, nil
+}
+
+// overlayEntries returns the entries of the named directory provided by the overlay, and whether
+// the overlay contains any files under it. The root directory is always considered to exist.
+func (o *overlayFS) overlayEntries(dir string) (entries []fs.DirEntry, ok bool) {
+	prefix := ""
+	if dir != "." {
+		prefix = dir + "/"
+	}
+
+	seen := make(map[string]bool)
+
+	for name, content := range o.files {
+		rest, found := strings.CutPrefix(name, prefix)
+		if !found {
+			continue
+		}
+
+		child, _, nested := strings.Cut(rest, "/")
+		if seen[child] {
+			continue
+		}
+
+		seen[child] = true
+
+		info := fileInfo{name: child, dir: nested}
+		if !nested {
+			info.size = int64(len(content))
+		}
+
+		entries = append(entries, fs.FileInfoToDirEntry(info))
+	}
+
+	return entries, len(entries) > 0 || dir == "."
+}
+
+func (f *memFile) Stat() (fs.FileInfo, error) {
+	return f.info, nil
+}
+
+func (*memFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) Stat() (fs.FileInfo, error) {
+	return d.info, nil
+}
+
+func (d *dirFile) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (*dirFile) Close() error {
+	return nil
+}
+
+func (d *dirFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	remaining := d.entries[d.offset:]
+
+	if n <= 0 {
+		d.offset = len(d.entries)
+
+		return remaining, nil
+	}
+
+	if len(remaining) == 0 {
+		return nil, io.EOF
+	}
+
+	n = min(n, len(remaining))
+	d.offset += n
+
+	return remaining[:n], nil
+}
+
+func (fi fileInfo) Name() string {
+	return fi.name
+}
+
+func (fi fileInfo) Size() int64 {
+	return fi.size
+}
+
+func (fi fileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0o555
+	}
+
+	return 0o444
+}
+
+func (fileInfo) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (fi fileInfo) IsDir() bool {
+	return fi.dir
+}
+
+func (fileInfo) Sys() any {
+	return nil
+}
@@ -3,6 +3,7 @@ package rules
 import (
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -90,6 +91,52 @@ func InputFromPaths(paths []string, prefix string, versionsMap map[string]ast.Re
 	return NewInput(content, modules), nil
 }
 
+// InputFromFS creates a new Input from a set of file paths in the provided filesystem, which may be any
+// implementation of fs.FS, like an in-memory filesystem or an overlay of one. File names in the returned
+// input are the paths as provided, which must be valid according to fs.ValidPath. Like with InputFromPaths,
+// the versionsMap is used to determine the Rego version of each file, when provided.
+func InputFromFS(fsys fs.FS, paths []string, versionsMap map[string]ast.RegoVersion) (Input, error) {
+	numPaths := len(paths)
+
+	var wg sync.WaitGroup
+
+	wg.Add(numPaths)
+
+	errors := make([]error, numPaths)
+	parsed := make([]*regoFile, numPaths)
+
+	for i, path := range paths {
+		go func(i int, path string) {
+			opts := parse.ParserOptions()
+			opts.RegoVersion = RegoVersionFromMap(versionsMap, filepath.FromSlash("/"+path), ast.RegoUndefined)
+
+			if result, err := regoFromFS(fsys, path, opts); err != nil {
+				errors[i] = err
+			} else {
+				parsed[i] = result
+			}
+
+			wg.Done()
+		}(i, path)
+	}
+
+	wg.Wait()
+
+	if errors = rutil.Filter(errors, errNotNil); len(errors) > 0 {
+		return Input{}, fmt.Errorf("failed to parse %d module(s) — first error: %w", len(errors), errors[0])
+	}
+
+	content := make(map[string]string, numPaths)
+	modules := make(map[string]*ast.Module, numPaths)
+
+	for _, file := range parsed {
+		content[file.name] = util.ByteSliceToString(file.raw)
+		modules[file.name] = file.parsed
+	}
+
+	return NewInput(content, modules), nil
+}
+
 // InputFromMap creates a new Input from a map of file paths to their contents.
 // This function uses a vesrionsMap to determine the parser version for each
 // file before parsing the module.
@@ -164,6 +211,20 @@ func regoWithOpts(path string, opts ast.ParserOptions) (*regoFile, error) {
 	return &regoFile{name: path, raw: bs, parsed: mod}, nil
 }
 
+func regoFromFS(fsys fs.FS, path string, opts ast.ParserOptions) (*regoFile, error) {
+	bs, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, err
+	}
+
+	mod, err := parse.ModuleWithOpts(path, util.ByteSliceToString(bs), opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &regoFile{name: path, raw: bs, parsed: mod}, nil
+}
+
 func inputFromStdin() (Input, error) {
 	// Ideally, we'd just pass the reader to OPA, but as the parser materializes
 	// the input immediately anyway, there's currently no benefit to doing so.
@@ -1,14 +1,18 @@
 package rules_test
 
 import (
+	"io/fs"
 	"path/filepath"
+	"slices"
 	"testing"
+	"testing/fstest"
 
 	"github.com/open-policy-agent/opa/v1/ast"
 
 	"github.com/open-policy-agent/regal/internal/parse"
 	"github.com/open-policy-agent/regal/internal/test/assert"
 	"github.com/open-policy-agent/regal/internal/test/must"
+	"github.com/open-policy-agent/regal/internal/testutil"
 	"github.com/open-policy-agent/regal/pkg/rules"
 )
 
@@ -152,3 +156,161 @@ allow[msg] { msg := "hello" }
 	input := must.Return(rules.InputFromMap(files, versionsMap))(t)
 	must.Equal(t, 2, len(input.Modules))
 }
+
+func TestInputFromFS(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{
+		"foo/bar/main.rego": {Data: []byte("package main\n\nallow if input.admin\n")},
+		"foo/main.rego":     {Data: []byte("package main\n\nallow[msg] { msg := \"hello\" }\n")},
+	}
+
+	versionsMap := map[string]ast.RegoVersion{"foo/bar": ast.RegoV1, "foo": ast.RegoV0}
+
+	input := must.Return(rules.InputFromFS(fsys, []string{"foo/bar/main.rego", "foo/main.rego"}, versionsMap))(t)
+	assert.SlicesEqual(t, []string{"foo/bar/main.rego", "foo/main.rego"}, input.FileNames)
+	assert.Equal(t, "package main\n\nallow if input.admin\n", input.FileContent["foo/bar/main.rego"])
+
+	if _, err := rules.InputFromFS(fsys, []string{"missing.rego"}, nil); err == nil {
+		t.Fatal("expected error reading missing file")
+	}
+}
+
+func TestDataFileFromContent(t *testing.T) {
+	t.Parallel()
+
+	content := "users:\n  admins: [alice]\n  \"banned\": {}\n  admins: []\n"
+
+	df := rules.DataFileFromContent("data.yaml", content, []string{"org"})
+
+	assert.DeepEqual(t, []rules.DataKey{
+		{Path: []string{"data", "org", "users"}, Row: 1, Col: 1, EndCol: 6},
+		{Path: []string{"data", "org", "users", "admins"}, Row: 2, Col: 3, EndCol: 9, Leaf: true},
+		{Path: []string{"data", "org", "users", "banned"}, Row: 3, Col: 3, EndCol: 11},
+	}, df.Keys)
+
+	assert.DeepEqual(t, []rules.DataKey{
+		{Path: []string{"data", "org", "users", "admins"}, Row: 4, Col: 3, EndCol: 9, Leaf: true},
+	}, df.Duplicates)
+
+	tree := df.ToValue().(ast.Object).Get(ast.StringTerm("tree"))
+	assert.Equal(t, `{"data": {"org": {"users": {"admins": {}, "banned": {}}}}}`, tree.String())
+	assert.Equal(t, nil, df.ToValue().(ast.Object).Get(ast.StringTerm("error")))
+}
+
+func TestDataFileFromContentInvalid(t *testing.T) {
+	t.Parallel()
+
+	df := rules.DataFileFromContent("data.json", "{\n  \"users\": [\n}\n", nil)
+
+	assert.DeepEqual(t, &rules.DataParseError{Message: "did not find expected node content", Row: 2}, df.ParseError)
+	assert.Equal(t, 0, len(df.Keys))
+
+	expected := `{"location": {"col": 1, "end": {"col": 13, "row": 2}, "file": "data.json", "row": 2, ` +
+		`"text": "  \"users\": ["}, "message": "did not find expected node content"}`
+	assert.Equal(t, expected, df.ToValue().(ast.Object).Get(ast.StringTerm("error")).String())
+}
+
+func TestManifestFromContent(t *testing.T) {
+	t.Parallel()
+
+	content := `{
+  "roots": ["authz/", "users"],
+  "rego_version": 1,
+  "file_rego_versions": {"/legacy/*.rego": 0},
+  "wasm": [{"entrypoint": "authz/allow", "module": "/policy.wasm"}]
+}`
+
+	m := must.Return(rules.ManifestFromContent(".manifest", content))(t)
+
+	assert.DeepEqual(t, []rules.ManifestEntry{
+		{Value: "authz", Row: 2, Col: 13, EndCol: 21},
+		{Value: "users", Row: 2, Col: 23, EndCol: 30},
+	}, m.Roots)
+
+	assert.DeepEqual(t, []rules.ManifestEntry{
+		{Key: "/policy.wasm", Value: "authz/allow", Row: 5, Col: 27, EndCol: 40},
+	}, m.Wasm)
+
+	assert.Equal(t, "0", m.RegoVersionForFile("legacy/policy.rego").Value)
+	assert.Equal(t, "1", m.RegoVersionForFile("policy.rego").Value)
+
+	policy := "package p\n\nallow if true\n"
+	module := must.Return(parse.ModuleWithOpts("legacy/policy.rego", policy, parse.ParserOptions()))(t)
+
+	v0 := rules.NewBundleFile("legacy/policy.rego", "legacy/policy.rego", m)
+	v0.CheckRegoVersion(module, policy)
+	assert.Equal(t, false, v0.Parses)
+
+	v1 := rules.NewBundleFile("policy.rego", "policy.rego", m)
+	v1.CheckRegoVersion(module, policy)
+	assert.Equal(t, true, v1.Parses)
+}
+
+func TestSchemaNamesFromPath(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"schemas/input.json":          "{}",
+		"schemas/k8s/pod.json":        "{}",
+		"schemas/k8s/v1/service.json": "{}",
+	})
+
+	names := must.Return(rules.SchemaNamesFromPath(filepath.Join(root, "schemas")))(t)
+	slices.Sort(names)
+
+	assert.SlicesEqual(t, []string{"input", "k8s.pod", "k8s.v1.service"}, names)
+
+	names = must.Return(rules.SchemaNamesFromPath(filepath.Join(root, "schemas", "input.json")))(t)
+
+	assert.SlicesEqual(t, []string{""}, names)
+}
+
+func TestAddSchemasFromPath(t *testing.T) {
+	t.Parallel()
+
+	root := testutil.TempDirectoryOf(t, map[string]string{
+		"schemas/k8s/pod.json": `{"type": "object"}`,
+		"input.json":           `{"type": "string"}`,
+	})
+
+	schemas := ast.NewSchemaSet()
+
+	must.Equal(t, nil, rules.AddSchemasFromPath(schemas, filepath.Join(root, "schemas")))
+	must.Equal(t, nil, rules.AddSchemasFromPath(schemas, filepath.Join(root, "input.json")))
+
+	assert.DeepEqual(t, map[string]any{"type": "object"}, schemas.Get(ast.MustParseRef("schema.k8s.pod")))
+	assert.DeepEqual(t, map[string]any{"type": "string"}, schemas.Get(ast.SchemaRootRef))
+
+	if err := rules.AddSchemasFromPath(schemas, filepath.Join(root, "missing")); err == nil {
+		t.Error("expected error for missing schema path")
+	}
+}
+
+func TestOverlayFS(t *testing.T) {
+	t.Parallel()
+
+	base := fstest.MapFS{
+		"policy/p.rego": {Data: []byte("package p\n")},
+		"policy/q.rego": {Data: []byte("package q\n")},
+	}
+
+	overlay := rules.NewOverlayFS(base, map[string]string{
+		"policy/q.rego":       "package q.edited\n",
+		"policy/new/r.rego":   "package r\n",
+		"unsaved/s/deep.rego": "package s\n",
+	})
+
+	must.Equal(t, "package q.edited\n", string(must.Return(fs.ReadFile(overlay, "policy/q.rego"))(t)))
+	must.Equal(t, "package p\n", string(must.Return(fs.ReadFile(overlay, "policy/p.rego"))(t)))
+
+	if err := fstest.TestFS(
+		overlay, "policy/p.rego", "policy/q.rego", "policy/new/r.rego", "unsaved/s/deep.rego",
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := fstest.TestFS(rules.NewOverlayFS(nil, map[string]string{"a/b.rego": "package b\n"}), "a/b.rego"); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,86 @@
+package rules
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/open-policy-agent/opa/v1/ast"
+	"github.com/open-policy-agent/opa/v1/util"
+)
+
+// SchemaNamesFromPath returns the names of the schemas found at path, following the conventions of
+// OPA's --schema flag. Each file found in a directory provides a schema named by its path relative to
+// the directory, where the extension is dropped and separators are replaced by dots, so that a file at
+// path/k8s/pod.json is referenced as schema.k8s.pod. A file provided as path is the root schema, and
+// has the empty name, as it's referenced as schema.
+func SchemaNamesFromPath(path string) ([]string, error) {
+	var names []string
+
+	err := walkSchemas(path, func(name, _ string) error {
+		names = append(names, name)
+
+		return nil
+	})
+
+	return names, err
+}
+
+// AddSchemasFromPath reads the schemas found at path into schemas, keyed by the same refs as OPA uses
+// when loading schemas with the --schema flag, e.g. schema.k8s.pod. See SchemaNamesFromPath for how the
+// names of schemas are determined.
+func AddSchemasFromPath(schemas *ast.SchemaSet, path string) error {
+	return walkSchemas(path, func(name, file string) error {
+		bs, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read schema %s: %w", file, err)
+		}
+
+		var schema any
+		if err := util.Unmarshal(bs, &schema); err != nil {
+			return fmt.Errorf("failed to parse schema %s: %w", file, err)
+		}
+
+		key := ast.SchemaRootRef.Copy()
+		if name != "" {
+			for part := range strings.SplitSeq(name, ".") {
+				key = append(key, ast.StringTerm(part))
+			}
+		}
+
+		schemas.Put(key, schema)
+
+		return nil
+	})
+}
+
+func walkSchemas(path string, fn func(name, file string) error) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read schema path %s: %w", path, err)
+	}
+
+	if !info.IsDir() {
+		return fn("", path)
+	}
+
+	err = filepath.WalkDir(path, func(file string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		rel, err := filepath.Rel(path, file)
+		if err != nil {
+			return err
+		}
+
+		return fn(strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(rel), filepath.Ext(rel)), "/", "."), file)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk schema path %s: %w", path, err)
+	}
+
+	return nil
+}